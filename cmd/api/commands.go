@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/audit"
+	"github.com/Doris-Mwito5/ginja-ai/internal/configs"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/mailer"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+type command func(ctx context.Context, dB db.DB, store *domain.Store, args []string) error
+
+// commands are run as `api <name> [flags]` instead of starting the HTTP server.
+var commands = map[string]command{
+	"recompute-costs":  recomputeCostsCommand,
+	"approve-cost-run": reviewCostRunCommand(true),
+	"reject-cost-run":  reviewCostRunCommand(false),
+	"replay-claims":    replayClaimsCommand,
+	"x12-claims":       x12ClaimsCommand,
+	"create-admin":     createAdminCommand,
+}
+
+func runCommand(
+	ctx context.Context,
+	dB db.DB,
+	store *domain.Store,
+	name string,
+	args []string,
+) error {
+
+	cmd, ok := commands[name]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for commandName := range commands {
+			names = append(names, commandName)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown command [%v], available: %v", name, strings.Join(names, ", "))
+	}
+
+	// changes made by a command are audited as "cli:<name>"
+	ctx = audit.WithActor(ctx, audit.Actor{Username: "cli:" + name})
+
+	return cmd(ctx, dB, store, args)
+}
+
+func recomputeCostsCommand(
+	ctx context.Context,
+	dB db.DB,
+	store *domain.Store,
+	args []string,
+) error {
+
+	flags := flag.NewFlagSet("recompute-costs", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "print the proposed costs without saving a run")
+	method := flags.String("method", "", "median or trimmed_mean (default median)")
+	windowDays := flags.Int("window-days", 0, fmt.Sprintf("days of claims to sample (default %d)", services.DefaultCostWindowDays))
+	minSamples := flags.Int("min-samples", 0, fmt.Sprintf("fewest claims needed per procedure (default %d)", services.DefaultCostMinSamples))
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	form := &dtos.CostRunRequest{
+		Method:     *method,
+		WindowDays: *windowDays,
+		MinSamples: *minSamples,
+	}
+
+	costRunService := services.NewCostRunService(store)
+
+	var run *models.CostRun
+	var err error
+	if *dryRun {
+		run, err = costRunService.PreviewCostRun(ctx, dB, form)
+	} else {
+		run, err = costRunService.CreateCostRun(ctx, dB, form)
+	}
+	if err != nil {
+		return err
+	}
+
+	printCostRun(run)
+	if !*dryRun {
+		fmt.Printf("\nSaved pending cost run %d. Approve it with: approve-cost-run -id %d\n", run.ID, run.ID)
+	}
+
+	return nil
+}
+
+func reviewCostRunCommand(approve bool) command {
+	return func(ctx context.Context, dB db.DB, store *domain.Store, args []string) error {
+
+		name := "reject-cost-run"
+		if approve {
+			name = "approve-cost-run"
+		}
+
+		flags := flag.NewFlagSet(name, flag.ContinueOnError)
+		id := flags.Int64("id", 0, "cost run ID")
+		reviewedBy := flags.String("reviewed-by", "cli", "name recorded as the reviewer")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return fmt.Errorf("%v: -id is required", name)
+		}
+
+		costRunService := services.NewCostRunService(store)
+
+		var run *models.CostRun
+		var err error
+		if approve {
+			run, err = costRunService.ApproveCostRun(ctx, dB, *id, *reviewedBy)
+		} else {
+			run, err = costRunService.RejectCostRun(ctx, dB, *id, *reviewedBy)
+		}
+		if err != nil {
+			return err
+		}
+
+		printCostRun(run)
+		fmt.Printf("\nCost run %d %v by %v\n", run.ID, run.Status, run.ReviewedBy)
+		return nil
+	}
+}
+
+func printCostRun(run *models.CostRun) {
+	fmt.Printf("Method %v, window %d days, min samples %d\n\n", run.Method, run.WindowDays, run.MinSamples)
+
+	if len(run.Proposals) == 0 {
+		fmt.Println("No procedure costs would change.")
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "CODE\tCURRENT\tPROPOSED\tCHANGE\tSAMPLES\t")
+	for _, proposal := range run.Proposals {
+		change := "n/a"
+		if !proposal.CurrentCost.IsZero() {
+			change = fmt.Sprintf("%+.1f%%", proposal.ProposedCost.Sub(proposal.CurrentCost).Float64()/proposal.CurrentCost.Float64()*100)
+		}
+		fmt.Fprintf(
+			writer,
+			"%v\t%v\t%v\t%v\t%d\t\n",
+			proposal.ProcedureCode,
+			proposal.CurrentCost,
+			proposal.ProposedCost,
+			change,
+			proposal.SampleSize,
+		)
+	}
+	writer.Flush()
+}
+
+func replayClaimsCommand(
+	ctx context.Context,
+	dB db.DB,
+	store *domain.Store,
+	args []string,
+) error {
+
+	flags := flag.NewFlagSet("replay-claims", flag.ContinueOnError)
+	from := flags.String("from", "", "first submission date to replay, YYYY-MM-DD")
+	to := flags.String("to", "", "last submission date to replay, YYYY-MM-DD (default from)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" {
+		return fmt.Errorf("replay-claims: -from is required")
+	}
+	if *to == "" {
+		*to = *from
+	}
+
+	fromDate, err := utils.ParseDate(*from)
+	if err != nil {
+		return fmt.Errorf("replay-claims: -from: %v", err)
+	}
+	toDate, err := utils.ParseDate(*to)
+	if err != nil {
+		return fmt.Errorf("replay-claims: -to: %v", err)
+	}
+
+	claimService := services.NewClaimService(store, configs.Config.ClaimFilingDays)
+	report, err := claimService.ReplayClaims(ctx, dB, fromDate, toDate)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Replayed %d claims submitted %v to %v, %d unchanged\n", report.Replayed, report.From, report.To, report.Unchanged)
+	fmt.Printf("Approved %v originally, %v under current rules\n\n", report.OriginalApprovedAmount, report.ReplayedApprovedAmount)
+
+	if len(report.Differences) == 0 {
+		fmt.Println("No claim decisions would change.")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CLAIM\tSTATUS\tREPLAYED\tAPPROVED\tREPLAYED\tFRAUD\tREASON")
+	for _, difference := range report.Differences {
+		fmt.Fprintf(
+			writer,
+			"%d\t%v\t%v\t%v\t%v\t%v -> %v\t%v\n",
+			difference.ClaimID,
+			difference.OriginalStatus,
+			difference.ReplayedStatus,
+			difference.OriginalApprovedAmount,
+			difference.ReplayedApprovedAmount,
+			difference.OriginalFraudFlag,
+			difference.ReplayedFraudFlag,
+			difference.ReplayedReason,
+		)
+	}
+	writer.Flush()
+	return nil
+}
+
+func x12ClaimsCommand(
+	ctx context.Context,
+	dB db.DB,
+	store *domain.Store,
+	args []string,
+) error {
+
+	flags := flag.NewFlagSet("x12-claims", flag.ContinueOnError)
+	in := flags.String("in", "", "837P or 837I interchange to adjudicate")
+	out := flags.String("out", "", "file to write the 835 to (default stdout)")
+	dryRun := flags.Bool("dry-run", false, "adjudicate without storing the claims")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return fmt.Errorf("x12-claims: -in is required")
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return fmt.Errorf("x12-claims: %v", err)
+	}
+
+	claimService := services.NewClaimService(store, configs.Config.ClaimFilingDays)
+	x12Service := services.NewX12Service(store, claimService)
+
+	result, err := x12Service.ProcessClaims(ctx, dB, data, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	// the summary goes to stderr so the 835 can be piped from stdout
+	writer := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CLAIM\tTYPE\tID\tSTATUS\tAPPROVED\tREASON")
+	for _, claim := range result.Claims {
+		reason := claim.RejectionReason
+		if claim.Error != "" {
+			reason = claim.Error
+		}
+		fmt.Fprintf(
+			writer,
+			"%v\t%v\t%d\t%v\t%v\t%v\n",
+			claim.PatientControlNumber,
+			claim.Type,
+			claim.ClaimID,
+			claim.Status,
+			claim.ApprovedAmount,
+			reason,
+		)
+	}
+	writer.Flush()
+
+	if *out == "" {
+		fmt.Print(result.Remittance)
+		return nil
+	}
+	return os.WriteFile(*out, []byte(result.Remittance), 0o644)
+}
+
+// createAdminCommand creates the first administrator, or promotes an existing user. The password
+// of a new user is read from the first line of stdin so it stays out of the shell history.
+func createAdminCommand(
+	ctx context.Context,
+	dB db.DB,
+	store *domain.Store,
+	args []string,
+) error {
+
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the admin")
+	email := flags.String("email", "", "email of a new admin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return fmt.Errorf("create-admin: -username is required")
+	}
+
+	userService := services.NewUserService(store, mailer.NewMailer())
+
+	form := &dtos.RegisterRequest{
+		Username: *username,
+		Email:    *email,
+	}
+
+	existing, err := userService.GetUserByUsername(ctx, dB, *username)
+	if err != nil && !apperr.IsNoRowsErr(err) {
+		return err
+	}
+	if existing == nil {
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("create-admin: read password from stdin: %w", err)
+		}
+		form.Password = strings.TrimRight(password, "\r\n")
+	}
+
+	user, err := userService.CreateAdmin(ctx, dB, form)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("User %v (%d) is an active admin\n", user.Username, user.ID)
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/configs"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/jobs"
+	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+)
+
+// startScheduledJobs starts the background jobs enabled in config. They stop when ctx is cancelled.
+func startScheduledJobs(
+	ctx context.Context,
+	dB db.DB,
+	store *domain.Store,
+) {
+
+	sealInterval, err := time.ParseDuration(configs.Config.AuditSealInterval)
+	if err != nil || sealInterval <= 0 {
+		logger.Fatalf("invalid AUDIT_SEAL_INTERVAL [%v]", configs.Config.AuditSealInterval)
+	}
+
+	auditService := services.NewAuditService(store)
+
+	// entries are chained outside the transactions that write them, so audited writes never wait
+	// on each other for the audit log
+	go jobs.Every(ctx, sealInterval, "seal-audit-log", func(ctx context.Context) error {
+		_, err := auditService.SealAuditLog(ctx, dB)
+		return err
+	})
+
+	if configs.Config.CostRunInterval != "" {
+		interval, err := time.ParseDuration(configs.Config.CostRunInterval)
+		if err != nil || interval <= 0 {
+			logger.Fatalf("invalid COST_RUN_INTERVAL [%v]", configs.Config.CostRunInterval)
+		}
+
+		costRunService := services.NewCostRunService(store)
+
+		// scheduled runs are only proposals; an admin still has to approve them
+		go jobs.Every(ctx, interval, "recompute-costs", func(ctx context.Context) error {
+			preview, err := costRunService.PreviewCostRun(ctx, dB, &dtos.CostRunRequest{})
+			if err != nil {
+				return err
+			}
+			if len(preview.Proposals) == 0 {
+				return nil
+			}
+
+			// a run still waiting for review is left alone until an admin decides on it
+			run, err := costRunService.CreateCostRun(ctx, dB, &dtos.CostRunRequest{})
+			if appErr, ok := err.(*apperr.Error); ok && appErr.Type == apperr.Conflict {
+				logger.Infof("cost run not created: %v", appErr.Message)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			logger.Infof("cost run %d is pending review with %d proposed changes", run.ID, len(run.Proposals))
+			return nil
+		})
+	}
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/Doris-Mwito5/ginja-ai/internal/configs"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/health"
 	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+	"github.com/Doris-Mwito5/ginja-ai/internal/metrics"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
 	"github.com/Doris-Mwito5/ginja-ai/web/routes"
 )
 
@@ -23,6 +26,19 @@ func main() {
 	log.Printf("Console: %s", configs.Config.Environment)
 	
 	logger.InitLogger("ginja-ai")
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Options{
+		ServiceName:  "ginja-ai",
+		Exporter:     configs.Config.TracingExporter,
+		OTLPEndpoint: configs.Config.OTLPEndpoint,
+		OTLPInsecure: configs.Config.OTLPInsecure,
+		SampleRatio:  configs.Config.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Fatalf("tracing setup failed: %v", err)
+	}
+	defer stopTracing(shutdownTracing)
+
 	// Init db
 	dB := db.InitDB(configs.Config.DatabaseURL)
 	defer dB.Close()
@@ -33,9 +49,29 @@ func main() {
 	//domain store
 	domainStore := domain.NewStore()
 
+	// subcommands such as recompute-costs run once and exit instead of serving HTTP
+	if len(os.Args) > 1 {
+		if err := runCommand(context.Background(), dB, domainStore, os.Args[1], os.Args[2:]); err != nil {
+			logger.Fatalf("command [%v] failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	startScheduledJobs(jobsCtx, dB, domainStore)
+
+	shutdownDelay, err := time.ParseDuration(configs.Config.ShutdownDelay)
+	if err != nil || shutdownDelay < 0 {
+		logger.Fatalf("invalid SHUTDOWN_DELAY [%v]", configs.Config.ShutdownDelay)
+	}
+
+	healthState := health.NewState()
+
 	appRouter := routes.BuildRouter(
 		dB,
 		domainStore,
+		healthState,
 	)
 
 	server := &http.Server{
@@ -43,6 +79,9 @@ func main() {
 		Handler: appRouter,
 	}
 
+	// metrics are served on their own port so they are not exposed with the API
+	metricsServer := startMetricsServer(dB)
+
 	done := make(chan struct{})
 
 	go func() {
@@ -52,10 +91,21 @@ func main() {
 
 		logger.Info("Process terminated...shutting down")
 
+		// fail readiness first and keep serving for a while, so load balancers stop sending
+		// new requests before the listener closes
+		healthState.StartShutdown()
+		time.Sleep(shutdownDelay)
+
 		if err := server.Shutdown(context.Background()); err != nil {
 			logger.Fatalf("Server shut down error: %v", err)
 		}
 
+		if metricsServer != nil {
+			if err := metricsServer.Shutdown(context.Background()); err != nil {
+				logger.Errorf("Metrics server shut down error: %v", err)
+			}
+		}
+
 		close(done)
 	}()
 
@@ -85,6 +135,45 @@ func main() {
 
 	logger.Info("Server exiting...")
 
+	// os.Exit skips deferred calls, so the last spans are flushed here
+	stopTracing(shutdownTracing)
+
 	os.Exit(code)
 
 }
+
+// startMetricsServer serves the Prometheus metrics on METRICS_PORT, or returns nil when no port
+// is configured.
+func startMetricsServer(dB db.DB) *http.Server {
+	if configs.Config.MetricsPort == "" {
+		return nil
+	}
+
+	metrics.RegisterDB(dB)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	metricsServer := &http.Server{
+		Addr:    ":" + configs.Config.MetricsPort,
+		Handler: mux,
+	}
+
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("Metrics server stopped: %v", err)
+		}
+	}()
+
+	return metricsServer
+}
+
+// stopTracing flushes the spans not yet exported, giving up after a few seconds.
+func stopTracing(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := shutdown(ctx); err != nil {
+		logger.Errorf("tracing shut down error: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
 	"net/http"
+	"time"
 )
 
 type Type string
@@ -23,6 +24,8 @@ const (
 	UnsupportedMediaType Type = "UNSUPPORTED_MEDIA_TYPE" // for http 415
 	UnexpextedError      Type = "UNEXPECTED_ERROR"
 	DatabaseError        Type = "DATABASE_ERROR"
+	AccountLocked        Type = "ACCOUNT_LOCKED"    // Too many failed logins, account temporarily locked - 423
+	TooManyRequests      Type = "TOO_MANY_REQUESTS" // Rate limited - 429
 )
 
 var (
@@ -51,6 +54,8 @@ func (e *Error) Status() int {
 		return http.StatusBadRequest
 	case Conflict:
 		return http.StatusConflict
+	case Permission:
+		return http.StatusForbidden
 	case Internal:
 		return http.StatusInternalServerError
 	case NotFound:
@@ -65,6 +70,10 @@ func (e *Error) Status() int {
 		return http.StatusExpectationFailed
 	case DatabaseError:
 		return http.StatusBadGateway
+	case AccountLocked:
+		return http.StatusLocked
+	case TooManyRequests:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
@@ -151,6 +160,22 @@ func NewUnsupportedMediaType(reason string) *Error {
 	}
 }
 
+// NewAccountLocked to create an error for 423
+func NewAccountLocked(lockedUntil time.Time) *Error {
+	return &Error{
+		Type:    AccountLocked,
+		Message: fmt.Sprintf("account is locked until %v after too many failed login attempts", lockedUntil.UTC().Format(time.RFC3339)),
+	}
+}
+
+// NewTooManyRequests to create an error for 429
+func NewTooManyRequests(reason string) *Error {
+	return &Error{
+		Type:    TooManyRequests,
+		Message: reason,
+	}
+}
+
 func NewUnexpectedError(reason string) *Error {
 	return &Error{
 		Type:    UnexpextedError,
@@ -230,8 +255,10 @@ func IsNoRowsErr(err error) bool {
 	return appError.Error() == sql.ErrNoRows.Error()
 }
 
+// LogErrorMessage records a message about the error's cause. The messages are logged with the
+// request ID when the error is returned to the client.
 func (e *Error) LogErrorMessage(message string, values ...interface{}) error {
-	logger.Errorf(message, values...)
+	e.LogMessages = append(e.LogMessages, fmt.Sprintf(message, values...))
 	return e
 }
 
@@ -251,5 +278,9 @@ func (e *Error) JsonResponse() map[string]interface{} {
 		"status_code":   e.Status(),
 	}
 
+	if e.RequestID != "" {
+		jsonResponse["request_id"] = e.RequestID
+	}
+
 	return jsonResponse
 }
@@ -0,0 +1,25 @@
+package audit
+
+import "context"
+
+type actorKey struct{}
+
+// Actor is who is making a change and where the request came from. Username is empty for
+// requests without a token, such as registration and login.
+type Actor struct {
+	Username  string
+	Role      string
+	IPAddress string
+	RequestID string
+}
+
+// WithActor returns a copy of ctx carrying the actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by ctx, or the zero Actor.
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
@@ -9,11 +9,49 @@ import (
 var Config *config
 
 type config struct {
-	Debug       bool   `mapstructure:"DEBUG"`
-	Port        string `mapstructure:"HTTP_PORT"`
-	Environment string `mapstructure:"ENVIRONMENT"`
-	DatabaseURL string `mapstructure:"DATABASE_URL"`
-	JWTSecret   string `mapstructure:"JWT_SECRET"`
+	Debug        bool   `mapstructure:"DEBUG"`
+	Port         string `mapstructure:"HTTP_PORT"`
+	Environment  string `mapstructure:"ENVIRONMENT"`
+	DatabaseURL  string `mapstructure:"DATABASE_URL"`
+	JWTSecret    string `mapstructure:"JWT_SECRET"`
+	AppBaseURL   string `mapstructure:"APP_BASE_URL"`
+	MailDriver   string `mapstructure:"MAIL_DRIVER"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	MailLogFile  string `mapstructure:"MAIL_LOG_FILE"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     string `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	// ClaimFilingDays is the timely-filing limit in days after the date of service; 0 disables it.
+	ClaimFilingDays int `mapstructure:"CLAIM_FILING_DAYS"`
+	// CostRunInterval schedules procedure cost recomputation, e.g. "168h"; empty disables it.
+	CostRunInterval string `mapstructure:"COST_RUN_INTERVAL"`
+	// AuditSealInterval is how often new audit log entries are chained into the hash chain, e.g. "10s".
+	AuditSealInterval string `mapstructure:"AUDIT_SEAL_INTERVAL"`
+	// StorageDriver keeps claim attachments on the local filesystem ("local") or in S3 ("s3").
+	StorageDriver     string `mapstructure:"STORAGE_DRIVER"`
+	StorageLocalDir   string `mapstructure:"STORAGE_LOCAL_DIR"`
+	S3Endpoint        string `mapstructure:"S3_ENDPOINT"`
+	S3Region          string `mapstructure:"S3_REGION"`
+	S3Bucket          string `mapstructure:"S3_BUCKET"`
+	S3AccessKeyID     string `mapstructure:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `mapstructure:"S3_SECRET_ACCESS_KEY"`
+	// AttachmentMaxBytes is the largest claim attachment accepted.
+	AttachmentMaxBytes int64 `mapstructure:"ATTACHMENT_MAX_BYTES"`
+	// TrustedProxies lists the proxy IPs or CIDRs, comma separated, whose X-Forwarded-For and
+	// X-Real-IP headers are believed; with none, the client IP is the connection's address.
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+	// MetricsPort serves the Prometheus /metrics endpoint apart from the API; empty disables it.
+	MetricsPort string `mapstructure:"METRICS_PORT"`
+	// TracingExporter sends OpenTelemetry spans to an OTLP/HTTP collector ("otlp"), prints them
+	// ("stdout") or drops them ("none").
+	TracingExporter    string  `mapstructure:"TRACING_EXPORTER"`
+	TracingSampleRatio float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	OTLPEndpoint       string  `mapstructure:"OTLP_ENDPOINT"`
+	OTLPInsecure       bool    `mapstructure:"OTLP_INSECURE"`
+	// ShutdownDelay is how long the server keeps serving after SIGTERM with readiness failing,
+	// e.g. "5s", so load balancers stop routing to it before it closes.
+	ShutdownDelay string `mapstructure:"SHUTDOWN_DELAY"`
 }
 
 func InitializeEnvironment() {
@@ -25,6 +63,34 @@ func InitializeEnvironment() {
 
 	viper.AutomaticEnv()
 
+	// defaults also register the keys so they can be set from the environment alone
+	viper.SetDefault("APP_BASE_URL", "http://localhost:8080")
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("MAIL_FROM", "no-reply@ginja.ai")
+	viper.SetDefault("MAIL_LOG_FILE", "")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("CLAIM_FILING_DAYS", 90)
+	viper.SetDefault("COST_RUN_INTERVAL", "")
+	viper.SetDefault("AUDIT_SEAL_INTERVAL", "10s")
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "./data/attachments")
+	viper.SetDefault("S3_ENDPOINT", "https://s3.amazonaws.com")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_BUCKET", "")
+	viper.SetDefault("S3_ACCESS_KEY_ID", "")
+	viper.SetDefault("S3_SECRET_ACCESS_KEY", "")
+	viper.SetDefault("ATTACHMENT_MAX_BYTES", 10<<20)
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("METRICS_PORT", "9091")
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("OTLP_ENDPOINT", "localhost:4318")
+	viper.SetDefault("OTLP_INSECURE", false)
+	viper.SetDefault("SHUTDOWN_DELAY", "5s")
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		log.Printf("Warning: .env file not found (%v), using system environment variables", err)
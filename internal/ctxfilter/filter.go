@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
 	"github.com/Doris-Mwito5/ginja-ai/internal/null"
 	"github.com/gin-gonic/gin"
@@ -33,6 +34,25 @@ func FilterFromContext(
 	filter.UUID = strings.TrimSpace(c.Query("uuid"))
 	filter.Year = strings.TrimSpace(c.Query("year"))
 	filter.Reference = strings.TrimSpace(c.Query("reference"))
+	filter.Role = strings.TrimSpace(c.Query("role"))
+	filter.Currency = strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+	filter.Actor = strings.TrimSpace(c.Query("actor"))
+	filter.Action = strings.TrimSpace(c.Query("action"))
+	filter.EntityType = strings.TrimSpace(c.Query("entity_type"))
+	filter.EntityID = strings.TrimSpace(c.Query("entity_id"))
+	filter.RequestID = strings.TrimSpace(c.Query("request_id"))
+
+	providerID := strings.TrimSpace(c.Query("provider_id"))
+	if providerID != "" {
+		if _, err := strconv.ParseInt(providerID, 10, 64); err != nil {
+			return filter, apperr.NewErrorWithType(
+				err,
+				apperr.BadRequest,
+			)
+		}
+
+		filter.ProviderID = &providerID
+	}
 
 	isValid := strings.TrimSpace(c.Query("active"))
 	if isValid != "" {
@@ -63,6 +83,45 @@ func FilterFromContext(
 	return filter, nil
 }
 
+// ImportOptionsFromContext reads dry_run and all_or_nothing for CSV uploads. Imports are
+// all or nothing unless all_or_nothing=false is passed.
+func ImportOptionsFromContext(
+	c *gin.Context,
+) (*dtos.ImportOptions, error) {
+
+	options := &dtos.ImportOptions{
+		AllOrNothing: true,
+	}
+
+	dryRun := strings.TrimSpace(c.Query("dry_run"))
+	if dryRun != "" {
+		value, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return options, apperr.NewErrorWithType(
+				err,
+				apperr.BadRequest,
+			)
+		}
+
+		options.DryRun = value
+	}
+
+	allOrNothing := strings.TrimSpace(c.Query("all_or_nothing"))
+	if allOrNothing != "" {
+		value, err := strconv.ParseBool(allOrNothing)
+		if err != nil {
+			return options, apperr.NewErrorWithType(
+				err,
+				apperr.BadRequest,
+			)
+		}
+
+		options.AllOrNothing = value
+	}
+
+	return options, nil
+}
+
 func paginationFromContext(
 	c *gin.Context,
 ) (int, int, error) {
@@ -0,0 +1,23 @@
+package custom_types
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+	AuditActionDecide AuditAction = "decide" // a claim adjudicated by the pipeline
+)
+
+func (a AuditAction) String() string {
+	return string(a)
+}
+
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionDecide:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,6 +1,9 @@
 package custom_types
 
-import "database/sql/driver"
+import (
+	"database/sql/driver"
+	"strings"
+)
 
 type ClaimStatus string
 
@@ -22,3 +25,19 @@ func (c *ClaimStatus) Value() (driver.Value, error) {
 func (c ClaimStatus) String() string {
 	return string(c)
 }
+
+// IsDecision reports whether the status is an adjudication outcome rather than a pending claim.
+// Stored statuses are upper case.
+func (c ClaimStatus) IsDecision() bool {
+	switch ClaimStatus(strings.ToLower(string(c))) {
+	case ClaimStatusApproved, ClaimStatusPartial, ClaimStatusRejected:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRejected reports whether the claim was rejected, whatever the case it is stored in.
+func (c ClaimStatus) IsRejected() bool {
+	return strings.EqualFold(string(c), string(ClaimStatusRejected))
+}
@@ -0,0 +1,42 @@
+package custom_types
+
+type CostMethod string
+
+const (
+	CostMethodMedian      CostMethod = "median"
+	CostMethodTrimmedMean CostMethod = "trimmed_mean"
+)
+
+func (m CostMethod) String() string {
+	return string(m)
+}
+
+func (m CostMethod) IsValid() bool {
+	switch m {
+	case CostMethodMedian, CostMethodTrimmedMean:
+		return true
+	default:
+		return false
+	}
+}
+
+type CostRunStatus string
+
+const (
+	CostRunStatusPending  CostRunStatus = "pending"
+	CostRunStatusApproved CostRunStatus = "approved"
+	CostRunStatusRejected CostRunStatus = "rejected"
+)
+
+func (s CostRunStatus) String() string {
+	return string(s)
+}
+
+func (s CostRunStatus) IsValid() bool {
+	switch s {
+	case CostRunStatusPending, CostRunStatusApproved, CostRunStatusRejected:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,31 @@
+package custom_types
+
+// DocumentType is the kind of supporting document attached to a claim.
+type DocumentType string
+
+const (
+	DocumentTypeInvoice          DocumentType = "invoice"
+	DocumentTypeDischargeSummary DocumentType = "discharge_summary"
+	DocumentTypeLabReport        DocumentType = "lab_report"
+	DocumentTypePrescription     DocumentType = "prescription"
+	DocumentTypeReferral         DocumentType = "referral"
+	DocumentTypeOther            DocumentType = "other"
+)
+
+func (t DocumentType) String() string {
+	return string(t)
+}
+
+func (t DocumentType) IsValid() bool {
+	switch t {
+	case DocumentTypeInvoice,
+		DocumentTypeDischargeSummary,
+		DocumentTypeLabReport,
+		DocumentTypePrescription,
+		DocumentTypeReferral,
+		DocumentTypeOther:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,22 @@
+package custom_types
+
+type PaymentBatchStatus string
+
+const (
+	PaymentBatchStatusDraft    PaymentBatchStatus = "draft"
+	PaymentBatchStatusApproved PaymentBatchStatus = "approved"
+	PaymentBatchStatusPaid     PaymentBatchStatus = "paid"
+)
+
+func (s PaymentBatchStatus) String() string {
+	return string(s)
+}
+
+func (s PaymentBatchStatus) IsValid() bool {
+	switch s {
+	case PaymentBatchStatusDraft, PaymentBatchStatusApproved, PaymentBatchStatusPaid:
+		return true
+	default:
+		return false
+	}
+}
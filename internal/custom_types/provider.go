@@ -0,0 +1,42 @@
+package custom_types
+
+type ProviderNetworkTier string
+
+const (
+	ProviderNetworkTierInNetwork    ProviderNetworkTier = "in_network"
+	ProviderNetworkTierOutOfNetwork ProviderNetworkTier = "out_of_network"
+	ProviderNetworkTierPanel        ProviderNetworkTier = "panel"
+)
+
+func (t ProviderNetworkTier) String() string {
+	return string(t)
+}
+
+func (t ProviderNetworkTier) IsValid() bool {
+	switch t {
+	case ProviderNetworkTierInNetwork, ProviderNetworkTierOutOfNetwork, ProviderNetworkTierPanel:
+		return true
+	default:
+		return false
+	}
+}
+
+type ProviderStatus string
+
+const (
+	ProviderStatusActive    ProviderStatus = "active"
+	ProviderStatusSuspended ProviderStatus = "suspended"
+)
+
+func (s ProviderStatus) String() string {
+	return string(s)
+}
+
+func (s ProviderStatus) IsValid() bool {
+	switch s {
+	case ProviderStatusActive, ProviderStatusSuspended:
+		return true
+	default:
+		return false
+	}
+}
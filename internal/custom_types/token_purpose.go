@@ -0,0 +1,12 @@
+package custom_types
+
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+)
+
+func (p TokenPurpose) String() string {
+	return string(p)
+}
@@ -0,0 +1,23 @@
+package custom_types
+
+type UserRole string
+
+const (
+	UserRoleAdmin         UserRole = "admin"
+	UserRoleClaimsOfficer UserRole = "claims_officer"
+	UserRoleUser          UserRole = "user"
+	UserRoleAuditor       UserRole = "auditor" // reads the audit log
+)
+
+func (r UserRole) String() string {
+	return string(r)
+}
+
+func (r UserRole) IsValid() bool {
+	switch r {
+	case UserRoleAdmin, UserRoleClaimsOfficer, UserRoleUser, UserRoleAuditor:
+		return true
+	default:
+		return false
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq"
 )
@@ -29,6 +30,18 @@ type pgSQLOperations struct {
 	*sql.Tx
 }
 
+func (o *pgSQLOperations) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tracedExec(ctx, o.Tx, query, args)
+}
+
+func (o *pgSQLOperations) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tracedQuery(ctx, o.Tx, query, args)
+}
+
+func (o *pgSQLOperations) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tracedQueryRow(ctx, o.Tx, query, args)
+}
+
 func (o *pgSQLOperations) ValidForPostgres() bool {
 	return true
 }
@@ -40,6 +53,8 @@ type DB interface {
 	Ping() error
 	InTransaction(ctx context.Context, operations func(context.Context, SQLOperations) error) error
 	Valid() bool
+	Stats() sql.DBStats
+	PoolStat() *pgxpool.Stat
 }
 
 type RowScanner interface {
@@ -52,18 +67,44 @@ type appDB struct {
 	valid bool
 }
 
+func (db *appDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tracedExec(ctx, db.DB, query, args)
+}
+
+func (db *appDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tracedQuery(ctx, db.DB, query, args)
+}
+
+func (db *appDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tracedQueryRow(ctx, db.DB, query, args)
+}
+
 func (db *appDB) InTransaction(ctx context.Context, operations func(context.Context, SQLOperations) error) error {
 
+	// the statements run in the transaction are traced as its children; a rollback is not
+	// marked as an error, since dry runs always roll back
+	ctx, span := tracing.Start(ctx, "transaction")
+	defer span.End()
+
 	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
 
+	// a panic must not leave the transaction, and its connection, open
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
 	sqlOperations := &pgSQLOperations{
 		Tx: tx,
 	}
 
 	if err = operations(ctx, sqlOperations); err != nil {
+		span.SetAttributes(rolledBackKey.Bool(true))
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
 			return rollbackErr
 		}
@@ -82,6 +123,11 @@ func (db *appDB) Valid() bool {
 	return db.valid
 }
 
+// PoolStat returns the statistics of the pgx connection pool; Stats reports those of the sql.DB.
+func (db *appDB) PoolStat() *pgxpool.Stat {
+	return db.pool.Stat()
+}
+
 func InitDB(databaseURL string) DB {
 	return InitDBWithURL(
 		databaseURL,
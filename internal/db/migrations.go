@@ -0,0 +1,37 @@
+package db
+
+import (
+	"embed"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFiles are the goose migrations the binary was built with, so readiness can tell
+// whether the database has caught up with the code.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationVersions returns the versions of the embedded migrations in ascending order, read
+// from the timestamp that starts each file name.
+func MigrationVersions() ([]int64, error) {
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int64, 0, len(names))
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(strings.TrimPrefix(name, "migrations/"), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
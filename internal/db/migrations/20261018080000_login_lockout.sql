@@ -0,0 +1,28 @@
+-- +goose Up
+
+-- roles and lockout state on users
+ALTER TABLE users ADD COLUMN role               VARCHAR(30) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN failed_login_count INT         NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN locked_until       TIMESTAMPTZ;
+
+-- login_attempts table, one row per login attempt (known and unknown usernames)
+CREATE TABLE login_attempts (
+    id         BIGSERIAL   PRIMARY KEY,
+    username   VARCHAR(50) NOT NULL,
+    ip_address VARCHAR(45),
+    succeeded  BOOLEAN     NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_attempts_username   ON login_attempts (username, created_at);
+CREATE INDEX idx_login_attempts_ip_address ON login_attempts (ip_address, created_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_login_attempts_ip_address;
+DROP INDEX IF EXISTS idx_login_attempts_username;
+DROP TABLE IF EXISTS login_attempts;
+
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS failed_login_count;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
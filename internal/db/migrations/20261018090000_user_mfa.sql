@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- TOTP second factor on users
+ALTER TABLE users ADD COLUMN mfa_secret    VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN mfa_enabled   BOOLEAN     NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN mfa_last_step BIGINT      NOT NULL DEFAULT 0;
+
+-- single-use recovery codes, stored hashed
+CREATE TABLE user_recovery_codes (
+    id         BIGSERIAL   PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes (user_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_user_recovery_codes_user_id;
+DROP TABLE IF EXISTS user_recovery_codes;
+
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_secret;
@@ -0,0 +1,20 @@
+-- +goose Up
+
+-- accreditation, network tier and contract status on providers
+ALTER TABLE providers ADD COLUMN licence_number       VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE providers ADD COLUMN accreditation_expiry DATE;
+ALTER TABLE providers ADD COLUMN network_tier         VARCHAR(20) NOT NULL DEFAULT 'in_network';
+ALTER TABLE providers ADD COLUMN status               VARCHAR(20) NOT NULL DEFAULT 'active';
+
+CREATE UNIQUE INDEX idx_providers_licence_number ON providers (licence_number) WHERE licence_number <> '';
+CREATE INDEX idx_providers_status ON providers (status);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_providers_status;
+DROP INDEX IF EXISTS idx_providers_licence_number;
+
+ALTER TABLE providers DROP COLUMN IF EXISTS status;
+ALTER TABLE providers DROP COLUMN IF EXISTS network_tier;
+ALTER TABLE providers DROP COLUMN IF EXISTS accreditation_expiry;
+ALTER TABLE providers DROP COLUMN IF EXISTS licence_number;
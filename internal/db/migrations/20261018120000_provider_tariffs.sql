@@ -0,0 +1,20 @@
+-- +goose Up
+
+-- negotiated per-provider procedure prices, overriding procedures.average_cost
+CREATE TABLE provider_tariffs (
+    id             BIGSERIAL      PRIMARY KEY,
+    provider_id    BIGINT         NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+    procedure_code VARCHAR(20)    NOT NULL REFERENCES procedures(code),
+    agreed_price   DECIMAL(10, 2) NOT NULL,
+    effective_from DATE           NOT NULL,
+    effective_to   DATE,
+    created_at     TIMESTAMPTZ    DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMPTZ    DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_provider_tariffs_lookup ON provider_tariffs (provider_id, procedure_code, effective_from);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_provider_tariffs_lookup;
+DROP TABLE IF EXISTS provider_tariffs;
@@ -0,0 +1,27 @@
+-- +goose Up
+
+-- effective-dated procedure prices; claims keep the version they were judged against
+CREATE TABLE procedure_versions (
+    id             BIGSERIAL      PRIMARY KEY,
+    procedure_id   BIGINT         NOT NULL REFERENCES procedures(id) ON DELETE CASCADE,
+    average_cost   DECIMAL(10, 2) NOT NULL,
+    effective_from DATE           NOT NULL,
+    effective_to   DATE,
+    created_at     TIMESTAMPTZ    DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMPTZ    DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (procedure_id, effective_from)
+);
+
+-- existing prices become the first version, valid for all historic claims
+INSERT INTO procedure_versions (procedure_id, average_cost, effective_from)
+SELECT id, average_cost, DATE '1970-01-01' FROM procedures;
+
+ALTER TABLE claims ADD COLUMN procedure_version_id BIGINT REFERENCES procedure_versions(id);
+
+CREATE INDEX idx_claims_procedure_version_id ON claims (procedure_version_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_claims_procedure_version_id;
+ALTER TABLE claims DROP COLUMN IF EXISTS procedure_version_id;
+DROP TABLE IF EXISTS procedure_versions;
@@ -0,0 +1,37 @@
+-- +goose Up
+
+-- recomputed procedure costs waiting for review before they become new price versions
+CREATE TABLE procedure_cost_runs (
+    id          BIGSERIAL    PRIMARY KEY,
+    method      VARCHAR(20)  NOT NULL,
+    window_days INT          NOT NULL,
+    min_samples INT          NOT NULL,
+    status      VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    reviewed_by VARCHAR(100) NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ  DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMPTZ  DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_procedure_cost_runs_status ON procedure_cost_runs (status);
+
+CREATE TABLE procedure_cost_proposals (
+    id             BIGSERIAL      PRIMARY KEY,
+    run_id         BIGINT         NOT NULL REFERENCES procedure_cost_runs(id) ON DELETE CASCADE,
+    procedure_id   BIGINT         NOT NULL REFERENCES procedures(id) ON DELETE CASCADE,
+    procedure_code VARCHAR(20)    NOT NULL,
+    current_cost   DECIMAL(10, 2) NOT NULL,
+    proposed_cost  DECIMAL(10, 2) NOT NULL,
+    sample_size    INT            NOT NULL,
+    created_at     TIMESTAMPTZ    DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMPTZ    DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_procedure_cost_proposals_run_id ON procedure_cost_proposals (run_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_procedure_cost_proposals_run_id;
+DROP TABLE IF EXISTS procedure_cost_proposals;
+DROP INDEX IF EXISTS idx_procedure_cost_runs_status;
+DROP TABLE IF EXISTS procedure_cost_runs;
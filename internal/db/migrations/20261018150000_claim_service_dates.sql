@@ -0,0 +1,20 @@
+-- +goose Up
+
+ALTER TABLE claims ADD COLUMN service_date   DATE;
+ALTER TABLE claims ADD COLUMN admission_date DATE;
+ALTER TABLE claims ADD COLUMN discharge_date DATE;
+
+-- existing claims have no recorded date of service; use the submission date
+UPDATE claims SET service_date = created_at::DATE WHERE service_date IS NULL;
+
+ALTER TABLE claims ALTER COLUMN service_date SET DEFAULT CURRENT_DATE;
+ALTER TABLE claims ALTER COLUMN service_date SET NOT NULL;
+
+CREATE INDEX idx_claims_service_date ON claims (service_date);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_claims_service_date;
+ALTER TABLE claims DROP COLUMN IF EXISTS discharge_date;
+ALTER TABLE claims DROP COLUMN IF EXISTS admission_date;
+ALTER TABLE claims DROP COLUMN IF EXISTS service_date;
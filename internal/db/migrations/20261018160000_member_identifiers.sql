@@ -0,0 +1,19 @@
+-- +goose Up
+
+ALTER TABLE members ADD COLUMN membership_number VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE members ADD COLUMN national_id       VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE members ADD COLUMN card_number       VARCHAR(50) NOT NULL DEFAULT '';
+
+-- identifiers are optional, but an identifier can only belong to one member
+CREATE UNIQUE INDEX idx_members_membership_number ON members (membership_number) WHERE membership_number <> '';
+CREATE UNIQUE INDEX idx_members_national_id ON members (national_id) WHERE national_id <> '';
+CREATE UNIQUE INDEX idx_members_card_number ON members (card_number) WHERE card_number <> '';
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_members_card_number;
+DROP INDEX IF EXISTS idx_members_national_id;
+DROP INDEX IF EXISTS idx_members_membership_number;
+ALTER TABLE members DROP COLUMN IF EXISTS card_number;
+ALTER TABLE members DROP COLUMN IF EXISTS national_id;
+ALTER TABLE members DROP COLUMN IF EXISTS membership_number;
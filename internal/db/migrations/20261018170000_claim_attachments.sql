@@ -0,0 +1,34 @@
+-- +goose Up
+
+-- supporting documents for a claim; the file itself lives in the configured storage
+CREATE TABLE claim_attachments (
+    id              BIGSERIAL    PRIMARY KEY,
+    claim_id        BIGINT       NOT NULL REFERENCES claims(id) ON DELETE CASCADE,
+    document_type   VARCHAR(30)  NOT NULL,
+    file_name       VARCHAR(255) NOT NULL,
+    content_type    VARCHAR(100) NOT NULL,
+    size_bytes      BIGINT       NOT NULL,
+    checksum_sha256 CHAR(64)     NOT NULL,
+    storage_key     TEXT         NOT NULL UNIQUE,
+    uploaded_by     VARCHAR(100) NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMPTZ  DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_claim_attachments_claim_id ON claim_attachments (claim_id);
+
+-- document types a claim for the procedure must carry
+CREATE TABLE procedure_document_requirements (
+    id             BIGSERIAL   PRIMARY KEY,
+    procedure_code VARCHAR(20) NOT NULL REFERENCES procedures(code) ON DELETE CASCADE,
+    document_type  VARCHAR(30) NOT NULL,
+    created_at     TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (procedure_code, document_type)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS procedure_document_requirements;
+DROP INDEX IF EXISTS idx_claim_attachments_claim_id;
+DROP TABLE IF EXISTS claim_attachments;
@@ -0,0 +1,36 @@
+-- +goose Up
+
+-- approved claims grouped into a single payment to their provider
+CREATE TABLE payment_batches (
+    id                BIGSERIAL      PRIMARY KEY,
+    provider_id       BIGINT         NOT NULL REFERENCES providers(id),
+    status            VARCHAR(20)    NOT NULL DEFAULT 'draft',
+    claim_count       INT            NOT NULL DEFAULT 0,
+    total_amount      DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    payment_reference VARCHAR(100)   NOT NULL DEFAULT '',
+    approved_by       VARCHAR(100)   NOT NULL DEFAULT '',
+    approved_at       TIMESTAMPTZ,
+    paid_at           TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ    DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMPTZ    DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_batches_provider_id ON payment_batches (provider_id);
+CREATE INDEX idx_payment_batches_status      ON payment_batches (status);
+CREATE UNIQUE INDEX idx_payment_batches_payment_reference ON payment_batches (payment_reference) WHERE payment_reference <> '';
+
+-- a claim belongs to at most one batch; paid_at is set once that batch is paid
+ALTER TABLE claims ADD COLUMN payment_batch_id BIGINT REFERENCES payment_batches(id) ON DELETE SET NULL;
+ALTER TABLE claims ADD COLUMN paid_at          TIMESTAMPTZ;
+
+CREATE INDEX idx_claims_payment_batch_id ON claims (payment_batch_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_claims_payment_batch_id;
+ALTER TABLE claims DROP COLUMN IF EXISTS paid_at;
+ALTER TABLE claims DROP COLUMN IF EXISTS payment_batch_id;
+DROP INDEX IF EXISTS idx_payment_batches_payment_reference;
+DROP INDEX IF EXISTS idx_payment_batches_status;
+DROP INDEX IF EXISTS idx_payment_batches_provider_id;
+DROP TABLE IF EXISTS payment_batches;
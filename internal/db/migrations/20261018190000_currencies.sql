@@ -0,0 +1,64 @@
+-- +goose Up
+
+-- every existing amount was in Kenyan shillings
+ALTER TABLE members          ADD COLUMN benefit_currency VARCHAR(3) NOT NULL DEFAULT 'KES';
+ALTER TABLE procedures       ADD COLUMN currency         VARCHAR(3) NOT NULL DEFAULT 'KES';
+ALTER TABLE provider_tariffs ADD COLUMN currency         VARCHAR(3) NOT NULL DEFAULT 'KES';
+ALTER TABLE payment_batches  ADD COLUMN currency         VARCHAR(3) NOT NULL DEFAULT 'KES';
+
+-- shilling amounts in UGX and TZS outgrow DECIMAL(10, 2)
+ALTER TABLE members             ALTER COLUMN benefit_limit    TYPE DECIMAL(14, 2);
+ALTER TABLE members             ALTER COLUMN used_amount      TYPE DECIMAL(14, 2);
+ALTER TABLE procedures          ALTER COLUMN average_cost     TYPE DECIMAL(14, 2);
+ALTER TABLE procedure_versions  ALTER COLUMN average_cost     TYPE DECIMAL(14, 2);
+ALTER TABLE provider_tariffs    ALTER COLUMN agreed_price     TYPE DECIMAL(14, 2);
+ALTER TABLE claims              ALTER COLUMN requested_amount TYPE DECIMAL(14, 2);
+ALTER TABLE claims              ALTER COLUMN approved_amount  TYPE DECIMAL(14, 2);
+ALTER TABLE payment_batches     ALTER COLUMN total_amount     TYPE DECIMAL(16, 2);
+ALTER TABLE procedure_cost_proposals ALTER COLUMN current_cost  TYPE DECIMAL(14, 2);
+ALTER TABLE procedure_cost_proposals ALTER COLUMN proposed_cost TYPE DECIMAL(14, 2);
+
+-- requested_amount stays in the currency it was billed in; converted_amount and approved_amount
+-- are in the member's benefit currency, converted at exchange_rate
+ALTER TABLE claims ADD COLUMN currency         VARCHAR(3)      NOT NULL DEFAULT 'KES';
+ALTER TABLE claims ADD COLUMN benefit_currency VARCHAR(3)      NOT NULL DEFAULT 'KES';
+ALTER TABLE claims ADD COLUMN converted_amount DECIMAL(14, 2);
+ALTER TABLE claims ADD COLUMN exchange_rate    DECIMAL(24, 10) NOT NULL DEFAULT 1;
+
+UPDATE claims SET converted_amount = requested_amount WHERE converted_amount IS NULL;
+ALTER TABLE claims ALTER COLUMN converted_amount SET NOT NULL;
+
+-- one unit of base_currency buys rate units of quote_currency from effective_from until the
+-- pair's next rate
+CREATE TABLE exchange_rates (
+    id             BIGSERIAL       PRIMARY KEY,
+    base_currency  VARCHAR(3)      NOT NULL,
+    quote_currency VARCHAR(3)      NOT NULL,
+    rate           DECIMAL(24, 10) NOT NULL CHECK (rate > 0),
+    effective_from DATE            NOT NULL,
+    created_at     TIMESTAMPTZ     DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMPTZ     DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (base_currency, quote_currency, effective_from)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE claims DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE claims DROP COLUMN IF EXISTS converted_amount;
+ALTER TABLE claims DROP COLUMN IF EXISTS benefit_currency;
+ALTER TABLE claims DROP COLUMN IF EXISTS currency;
+ALTER TABLE procedure_cost_proposals ALTER COLUMN proposed_cost TYPE DECIMAL(10, 2);
+ALTER TABLE procedure_cost_proposals ALTER COLUMN current_cost  TYPE DECIMAL(10, 2);
+ALTER TABLE payment_batches     ALTER COLUMN total_amount     TYPE DECIMAL(12, 2);
+ALTER TABLE claims              ALTER COLUMN approved_amount  TYPE DECIMAL(10, 2);
+ALTER TABLE claims              ALTER COLUMN requested_amount TYPE DECIMAL(10, 2);
+ALTER TABLE provider_tariffs    ALTER COLUMN agreed_price     TYPE DECIMAL(10, 2);
+ALTER TABLE procedure_versions  ALTER COLUMN average_cost     TYPE DECIMAL(10, 2);
+ALTER TABLE procedures          ALTER COLUMN average_cost     TYPE DECIMAL(10, 2);
+ALTER TABLE members             ALTER COLUMN used_amount      TYPE DECIMAL(10, 2);
+ALTER TABLE members             ALTER COLUMN benefit_limit    TYPE DECIMAL(10, 2);
+ALTER TABLE payment_batches DROP COLUMN IF EXISTS currency;
+ALTER TABLE provider_tariffs DROP COLUMN IF EXISTS currency;
+ALTER TABLE procedures DROP COLUMN IF EXISTS currency;
+ALTER TABLE members DROP COLUMN IF EXISTS benefit_currency;
@@ -0,0 +1,86 @@
+-- +goose Up
+
+-- append-only record of every change to the data; each entry's hash covers the previous
+-- entry's hash, so editing or removing an entry breaks the chain from that point on
+CREATE TABLE audit_log (
+    id           BIGINT       PRIMARY KEY,
+    actor        VARCHAR(50)  NOT NULL DEFAULT '',
+    actor_role   VARCHAR(30)  NOT NULL DEFAULT '',
+    action       VARCHAR(20)  NOT NULL,
+    entity_type  VARCHAR(50)  NOT NULL,
+    entity_id    VARCHAR(100) NOT NULL,
+    before_state JSONB,
+    after_state  JSONB,
+    request_id   VARCHAR(100) NOT NULL DEFAULT '',
+    ip_address   VARCHAR(45)  NOT NULL DEFAULT '',
+    prev_hash    CHAR(64)     NOT NULL,
+    hash         CHAR(64)     NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX idx_audit_log_entity     ON audit_log (entity_type, entity_id);
+CREATE INDEX idx_audit_log_actor      ON audit_log (actor, created_at);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
+CREATE INDEX idx_audit_log_request_id ON audit_log (request_id) WHERE request_id <> '';
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_hash(entry audit_log) RETURNS CHAR(64) AS $$
+    SELECT encode(sha256(convert_to(concat_ws(
+        '|',
+        entry.id,
+        entry.prev_hash,
+        entry.actor,
+        entry.actor_role,
+        entry.action,
+        entry.entity_type,
+        entry.entity_id,
+        COALESCE(entry.before_state::TEXT, 'null'),
+        COALESCE(entry.after_state::TEXT, 'null'),
+        entry.request_id,
+        entry.ip_address,
+        to_char(entry.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US')
+    ), 'UTF8')), 'hex')
+$$ LANGUAGE SQL IMMUTABLE;
+-- +goose StatementEnd
+
+-- entries are numbered and chained one at a time: the lock is held until the writing
+-- transaction ends, so the previous entry is always the last committed one
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_chain() RETURNS TRIGGER AS $$
+DECLARE
+    previous audit_log%ROWTYPE;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('audit_log'));
+
+    SELECT * INTO previous FROM audit_log ORDER BY id DESC LIMIT 1;
+
+    NEW.id         := COALESCE(previous.id, 0) + 1;
+    NEW.prev_hash  := COALESCE(previous.hash, repeat('0', 64));
+    NEW.created_at := date_trunc('microseconds', clock_timestamp());
+    NEW.hash       := audit_log_hash(NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_log_chain       BEFORE INSERT ON audit_log FOR EACH ROW EXECUTE FUNCTION audit_log_chain();
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS audit_log_no_truncate ON audit_log;
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+DROP TRIGGER IF EXISTS audit_log_chain ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_hash(audit_log);
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+DROP FUNCTION IF EXISTS audit_log_chain();
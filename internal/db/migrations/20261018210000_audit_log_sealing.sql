@@ -0,0 +1,135 @@
+-- +goose Up
+
+-- entries used to be chained as they were inserted, under a lock held until the writing
+-- transaction committed, which serialised every audited write. Entries are now inserted
+-- without a lock, numbered by a sequence, and chained afterwards by audit_log_seal() in its own
+-- short transaction. The chain follows chain_position, the order entries were sealed in, since
+-- ids are handed out before their transactions commit and can commit out of order.
+
+DROP TRIGGER audit_log_chain ON audit_log;
+DROP FUNCTION audit_log_chain();
+
+CREATE SEQUENCE audit_log_id_seq OWNED BY audit_log.id;
+SELECT setval('audit_log_id_seq', COALESCE((SELECT MAX(id) FROM audit_log), 0) + 1, false);
+ALTER TABLE audit_log ALTER COLUMN id SET DEFAULT nextval('audit_log_id_seq');
+
+ALTER TABLE audit_log ADD COLUMN chain_position BIGINT UNIQUE;
+ALTER TABLE audit_log ALTER COLUMN prev_hash DROP NOT NULL;
+ALTER TABLE audit_log ALTER COLUMN hash DROP NOT NULL;
+
+-- existing entries were chained in id order
+ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only;
+UPDATE audit_log SET chain_position = id;
+ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only;
+
+CREATE INDEX idx_audit_log_unsealed ON audit_log (id) WHERE chain_position IS NULL;
+
+-- the database sets the time, and only audit_log_seal() sets the chain columns
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_stamp() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.chain_position := NULL;
+    NEW.prev_hash      := NULL;
+    NEW.hash           := NULL;
+    NEW.created_at     := date_trunc('microseconds', clock_timestamp());
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_log_stamp BEFORE INSERT ON audit_log FOR EACH ROW EXECUTE FUNCTION audit_log_stamp();
+
+-- an entry's chain columns may be set until it has a hash, which is how it is sealed; any other
+-- change is rejected
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD.hash IS NULL
+        AND (NEW.id, NEW.actor, NEW.actor_role, NEW.action, NEW.entity_type, NEW.entity_id,
+             NEW.before_state, NEW.after_state, NEW.request_id, NEW.ip_address, NEW.created_at)
+            IS NOT DISTINCT FROM
+            (OLD.id, OLD.actor, OLD.actor_role, OLD.action, OLD.entity_type, OLD.entity_id,
+             OLD.before_state, OLD.after_state, OLD.request_id, OLD.ip_address, OLD.created_at)
+    THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- audit_log_seal chains the committed entries not yet sealed, in id order, and returns how many
+-- it sealed. The lock only keeps two sealers apart; it is never taken by the transactions that
+-- write entries, so it cannot hold them up or deadlock with their row locks.
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_seal() RETURNS INTEGER AS $$
+DECLARE
+    entry_id      BIGINT;
+    last_position BIGINT;
+    last_hash     CHAR(64);
+    sealed        INTEGER := 0;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('audit_log'));
+
+    SELECT chain_position, hash INTO last_position, last_hash
+    FROM audit_log WHERE chain_position IS NOT NULL ORDER BY chain_position DESC LIMIT 1;
+
+    last_position := COALESCE(last_position, 0);
+    last_hash     := COALESCE(last_hash, repeat('0', 64));
+
+    FOR entry_id IN SELECT id FROM audit_log WHERE chain_position IS NULL ORDER BY id LOOP
+        last_position := last_position + 1;
+
+        UPDATE audit_log SET chain_position = last_position, prev_hash = last_hash WHERE id = entry_id;
+        UPDATE audit_log a SET hash = audit_log_hash(a) WHERE id = entry_id RETURNING a.hash INTO last_hash;
+
+        sealed := sealed + 1;
+    END LOOP;
+
+    RETURN sealed;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+
+DROP FUNCTION IF EXISTS audit_log_seal();
+DROP TRIGGER IF EXISTS audit_log_stamp ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_stamp();
+DROP INDEX IF EXISTS idx_audit_log_unsealed;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- entries still unsealed cannot be chained the old way; seal them before rolling back
+ALTER TABLE audit_log ALTER COLUMN hash SET NOT NULL;
+ALTER TABLE audit_log ALTER COLUMN prev_hash SET NOT NULL;
+ALTER TABLE audit_log DROP COLUMN chain_position;
+ALTER TABLE audit_log ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS audit_log_id_seq;
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_chain() RETURNS TRIGGER AS $$
+DECLARE
+    previous audit_log%ROWTYPE;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('audit_log'));
+
+    SELECT * INTO previous FROM audit_log ORDER BY id DESC LIMIT 1;
+
+    NEW.id         := COALESCE(previous.id, 0) + 1;
+    NEW.prev_hash  := COALESCE(previous.hash, repeat('0', 64));
+    NEW.created_at := date_trunc('microseconds', clock_timestamp());
+    NEW.hash       := audit_log_hash(NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_log_chain BEFORE INSERT ON audit_log FOR EACH ROW EXECUTE FUNCTION audit_log_chain();
@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- tokens issued before this time are rejected, so a forced or completed password reset ends
+-- every session the user had open
+ALTER TABLE users ADD COLUMN tokens_valid_after TIMESTAMPTZ;
+
+-- +goose Down
+
+ALTER TABLE users DROP COLUMN IF EXISTS tokens_valid_after;
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- claims filed as X12 keep the interchange sender and the CLM01 patient control number, so a
+-- file sent again is answered from the saved decisions instead of adjudicated a second time
+ALTER TABLE claims ADD COLUMN x12_sender_id          VARCHAR(15);
+ALTER TABLE claims ADD COLUMN patient_control_number VARCHAR(38);
+
+CREATE UNIQUE INDEX idx_claims_patient_control_number ON claims (x12_sender_id, patient_control_number)
+    WHERE patient_control_number IS NOT NULL;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_claims_patient_control_number;
+ALTER TABLE claims DROP COLUMN IF EXISTS patient_control_number;
+ALTER TABLE claims DROP COLUMN IF EXISTS x12_sender_id;
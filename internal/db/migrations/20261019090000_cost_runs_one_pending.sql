@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- only one cost run may wait for review at a time. Of the runs already pending, the latest is
+-- kept and the older ones, which it supersedes, are rejected.
+UPDATE procedure_cost_runs
+SET status = 'rejected', reviewed_by = 'system', reviewed_at = CURRENT_TIMESTAMP
+WHERE status = 'pending'
+    AND id < (SELECT MAX(id) FROM procedure_cost_runs WHERE status = 'pending');
+
+CREATE UNIQUE INDEX idx_procedure_cost_runs_one_pending ON procedure_cost_runs (status) WHERE status = 'pending';
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_procedure_cost_runs_one_pending;
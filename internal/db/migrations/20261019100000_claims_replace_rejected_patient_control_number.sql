@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- a rejected X12 claim can be corrected and filed again as a replacement under the same CLM01
+-- patient control number, so only claims that were not rejected need to be unique
+DROP INDEX IF EXISTS idx_claims_patient_control_number;
+
+CREATE UNIQUE INDEX idx_claims_patient_control_number ON claims (x12_sender_id, patient_control_number)
+    WHERE patient_control_number IS NOT NULL AND status <> 'REJECTED';
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_claims_patient_control_number;
+
+CREATE UNIQUE INDEX idx_claims_patient_control_number ON claims (x12_sender_id, patient_control_number)
+    WHERE patient_control_number IS NOT NULL;
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// rolledBackKey marks a transaction span whose transaction was rolled back.
+const rolledBackKey = attribute.Key("db.transaction.rolled_back")
+
+// queryExecutor is the part of *sql.DB and *sql.Tx that SQLOperations traces.
+type queryExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func tracedExec(ctx context.Context, executor queryExecutor, query string, args []interface{}) (sql.Result, error) {
+	ctx, span := startStatementSpan(ctx, query)
+	result, err := executor.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+// tracedQuery ends the span once the query has run; reading the rows is not included.
+func tracedQuery(ctx context.Context, executor queryExecutor, query string, args []interface{}) (*sql.Rows, error) {
+	ctx, span := startStatementSpan(ctx, query)
+	rows, err := executor.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func tracedQueryRow(ctx context.Context, executor queryExecutor, query string, args []interface{}) *sql.Row {
+	ctx, span := startStatementSpan(ctx, query)
+	row := executor.QueryRowContext(ctx, query, args...)
+	err := row.Err()
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	tracing.End(span, err)
+	return row
+}
+
+// startStatementSpan starts a span named after the statement, such as "SELECT claims". The query
+// text is recorded without its arguments, which may hold personal data.
+func startStatementSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation, table := statementName(query)
+	name := strings.TrimSpace(operation + " " + table)
+
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(strings.Join(strings.Fields(query), " ")),
+	}
+	if table != "" {
+		attrs = append(attrs, semconv.DBCollectionName(table))
+	}
+
+	ctx, span := tracing.Start(ctx, name, attrs...)
+	return ctx, span
+}
+
+// statementName returns the SQL command of query and the table it works on: the table after
+// INTO, UPDATE or the first FROM outside parentheses, so subselects in the column list do not
+// name the statement.
+func statementName(query string) (string, string) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return "", ""
+	}
+	operation := strings.ToUpper(words[0])
+
+	tableAfter := "FROM"
+	switch operation {
+	case "INSERT":
+		tableAfter = "INTO"
+	case "UPDATE":
+		tableAfter = "UPDATE"
+	}
+
+	depth := 0
+	for i := 0; i < len(words)-1; i++ {
+		depth += strings.Count(words[i], "(") - strings.Count(words[i], ")")
+		if depth == 0 && strings.EqualFold(words[i], tableAfter) && !strings.HasPrefix(words[i+1], "(") {
+			return operation, strings.Trim(words[i+1], "\"(),;")
+		}
+	}
+	return operation, ""
+}
@@ -0,0 +1,30 @@
+package db
+
+import "testing"
+
+func TestStatementName(t *testing.T) {
+	cases := []struct {
+		query     string
+		operation string
+		table     string
+	}{
+		{"SELECT id, status FROM claims WHERE id = $1", "SELECT", "claims"},
+		{"INSERT INTO members (full_name) VALUES ($1) RETURNING id", "INSERT", "members"},
+		{"UPDATE procedure_cost_runs SET status = $1 WHERE id = $2", "UPDATE", "procedure_cost_runs"},
+		{"DELETE FROM claim_documents WHERE id = $1", "DELETE", "claim_documents"},
+		{
+			"SELECT id, COALESCE((SELECT v.average_cost FROM procedure_versions v WHERE v.procedure_id = procedures.id LIMIT 1), average_cost), currency FROM procedures WHERE code = $1",
+			"SELECT", "procedures",
+		},
+		{"SELECT COUNT(*) FROM (SELECT id FROM claims) c", "SELECT", ""},
+		{"LOCK TABLE procedure_cost_runs IN SHARE ROW EXCLUSIVE MODE", "LOCK", ""},
+		{"", "", ""},
+	}
+
+	for _, c := range cases {
+		operation, table := statementName(c.query)
+		if operation != c.operation || table != c.table {
+			t.Errorf("statementName(%q) = %q, %q; want %q, %q", c.query, operation, table, c.operation, c.table)
+		}
+	}
+}
@@ -0,0 +1,422 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/audit"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+const (
+	createAuditEntrySQL         = "INSERT INTO audit_log (actor, actor_role, action, entity_type, entity_id, before_state, after_state, request_id, ip_address) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at"
+	getAuditEntriesSQL          = "SELECT id, actor, actor_role, action, entity_type, entity_id, before_state, after_state, request_id, ip_address, chain_position, COALESCE(prev_hash, ''), COALESCE(hash, ''), created_at FROM audit_log"
+	getAuditEntriesCountSQL     = "SELECT COUNT(*) FROM audit_log"
+	sealAuditLogSQL             = "SELECT audit_log_seal()"
+	getAuditLogHeadSQL          = "SELECT COUNT(chain_position), COALESCE(MAX(chain_position), 0), COUNT(*) - COUNT(chain_position) FROM audit_log"
+	getAuditLogHeadHashSQL      = "SELECT hash FROM audit_log WHERE chain_position = $1"
+	getFirstBrokenAuditEntrySQL = "SELECT a.id FROM audit_log a LEFT JOIN audit_log p ON p.chain_position = a.chain_position - 1 WHERE a.chain_position IS NOT NULL AND (a.hash <> audit_log_hash(a) OR a.prev_hash <> COALESCE(p.hash, repeat('0', 64))) ORDER BY a.chain_position LIMIT 1"
+
+	// audited rows are read as JSON without secrets; removing a key a table does not have is a no-op
+	auditRowJSON    = "to_jsonb(t) - 'password_hash' - 'mfa_secret' - 'token_hash' - 'code_hash'"
+	getAuditRowSQL  = "SELECT " + auditRowJSON + " FROM %s t WHERE id = $1"
+	getAuditRowsSQL = "SELECT jsonb_agg(" + auditRowJSON + " ORDER BY t.id) FROM %s t WHERE %s"
+)
+
+type (
+	AuditEntryDomain interface {
+		CreateAuditEntry(ctx context.Context, operations db.SQLOperations, entry *models.AuditEntry) error
+		GetAuditEntriesCount(ctx context.Context, operations db.SQLOperations, filter *models.Filter) (int, error)
+		GetAuditEntries(ctx context.Context, operations db.SQLOperations, filter *models.Filter) ([]*models.AuditEntry, error)
+		SealAuditLog(ctx context.Context, operations db.SQLOperations) (int, error)
+		VerifyAuditLog(ctx context.Context, operations db.SQLOperations) (*models.AuditLogVerification, error)
+	}
+
+	auditEntryDomain struct{}
+)
+
+func NewAuditEntryDomain() AuditEntryDomain {
+	return &auditEntryDomain{}
+}
+
+// CreateAuditEntry appends the entry to the audit log. Its ID and time are set by the database;
+// the entry is chained into the log later, by SealAuditLog, once its transaction has committed.
+func (s *auditEntryDomain) CreateAuditEntry(
+	ctx context.Context,
+	operations db.SQLOperations,
+	entry *models.AuditEntry,
+) error {
+	err := operations.QueryRowContext(
+		ctx,
+		createAuditEntrySQL,
+		entry.Actor,
+		entry.ActorRole,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		nullJSON(entry.Before),
+		nullJSON(entry.After),
+		entry.RequestID,
+		entry.IPAddress,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("create audit entry query error: %v", err)
+	}
+	return nil
+}
+
+func (s *auditEntryDomain) GetAuditEntriesCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) (int, error) {
+	countFilter := filter.NoPagination()
+	countFilter.CountQuery = true
+
+	query, args := s.buildQuery(getAuditEntriesCountSQL, countFilter)
+	row := operations.QueryRowContext(ctx, query, args...)
+
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get audit entries count query error: %v", err)
+	}
+	return count, nil
+}
+
+func (s *auditEntryDomain) GetAuditEntries(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) ([]*models.AuditEntry, error) {
+
+	query, args := s.buildQuery(getAuditEntriesSQL, filter)
+
+	rows, err := operations.QueryContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return []*models.AuditEntry{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get audit entries query error: %v", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*models.AuditEntry, 0)
+	for rows.Next() {
+		entry, err := s.scanRow(rows)
+		if err != nil {
+			return []*models.AuditEntry{}, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if rows.Err() != nil {
+		return []*models.AuditEntry{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list audit entries err: %v", rows.Err())
+	}
+	return entries, nil
+}
+
+// SealAuditLog chains the committed entries not yet in the hash chain and returns how many it
+// added. Run it outside the transactions that write entries: it holds a lock for as long as its
+// own transaction lasts.
+func (s *auditEntryDomain) SealAuditLog(
+	ctx context.Context,
+	operations db.SQLOperations,
+) (int, error) {
+
+	var sealed int
+	err := operations.QueryRowContext(ctx, sealAuditLogSQL).Scan(&sealed)
+	if err != nil {
+		return 0, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("seal audit log query error: %v", err)
+	}
+	return sealed, nil
+}
+
+// VerifyAuditLog recomputes the hash of every sealed entry and checks it links to the entry
+// before it in the chain.
+func (s *auditEntryDomain) VerifyAuditLog(
+	ctx context.Context,
+	operations db.SQLOperations,
+) (*models.AuditLogVerification, error) {
+
+	verification := &models.AuditLogVerification{}
+
+	var headPosition int64
+	err := operations.QueryRowContext(ctx, getAuditLogHeadSQL).Scan(
+		&verification.Entries,
+		&headPosition,
+		&verification.Pending,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get audit log head query error: %v", err)
+	}
+
+	if headPosition > 0 {
+		err = operations.QueryRowContext(ctx, getAuditLogHeadHashSQL, headPosition).Scan(&verification.HeadHash)
+		if err != nil {
+			return nil, apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("get audit log head hash query error: %v", err)
+		}
+	}
+
+	var brokenAt int64
+	err = operations.QueryRowContext(ctx, getFirstBrokenAuditEntrySQL).Scan(&brokenAt)
+	if err != nil {
+		if apperr.IsNoRowsErr(err) {
+			verification.Valid = int64(verification.Entries) == headPosition
+			return verification, nil
+		}
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("verify audit log query error: %v", err)
+	}
+
+	verification.BrokenAt = &brokenAt
+	return verification, nil
+}
+
+func (s *auditEntryDomain) buildQuery(
+	query string,
+	filter *models.Filter,
+) (string, []interface{}) {
+	args := make([]interface{}, 0)
+	conditions := make([]string, 0)
+	counter := utils.NewPlaceholder()
+
+	if filter.Actor != "" {
+		conditions = append(conditions, fmt.Sprintf("actor = $%d", counter.Touch()))
+		args = append(args, filter.Actor)
+	}
+
+	if filter.Action != "" {
+		conditions = append(conditions, fmt.Sprintf("action = $%d", counter.Touch()))
+		args = append(args, filter.Action)
+	}
+
+	if filter.EntityType != "" {
+		conditions = append(conditions, fmt.Sprintf("entity_type = $%d", counter.Touch()))
+		args = append(args, filter.EntityType)
+	}
+
+	if filter.EntityID != "" {
+		conditions = append(conditions, fmt.Sprintf("entity_id = $%d", counter.Touch()))
+		args = append(args, filter.EntityID)
+	}
+
+	if filter.RequestID != "" {
+		conditions = append(conditions, fmt.Sprintf("request_id = $%d", counter.Touch()))
+		args = append(args, filter.RequestID)
+	}
+
+	if filter.FromTime != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", counter.Touch()))
+		args = append(args, *filter.FromTime)
+	}
+
+	if filter.ToTime != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", counter.Touch()))
+		args = append(args, *filter.ToTime)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if filter.CountQuery {
+		return query, args
+	}
+
+	query += " ORDER BY id DESC"
+
+	if filter.Page > 0 && filter.Per > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
+		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
+	}
+
+	return query, args
+}
+
+func (s *auditEntryDomain) scanRow(
+	row db.RowScanner,
+) (*models.AuditEntry, error) {
+
+	var entry models.AuditEntry
+	var before, after []byte
+	err := row.Scan(
+		&entry.ID,
+		&entry.Actor,
+		&entry.ActorRole,
+		&entry.Action,
+		&entry.EntityType,
+		&entry.EntityID,
+		&before,
+		&after,
+		&entry.RequestID,
+		&entry.IPAddress,
+		&entry.ChainPosition,
+		&entry.PrevHash,
+		&entry.Hash,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("scan row error: %v", err)
+	}
+
+	entry.Before = before
+	entry.After = after
+	return &entry, nil
+}
+
+// auditRow reads the row of the table with the id as it will be recorded in the audit log. It
+// returns nil when there is no such row.
+func auditRow(
+	ctx context.Context,
+	operations db.SQLOperations,
+	table string,
+	id int64,
+) (json.RawMessage, error) {
+
+	var state []byte
+	err := operations.QueryRowContext(ctx, fmt.Sprintf(getAuditRowSQL, table), id).Scan(&state)
+	if err != nil {
+		if apperr.IsNoRowsErr(err) {
+			return nil, nil
+		}
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("audit %v row query error: %v", table, err)
+	}
+	return state, nil
+}
+
+// auditRows reads every row of the table matching the condition as a JSON array, for changes
+// that touch several rows at once. It returns nil when no row matches.
+func auditRows(
+	ctx context.Context,
+	operations db.SQLOperations,
+	table string,
+	condition string,
+	args ...interface{},
+) (json.RawMessage, error) {
+
+	var state []byte
+	err := operations.QueryRowContext(ctx, fmt.Sprintf(getAuditRowsSQL, table, condition), args...).Scan(&state)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("audit %v rows query error: %v", table, err)
+	}
+	return state, nil
+}
+
+// auditChange records a change to the row of the table with the id, given its state before the
+// change. The state after is read back unless the row was deleted.
+func auditChange(
+	ctx context.Context,
+	operations db.SQLOperations,
+	action custom_types.AuditAction,
+	table string,
+	id int64,
+	before json.RawMessage,
+) error {
+
+	var after json.RawMessage
+	if action != custom_types.AuditActionDelete {
+		var err error
+		after, err = auditRow(ctx, operations, table, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return recordAudit(ctx, operations, action, table, strconv.FormatInt(id, 10), before, after)
+}
+
+// auditRowsChange records one change to several rows of the table, given their state before the
+// change. Their state after is read back with the condition unless they were deleted. entityID
+// names the rows changed, such as "user_id=7".
+func auditRowsChange(
+	ctx context.Context,
+	operations db.SQLOperations,
+	action custom_types.AuditAction,
+	table string,
+	entityID string,
+	before json.RawMessage,
+	condition string,
+	args ...interface{},
+) error {
+
+	var after json.RawMessage
+	if action != custom_types.AuditActionDelete {
+		var err error
+		after, err = auditRows(ctx, operations, table, condition, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return recordAudit(ctx, operations, action, table, entityID, before, after)
+}
+
+// recordAudit appends an entry for the change to the audit log, made by the actor in ctx. A
+// change that touched no rows is not recorded.
+func recordAudit(
+	ctx context.Context,
+	operations db.SQLOperations,
+	action custom_types.AuditAction,
+	entityType string,
+	entityID string,
+	before json.RawMessage,
+	after json.RawMessage,
+) error {
+
+	if nullJSON(before) == nil && nullJSON(after) == nil {
+		return nil
+	}
+
+	actor := audit.ActorFromContext(ctx)
+	entry := &models.AuditEntry{
+		Actor:      actor.Username,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     before,
+		After:      after,
+		RequestID:  actor.RequestID,
+		IPAddress:  actor.IPAddress,
+	}
+
+	return NewAuditEntryDomain().CreateAuditEntry(ctx, operations, entry)
+}
+
+// nullJSON passes a missing state to the database as NULL rather than an empty document.
+func nullJSON(state json.RawMessage) interface{} {
+	if len(state) == 0 || string(state) == "null" {
+		return nil
+	}
+	return string(state)
+}
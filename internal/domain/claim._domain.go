@@ -4,23 +4,44 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
 	"github.com/Doris-Mwito5/ginja-ai/internal/null"
 	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 )
 
 const (
-	createClaimSQL          = "INSERT INTO claims (member_id, provider_id, procedure_code, diagnosis_code, requested_amount, approved_amount, status, fraud_flag, rejection_reason) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
-	getClaimsSQL            = "SELECT id, member_id, provider_id, procedure_code, diagnosis_code, requested_amount, approved_amount, status, fraud_flag, rejection_reason, created_at, updated_at FROM claims"
-	getClaimByIDSQL         = getClaimsSQL + " WHERE id = $1"
-	getClaimByMemberIDSQL   = getClaimsSQL + " WHERE member_id = $1"
-	getClaimByProviderIDSQL = getClaimsSQL + " WHERE provider_id = $1"
-	getClaimsCountSQL       = "SELECT COUNT(*) FROM claims"
-	updateClaimSQL          = "UPDATE claims SET member_id = $1, provider_id = $2, procedure_code = $3, diagnosis_code = $4, requested_amount = $5, approved_amount = $6, status = $7, fraud_flag = $8, rejection_reason = $9 WHERE id = $10"
-	deleteClaimSQL          = "DELETE FROM claims WHERE id = $1"
+	createClaimSQL                    = "INSERT INTO claims (member_id, provider_id, procedure_code, diagnosis_code, requested_amount, approved_amount, status, fraud_flag, rejection_reason, procedure_version_id, service_date, admission_date, discharge_date, currency, benefit_currency, converted_amount, exchange_rate, x12_sender_id, patient_control_number) VALUES (NULLIF($1::BIGINT, 0), NULLIF($2::BIGINT, 0), NULLIF($3, ''), $4, $5, $6, $7, $8, $9, NULLIF($10::BIGINT, 0), $11, $12, $13, $14, $15, $16, $17, NULLIF($18, ''), NULLIF($19, '')) RETURNING id"
+	getClaimsSQL                      = "SELECT id, COALESCE(member_id, 0), COALESCE(provider_id, 0), COALESCE(procedure_code, ''), diagnosis_code, requested_amount, approved_amount, status, fraud_flag, rejection_reason, COALESCE(procedure_version_id, 0), service_date, admission_date, discharge_date, currency, benefit_currency, converted_amount, exchange_rate, COALESCE(payment_batch_id, 0), paid_at, COALESCE(x12_sender_id, ''), COALESCE(patient_control_number, ''), created_at, updated_at FROM claims"
+	getClaimByIDSQL                   = getClaimsSQL + " WHERE id = $1"
+	getClaimByMemberIDSQL             = getClaimsSQL + " WHERE member_id = $1"
+	getClaimByProviderIDSQL           = getClaimsSQL + " WHERE provider_id = $1"
+	getClaimByPatientControlNumberSQL = getClaimsSQL + " WHERE x12_sender_id = $1 AND patient_control_number = $2 ORDER BY status = 'REJECTED', id DESC LIMIT 1"
+	getClaimsCountSQL                 = "SELECT COUNT(*) FROM claims"
+	getApprovedAmountsSQL             = "SELECT procedure_code, approved_amount, benefit_currency FROM claims WHERE status = 'APPROVED' AND fraud_flag = false AND procedure_code IS NOT NULL AND created_at >= $1"
+	getClaimsSubmittedSQL             = getClaimsSQL + " WHERE created_at >= $1::DATE AND created_at < $2::DATE + 1 ORDER BY id ASC"
+	updateClaimSQL                    = "UPDATE claims SET member_id = NULLIF($1::BIGINT, 0), provider_id = NULLIF($2::BIGINT, 0), procedure_code = NULLIF($3, ''), diagnosis_code = $4, requested_amount = $5, approved_amount = $6, status = $7, fraud_flag = $8, rejection_reason = $9, procedure_version_id = NULLIF($10::BIGINT, 0), service_date = $11, admission_date = $12, discharge_date = $13, currency = $14, benefit_currency = $15, converted_amount = $16, exchange_rate = $17 WHERE id = $18"
+	deleteClaimSQL                    = "DELETE FROM claims WHERE id = $1"
+
+	// payableClaimSQL matches approved, non-flagged claims submitted up to $1 that are not yet in a
+	// payment batch and carry every document their procedure requires. Claims are paid in their
+	// benefit currency.
+	missingDocumentSQL            = "EXISTS (SELECT 1 FROM procedure_document_requirements r WHERE r.procedure_code = c.procedure_code AND NOT EXISTS (SELECT 1 FROM claim_attachments a WHERE a.claim_id = c.id AND a.document_type = r.document_type))"
+	payableClaimSQL               = "c.status IN ('APPROVED', 'PARTIAL') AND c.fraud_flag = false AND c.approved_amount > 0 AND c.provider_id IS NOT NULL AND c.payment_batch_id IS NULL AND c.created_at < $1::DATE + 1 AND NOT " + missingDocumentSQL
+	getPayableProvidersSQL        = "SELECT DISTINCT c.provider_id, c.benefit_currency FROM claims c WHERE " + payableClaimSQL + " ORDER BY c.provider_id, c.benefit_currency"
+	assignPayableClaimsSQL        = "WITH assigned AS (UPDATE claims c SET payment_batch_id = $2 WHERE " + payableClaimSQL + " AND c.provider_id = $3 AND c.benefit_currency = $4 RETURNING c.approved_amount) SELECT COUNT(*), COALESCE(SUM(approved_amount), 0) FROM assigned"
+	markPaymentBatchClaimsPaidSQL = "UPDATE claims SET paid_at = $1 WHERE payment_batch_id = $2"
+	getIncompleteBatchClaimsSQL   = "SELECT c.id FROM claims c WHERE c.payment_batch_id = $1 AND " + missingDocumentSQL + " ORDER BY c.id ASC"
+	auditPayableClaimsSQL         = "t.id IN (SELECT c.id FROM claims c WHERE " + payableClaimSQL + " AND c.provider_id = $2 AND c.benefit_currency = $3)"
+	auditPaymentBatchClaimsSQL    = "t.payment_batch_id = $1"
+	getRemittanceLinesSQL         = "SELECT c.id, COALESCE(c.member_id, 0), COALESCE(c.procedure_code, ''), c.service_date, c.status, c.fraud_flag, c.requested_amount, c.currency, c.exchange_rate, c.converted_amount, c.approved_amount, c.benefit_currency, COALESCE(c.rejection_reason, ''), COALESCE(c.payment_batch_id, 0), COALESCE(b.payment_reference, ''), c.paid_at FROM claims c LEFT JOIN payment_batches b ON b.id = c.payment_batch_id"
+	getRemittanceLinesByBatchSQL  = getRemittanceLinesSQL + " WHERE c.payment_batch_id = $1 ORDER BY c.id ASC"
+	getProviderStatementLinesSQL  = getRemittanceLinesSQL + " WHERE c.provider_id = $1 AND c.created_at >= $2::DATE AND c.created_at < $3::DATE + 1 ORDER BY c.id ASC"
 )
 
 type (
@@ -29,9 +50,18 @@ type (
 		GetClaimByID(ctx context.Context, operations db.SQLOperations, id int64) (*models.Claim, error)
 		GetClaimByMemberID(ctx context.Context, operations db.SQLOperations, memberID string) (*models.Claim, error)
 		GetClaimByProviderID(ctx context.Context, operations db.SQLOperations, providerID string) (*models.Claim, error)
+		GetClaimByPatientControlNumber(ctx context.Context, operations db.SQLOperations, senderID, patientControlNumber string) (*models.Claim, error)
 		GetClaimsCount(ctx context.Context, operations db.SQLOperations, memberID string, filter *models.Filter) (int, error)
 		GetClaims(ctx context.Context, operations db.SQLOperations, memberID string, filter *models.Filter) ([]*models.Claim, error)
 		DeleteClaim(ctx context.Context, operations db.SQLOperations, claimID int64) error
+		GetApprovedClaimAmounts(ctx context.Context, operations db.SQLOperations, since time.Time) ([]*models.ClaimAmount, error)
+		GetClaimsSubmittedBetween(ctx context.Context, operations db.SQLOperations, from, to time.Time) ([]*models.Claim, error)
+		GetPayableProviders(ctx context.Context, operations db.SQLOperations, submittedTo time.Time) ([]*models.PayableProvider, error)
+		AssignPayableClaimsToPaymentBatch(ctx context.Context, operations db.SQLOperations, batchID int64, payee *models.PayableProvider, submittedTo time.Time) (int, money.Money, error)
+		MarkPaymentBatchClaimsPaid(ctx context.Context, operations db.SQLOperations, batchID int64, paidAt time.Time) error
+		GetPaymentBatchClaimsMissingDocuments(ctx context.Context, operations db.SQLOperations, batchID int64) ([]int64, error)
+		GetRemittanceLinesByPaymentBatchID(ctx context.Context, operations db.SQLOperations, batchID int64) ([]*models.RemittanceLine, error)
+		GetProviderStatementLines(ctx context.Context, operations db.SQLOperations, providerID int64, from, to time.Time) ([]*models.RemittanceLine, error)
 	}
 
 	claimDomain struct{}
@@ -60,15 +90,34 @@ func (s *claimDomain) CreateClaim(
 			claim.Status,
 			claim.FraudFlag,
 			claim.RejectionReason,
+			claim.ProcedureVersionID,
+			claim.ServiceDate,
+			claim.AdmissionDate,
+			claim.DischargeDate,
+			claim.Currency,
+			claim.BenefitCurrency,
+			claim.ConvertedAmount,
+			claim.ExchangeRate,
+			claim.X12SenderID,
+			claim.PatientControlNumber,
 		).Scan(&claim.ID)
 		if err != nil {
 			return apperr.NewDatabaseError(
 				err,
 			).LogErrorMessage("create claim query error: %v", err)
 		}
-		return nil
+		action := custom_types.AuditActionCreate
+		if claim.Status.IsDecision() {
+			action = custom_types.AuditActionDecide
+		}
+		return auditChange(ctx, operations, action, "claims", claim.ID, nil)
+	}
+	before, err := auditRow(ctx, operations, "claims", claim.ID)
+	if err != nil {
+		return err
 	}
-	_, err := operations.ExecContext(
+
+	_, err = operations.ExecContext(
 		ctx,
 		updateClaimSQL,
 		claim.MemberID,
@@ -80,6 +129,14 @@ func (s *claimDomain) CreateClaim(
 		claim.Status,
 		claim.FraudFlag,
 		claim.RejectionReason,
+		claim.ProcedureVersionID,
+		claim.ServiceDate,
+		claim.AdmissionDate,
+		claim.DischargeDate,
+		claim.Currency,
+		claim.BenefitCurrency,
+		claim.ConvertedAmount,
+		claim.ExchangeRate,
 		claim.ID,
 	)
 	if err != nil {
@@ -88,7 +145,7 @@ func (s *claimDomain) CreateClaim(
 		).LogErrorMessage("update claim query error: %v", err)
 	}
 
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "claims", claim.ID, before)
 }
 
 func (s *claimDomain) GetClaimByID(
@@ -119,6 +176,25 @@ func (s *claimDomain) GetClaimByProviderID(
 	return s.scanRow(row)
 }
 
+// GetClaimByPatientControlNumber finds the claim an X12 sender filed under a CLM01 patient
+// control number. Rejected claims can be filed again under the same number, so the claim that
+// was not rejected comes first, then the latest.
+func (s *claimDomain) GetClaimByPatientControlNumber(
+	ctx context.Context,
+	operations db.SQLOperations,
+	senderID, patientControlNumber string,
+) (*models.Claim, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getClaimByPatientControlNumberSQL,
+		senderID,
+		patientControlNumber,
+	)
+
+	return s.scanRow(row)
+}
+
 func (s *claimDomain) GetClaimByMemberID(
 	ctx context.Context,
 	operations db.SQLOperations,
@@ -213,7 +289,12 @@ func (s *claimDomain) DeleteClaim(
 	claimID int64,
 ) error {
 
-	_, err := operations.ExecContext(
+	before, err := auditRow(ctx, operations, "claims", claimID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
 		ctx,
 		deleteClaimSQL,
 		claimID,
@@ -224,7 +305,316 @@ func (s *claimDomain) DeleteClaim(
 		).LogErrorMessage("delete claim err")
 	}
 
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionDelete, "claims", claimID, before)
+}
+
+// GetApprovedClaimAmounts returns the approved amount of every fully approved, non-flagged claim
+// submitted since the given time, in each claim's benefit currency.
+func (s *claimDomain) GetApprovedClaimAmounts(
+	ctx context.Context,
+	operations db.SQLOperations,
+	since time.Time,
+) ([]*models.ClaimAmount, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getApprovedAmountsSQL,
+		since,
+	)
+	if err != nil {
+		return []*models.ClaimAmount{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get approved claim amounts query error: %v", err)
+	}
+	defer rows.Close()
+
+	amounts := make([]*models.ClaimAmount, 0)
+	for rows.Next() {
+		var amount models.ClaimAmount
+		if err := rows.Scan(&amount.ProcedureCode, &amount.Amount, &amount.Amount.Currency); err != nil {
+			return []*models.ClaimAmount{}, apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("scan row error: %v", err)
+		}
+		amounts = append(amounts, &amount)
+	}
+
+	if rows.Err() != nil {
+		return []*models.ClaimAmount{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list approved claim amounts err: %v", rows.Err())
+	}
+	return amounts, nil
+}
+
+// GetClaimsSubmittedBetween returns the claims submitted on any day from from to to inclusive,
+// oldest first.
+func (s *claimDomain) GetClaimsSubmittedBetween(
+	ctx context.Context,
+	operations db.SQLOperations,
+	from, to time.Time,
+) ([]*models.Claim, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getClaimsSubmittedSQL,
+		from.Format("2006-01-02"),
+		to.Format("2006-01-02"),
+	)
+	if err != nil {
+		return []*models.Claim{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get claims submitted between query error: %v", err)
+	}
+	defer rows.Close()
+
+	claims := make([]*models.Claim, 0)
+	for rows.Next() {
+		claim, err := s.scanRow(rows)
+		if err != nil {
+			return []*models.Claim{}, err
+		}
+		claims = append(claims, claim)
+	}
+
+	if rows.Err() != nil {
+		return []*models.Claim{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list claims submitted between err: %v", rows.Err())
+	}
+	return claims, nil
+}
+
+// GetPayableProviders returns each provider and currency with payable claims submitted on or
+// before submittedTo.
+func (s *claimDomain) GetPayableProviders(
+	ctx context.Context,
+	operations db.SQLOperations,
+	submittedTo time.Time,
+) ([]*models.PayableProvider, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getPayableProvidersSQL,
+		submittedTo.Format("2006-01-02"),
+	)
+	if err != nil {
+		return []*models.PayableProvider{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get payable providers query error: %v", err)
+	}
+	defer rows.Close()
+
+	payees := make([]*models.PayableProvider, 0)
+	for rows.Next() {
+		var payee models.PayableProvider
+		if err := rows.Scan(&payee.ProviderID, &payee.Currency); err != nil {
+			return []*models.PayableProvider{}, apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("scan row error: %v", err)
+		}
+		payees = append(payees, &payee)
+	}
+
+	if rows.Err() != nil {
+		return []*models.PayableProvider{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list payable providers err: %v", rows.Err())
+	}
+	return payees, nil
+}
+
+// AssignPayableClaimsToPaymentBatch moves the provider's payable claims in the payee's currency
+// submitted on or before submittedTo into the batch and returns how many were assigned and their
+// approved total.
+func (s *claimDomain) AssignPayableClaimsToPaymentBatch(
+	ctx context.Context,
+	operations db.SQLOperations,
+	batchID int64,
+	payee *models.PayableProvider,
+	submittedTo time.Time,
+) (int, money.Money, error) {
+
+	before, err := auditRows(ctx, operations, "claims", auditPayableClaimsSQL, submittedTo.Format("2006-01-02"), payee.ProviderID, payee.Currency)
+	if err != nil {
+		return 0, money.Money{}, err
+	}
+
+	var count int
+	var total money.Money
+	err = operations.QueryRowContext(
+		ctx,
+		assignPayableClaimsSQL,
+		submittedTo.Format("2006-01-02"),
+		batchID,
+		payee.ProviderID,
+		payee.Currency,
+	).Scan(&count, &total)
+	if err != nil {
+		return 0, money.Money{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("assign payable claims query error: %v", err)
+	}
+	total.Currency = payee.Currency
+
+	entityID := fmt.Sprintf("payment_batch_id=%d", batchID)
+	err = auditRowsChange(ctx, operations, custom_types.AuditActionUpdate, "claims", entityID, before, auditPaymentBatchClaimsSQL, batchID)
+	if err != nil {
+		return 0, money.Money{}, err
+	}
+	return count, total, nil
+}
+
+func (s *claimDomain) MarkPaymentBatchClaimsPaid(
+	ctx context.Context,
+	operations db.SQLOperations,
+	batchID int64,
+	paidAt time.Time,
+) error {
+
+	before, err := auditRows(ctx, operations, "claims", auditPaymentBatchClaimsSQL, batchID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		markPaymentBatchClaimsPaidSQL,
+		paidAt,
+		batchID,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("mark payment batch claims paid query error: %v", err)
+	}
+
+	entityID := fmt.Sprintf("payment_batch_id=%d", batchID)
+	return auditRowsChange(ctx, operations, custom_types.AuditActionUpdate, "claims", entityID, before, auditPaymentBatchClaimsSQL, batchID)
+}
+
+// GetPaymentBatchClaimsMissingDocuments returns the IDs of the batch's claims that lack a document
+// their procedure requires, such as when a requirement was added after the batch was drafted.
+func (s *claimDomain) GetPaymentBatchClaimsMissingDocuments(
+	ctx context.Context,
+	operations db.SQLOperations,
+	batchID int64,
+) ([]int64, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getIncompleteBatchClaimsSQL,
+		batchID,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get incomplete batch claims query error: %v", err)
+	}
+	defer rows.Close()
+
+	claimIDs := make([]int64, 0)
+	for rows.Next() {
+		var claimID int64
+		if err := rows.Scan(&claimID); err != nil {
+			return nil, apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("scan incomplete batch claim error: %v", err)
+		}
+		claimIDs = append(claimIDs, claimID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get incomplete batch claims query error: %v", err)
+	}
+
+	return claimIDs, nil
+}
+
+func (s *claimDomain) GetRemittanceLinesByPaymentBatchID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	batchID int64,
+) ([]*models.RemittanceLine, error) {
+	return s.getRemittanceLines(ctx, operations, getRemittanceLinesByBatchSQL, batchID)
+}
+
+// GetProviderStatementLines returns the provider's claims submitted on any day from from to to
+// inclusive, oldest first.
+func (s *claimDomain) GetProviderStatementLines(
+	ctx context.Context,
+	operations db.SQLOperations,
+	providerID int64,
+	from, to time.Time,
+) ([]*models.RemittanceLine, error) {
+	return s.getRemittanceLines(
+		ctx,
+		operations,
+		getProviderStatementLinesSQL,
+		providerID,
+		from.Format("2006-01-02"),
+		to.Format("2006-01-02"),
+	)
+}
+
+func (s *claimDomain) getRemittanceLines(
+	ctx context.Context,
+	operations db.SQLOperations,
+	query string,
+	args ...interface{},
+) ([]*models.RemittanceLine, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return []*models.RemittanceLine{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get remittance lines query error: %v", err)
+	}
+	defer rows.Close()
+
+	lines := make([]*models.RemittanceLine, 0)
+	for rows.Next() {
+		var line models.RemittanceLine
+		err := rows.Scan(
+			&line.ClaimID,
+			&line.MemberID,
+			&line.ProcedureCode,
+			&line.ServiceDate,
+			&line.Status,
+			&line.FraudFlag,
+			&line.RequestedAmount,
+			&line.Currency,
+			&line.ExchangeRate,
+			&line.ConvertedAmount,
+			&line.ApprovedAmount,
+			&line.PaymentCurrency,
+			&line.AdjustmentReason,
+			&line.PaymentBatchID,
+			&line.PaymentReference,
+			&line.PaidAt,
+		)
+		if err != nil {
+			return []*models.RemittanceLine{}, apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("scan row error: %v", err)
+		}
+		line.RequestedAmount.Currency = line.Currency
+		line.ConvertedAmount.Currency = line.PaymentCurrency
+		line.ApprovedAmount.Currency = line.PaymentCurrency
+		lines = append(lines, &line)
+	}
+
+	if rows.Err() != nil {
+		return []*models.RemittanceLine{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list remittance lines err: %v", rows.Err())
+	}
+	return lines, nil
 }
 
 func (s *claimDomain) buildQuery(
@@ -291,6 +681,18 @@ func (s *claimDomain) scanRow(
 		&claim.Status,
 		&claim.FraudFlag,
 		&claim.RejectionReason,
+		&claim.ProcedureVersionID,
+		&claim.ServiceDate,
+		&claim.AdmissionDate,
+		&claim.DischargeDate,
+		&claim.Currency,
+		&claim.BenefitCurrency,
+		&claim.ConvertedAmount,
+		&claim.ExchangeRate,
+		&claim.PaymentBatchID,
+		&claim.PaidAt,
+		&claim.X12SenderID,
+		&claim.PatientControlNumber,
 		&claim.CreatedAt,
 		&claim.UpdatedAt,
 	)
@@ -299,5 +701,8 @@ func (s *claimDomain) scanRow(
 			err,
 		).LogErrorMessage("scan row error: %v", err)
 	}
+	claim.RequestedAmount.Currency = claim.Currency
+	claim.ConvertedAmount.Currency = claim.BenefitCurrency
+	claim.ApprovedAmount.Currency = claim.BenefitCurrency
 	return &claim, nil
 }
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+)
+
+const (
+	createClaimAttachmentSQL      = "INSERT INTO claim_attachments (claim_id, document_type, file_name, content_type, size_bytes, checksum_sha256, storage_key, uploaded_by) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+	getClaimAttachmentsSQL        = "SELECT id, claim_id, document_type, file_name, content_type, size_bytes, checksum_sha256, storage_key, uploaded_by, created_at, updated_at FROM claim_attachments"
+	getClaimAttachmentByIDSQL     = getClaimAttachmentsSQL + " WHERE id = $1"
+	getClaimAttachmentsByClaimSQL = getClaimAttachmentsSQL + " WHERE claim_id = $1 ORDER BY id"
+)
+
+type (
+	ClaimAttachmentDomain interface {
+		CreateClaimAttachment(ctx context.Context, operations db.SQLOperations, attachment *models.ClaimAttachment) error
+		GetClaimAttachmentByID(ctx context.Context, operations db.SQLOperations, id int64) (*models.ClaimAttachment, error)
+		GetClaimAttachments(ctx context.Context, operations db.SQLOperations, claimID int64) ([]*models.ClaimAttachment, error)
+	}
+
+	claimAttachmentDomain struct{}
+)
+
+func NewClaimAttachmentDomain() ClaimAttachmentDomain {
+	return &claimAttachmentDomain{}
+}
+
+// CreateClaimAttachment records an uploaded file. Attachments are never edited once stored.
+func (s *claimAttachmentDomain) CreateClaimAttachment(
+	ctx context.Context,
+	operations db.SQLOperations,
+	attachment *models.ClaimAttachment,
+) error {
+	attachment.Touch()
+
+	err := operations.QueryRowContext(
+		ctx,
+		createClaimAttachmentSQL,
+		attachment.ClaimID,
+		attachment.DocumentType,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.SizeBytes,
+		attachment.Checksum,
+		attachment.StorageKey,
+		attachment.UploadedBy,
+	).Scan(&attachment.ID)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("create claim attachment query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionCreate, "claim_attachments", attachment.ID, nil)
+}
+
+func (s *claimAttachmentDomain) GetClaimAttachmentByID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) (*models.ClaimAttachment, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getClaimAttachmentByIDSQL,
+		id,
+	)
+
+	return s.scanRow(row)
+}
+
+func (s *claimAttachmentDomain) GetClaimAttachments(
+	ctx context.Context,
+	operations db.SQLOperations,
+	claimID int64,
+) ([]*models.ClaimAttachment, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getClaimAttachmentsByClaimSQL,
+		claimID,
+	)
+	if err != nil {
+		return []*models.ClaimAttachment{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get claim attachments query error: %v", err)
+	}
+	defer rows.Close()
+
+	attachments := make([]*models.ClaimAttachment, 0)
+	for rows.Next() {
+		attachment, err := s.scanRow(rows)
+		if err != nil {
+			return []*models.ClaimAttachment{}, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if rows.Err() != nil {
+		return []*models.ClaimAttachment{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list claim attachments err: %v", rows.Err())
+	}
+	return attachments, nil
+}
+
+func (s *claimAttachmentDomain) scanRow(
+	row db.RowScanner,
+) (*models.ClaimAttachment, error) {
+
+	var attachment models.ClaimAttachment
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.ClaimID,
+		&attachment.DocumentType,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.SizeBytes,
+		&attachment.Checksum,
+		&attachment.StorageKey,
+		&attachment.UploadedBy,
+		&attachment.CreatedAt,
+		&attachment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("scan row error: %v", err)
+	}
+	return &attachment, nil
+}
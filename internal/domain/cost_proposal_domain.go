@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+)
+
+const (
+	createCostProposalSQL      = "INSERT INTO procedure_cost_proposals (run_id, procedure_id, procedure_code, current_cost, proposed_cost, sample_size) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	getCostProposalsByRunIDSQL = "SELECT cp.id, cp.run_id, cp.procedure_id, cp.procedure_code, cp.current_cost, cp.proposed_cost, p.currency, cp.sample_size, cp.created_at, cp.updated_at FROM procedure_cost_proposals cp JOIN procedures p ON p.id = cp.procedure_id WHERE cp.run_id = $1 ORDER BY cp.procedure_code"
+)
+
+type (
+	CostProposalDomain interface {
+		CreateCostProposal(ctx context.Context, operations db.SQLOperations, proposal *models.CostProposal) error
+		GetCostProposalsByRunID(ctx context.Context, operations db.SQLOperations, runID int64) ([]*models.CostProposal, error)
+	}
+
+	costProposalDomain struct{}
+)
+
+func NewCostProposalDomain() CostProposalDomain {
+	return &costProposalDomain{}
+}
+
+func (s *costProposalDomain) CreateCostProposal(
+	ctx context.Context,
+	operations db.SQLOperations,
+	proposal *models.CostProposal,
+) error {
+	proposal.Touch()
+
+	err := operations.QueryRowContext(
+		ctx,
+		createCostProposalSQL,
+		proposal.RunID,
+		proposal.ProcedureID,
+		proposal.ProcedureCode,
+		proposal.CurrentCost,
+		proposal.ProposedCost,
+		proposal.SampleSize,
+	).Scan(&proposal.ID)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("create cost proposal query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionCreate, "procedure_cost_proposals", proposal.ID, nil)
+}
+
+func (s *costProposalDomain) GetCostProposalsByRunID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	runID int64,
+) ([]*models.CostProposal, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCostProposalsByRunIDSQL,
+		runID,
+	)
+	if err != nil {
+		return []*models.CostProposal{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get cost proposals query error: %v", err)
+	}
+	defer rows.Close()
+
+	proposals := make([]*models.CostProposal, 0)
+	for rows.Next() {
+		var proposal models.CostProposal
+		err := rows.Scan(
+			&proposal.ID,
+			&proposal.RunID,
+			&proposal.ProcedureID,
+			&proposal.ProcedureCode,
+			&proposal.CurrentCost,
+			&proposal.ProposedCost,
+			&proposal.Currency,
+			&proposal.SampleSize,
+			&proposal.CreatedAt,
+			&proposal.UpdatedAt,
+		)
+		if err != nil {
+			return []*models.CostProposal{}, apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("scan row error: %v", err)
+		}
+		proposal.CurrentCost.Currency = proposal.Currency
+		proposal.ProposedCost.Currency = proposal.Currency
+		proposals = append(proposals, &proposal)
+	}
+
+	if rows.Err() != nil {
+		return []*models.CostProposal{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list cost proposals err: %v", rows.Err())
+	}
+	return proposals, nil
+}
@@ -0,0 +1,264 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/null"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+const (
+	createCostRunSQL       = "INSERT INTO procedure_cost_runs (method, window_days, min_samples, status, reviewed_by, reviewed_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	getCostRunsSQL         = "SELECT id, method, window_days, min_samples, status, reviewed_by, reviewed_at, created_at, updated_at FROM procedure_cost_runs"
+	getCostRunByIDSQL      = getCostRunsSQL + " WHERE id = $1"
+	getCostRunForUpdateSQL = getCostRunByIDSQL + " FOR UPDATE"
+	getPendingCostRunSQL   = getCostRunsSQL + " WHERE status = 'pending'"
+	lockCostRunsSQL        = "LOCK TABLE procedure_cost_runs IN SHARE ROW EXCLUSIVE MODE"
+	getCostRunsCountSQL    = "SELECT COUNT(*) FROM procedure_cost_runs"
+	updateCostRunSQL       = "UPDATE procedure_cost_runs SET status = $1, reviewed_by = $2, reviewed_at = $3 WHERE id = $4"
+)
+
+type (
+	CostRunDomain interface {
+		CreateCostRun(ctx context.Context, operations db.SQLOperations, run *models.CostRun) error
+		GetCostRunByID(ctx context.Context, operations db.SQLOperations, id int64) (*models.CostRun, error)
+		GetCostRunByIDForUpdate(ctx context.Context, operations db.SQLOperations, id int64) (*models.CostRun, error)
+		GetPendingCostRun(ctx context.Context, operations db.SQLOperations) (*models.CostRun, error)
+		LockCostRuns(ctx context.Context, operations db.SQLOperations) error
+		GetCostRunsCount(ctx context.Context, operations db.SQLOperations, filter *models.Filter) (int, error)
+		GetCostRuns(ctx context.Context, operations db.SQLOperations, filter *models.Filter) ([]*models.CostRun, error)
+	}
+
+	costRunDomain struct{}
+)
+
+func NewCostRunDomain() CostRunDomain {
+	return &costRunDomain{}
+}
+
+func (s *costRunDomain) CreateCostRun(
+	ctx context.Context,
+	operations db.SQLOperations,
+	run *models.CostRun,
+) error {
+	run.Touch()
+
+	if run.IsNew() {
+		err := operations.QueryRowContext(
+			ctx,
+			createCostRunSQL,
+			run.Method,
+			run.WindowDays,
+			run.MinSamples,
+			run.Status,
+			run.ReviewedBy,
+			run.ReviewedAt,
+		).Scan(&run.ID)
+		if err != nil {
+			return apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("create cost run query error: %v", err)
+		}
+		return auditChange(ctx, operations, custom_types.AuditActionCreate, "procedure_cost_runs", run.ID, nil)
+	}
+
+	before, err := auditRow(ctx, operations, "procedure_cost_runs", run.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		updateCostRunSQL,
+		run.Status,
+		run.ReviewedBy,
+		run.ReviewedAt,
+		run.ID,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("update cost run query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "procedure_cost_runs", run.ID, before)
+}
+
+func (s *costRunDomain) GetCostRunByID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) (*models.CostRun, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCostRunByIDSQL,
+		id,
+	)
+
+	return s.scanRow(row)
+}
+
+// GetCostRunByIDForUpdate reads the run and locks it until the transaction ends, so two reviews
+// of the same run take turns and the second sees the first's decision.
+func (s *costRunDomain) GetCostRunByIDForUpdate(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) (*models.CostRun, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCostRunForUpdateSQL,
+		id,
+	)
+
+	return s.scanRow(row)
+}
+
+// GetPendingCostRun returns the run waiting for review; there is at most one.
+func (s *costRunDomain) GetPendingCostRun(
+	ctx context.Context,
+	operations db.SQLOperations,
+) (*models.CostRun, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getPendingCostRunSQL,
+	)
+
+	return s.scanRow(row)
+}
+
+// LockCostRuns keeps other transactions from creating cost runs until the current one ends, while
+// still letting them read.
+func (s *costRunDomain) LockCostRuns(
+	ctx context.Context,
+	operations db.SQLOperations,
+) error {
+
+	_, err := operations.ExecContext(ctx, lockCostRunsSQL)
+	if err != nil {
+		return apperr.NewDatabaseError(err).LogErrorMessage("lock cost runs query error: %v", err)
+	}
+	return nil
+}
+
+func (s *costRunDomain) GetCostRunsCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) (int, error) {
+	countFilter := filter.NoPagination()
+	countFilter.CountQuery = true
+
+	query, args := s.buildQuery(getCostRunsCountSQL, countFilter)
+	row := operations.QueryRowContext(
+		ctx,
+		query,
+		args...,
+	)
+
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, apperr.NewDatabaseError(err).LogErrorMessage("get cost runs count query error: %v", err)
+	}
+
+	return count, nil
+}
+
+func (s *costRunDomain) GetCostRuns(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) ([]*models.CostRun, error) {
+	query, args := s.buildQuery(getCostRunsSQL, filter)
+	rows, err := operations.QueryContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return []*models.CostRun{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get cost runs query error: %v", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*models.CostRun, 0)
+	for rows.Next() {
+		run, err := s.scanRow(rows)
+		if err != nil {
+			return []*models.CostRun{}, err
+		}
+		runs = append(runs, run)
+	}
+
+	if rows.Err() != nil {
+		return []*models.CostRun{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list cost runs err: %v", rows.Err())
+	}
+	return runs, nil
+}
+
+func (s *costRunDomain) buildQuery(
+	query string,
+	filter *models.Filter,
+) (string, []interface{}) {
+	args := make([]interface{}, 0)
+	conditions := make([]string, 0)
+	counter := utils.NewPlaceholder()
+
+	if null.ValueFromNull(filter.Status) != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", counter.Touch()))
+		args = append(args, null.ValueFromNull(filter.Status))
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if filter.CountQuery {
+		return query, args
+	}
+
+	query += " ORDER BY id DESC"
+
+	if filter.Page > 0 && filter.Per > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
+		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
+	}
+
+	return query, args
+}
+
+func (s *costRunDomain) scanRow(
+	row db.RowScanner,
+) (*models.CostRun, error) {
+
+	var run models.CostRun
+	err := row.Scan(
+		&run.ID,
+		&run.Method,
+		&run.WindowDays,
+		&run.MinSamples,
+		&run.Status,
+		&run.ReviewedBy,
+		&run.ReviewedAt,
+		&run.CreatedAt,
+		&run.UpdatedAt,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("scan row error: %v", err)
+	}
+	return &run, nil
+}
@@ -0,0 +1,233 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+const (
+	createExchangeRateSQL    = "INSERT INTO exchange_rates (base_currency, quote_currency, rate, effective_from) VALUES ($1, $2, $3, $4) RETURNING id"
+	getExchangeRatesSQL      = "SELECT id, base_currency, quote_currency, rate, effective_from, created_at, updated_at FROM exchange_rates"
+	getExchangeRateSQL       = getExchangeRatesSQL + " WHERE base_currency = $1 AND quote_currency = $2 AND effective_from = $3::DATE"
+	getExchangeRateOnSQL     = getExchangeRatesSQL + " WHERE base_currency = $1 AND quote_currency = $2 AND effective_from <= $3::DATE ORDER BY effective_from DESC LIMIT 1"
+	getExchangeRatesCountSQL = "SELECT COUNT(*) FROM exchange_rates"
+	updateExchangeRateSQL    = "UPDATE exchange_rates SET rate = $1 WHERE id = $2"
+)
+
+type (
+	ExchangeRateDomain interface {
+		CreateExchangeRate(ctx context.Context, operations db.SQLOperations, rate *models.ExchangeRate) error
+		GetExchangeRate(ctx context.Context, operations db.SQLOperations, base, quote string, effectiveFrom time.Time) (*models.ExchangeRate, error)
+		GetExchangeRateOn(ctx context.Context, operations db.SQLOperations, base, quote string, on time.Time) (*models.ExchangeRate, error)
+		GetExchangeRatesCount(ctx context.Context, operations db.SQLOperations, filter *models.Filter) (int, error)
+		GetExchangeRates(ctx context.Context, operations db.SQLOperations, filter *models.Filter) ([]*models.ExchangeRate, error)
+	}
+
+	exchangeRateDomain struct{}
+)
+
+func NewExchangeRateDomain() ExchangeRateDomain {
+	return &exchangeRateDomain{}
+}
+
+func (s *exchangeRateDomain) CreateExchangeRate(
+	ctx context.Context,
+	operations db.SQLOperations,
+	rate *models.ExchangeRate,
+) error {
+	rate.Touch()
+
+	if rate.IsNew() {
+		err := operations.QueryRowContext(
+			ctx,
+			createExchangeRateSQL,
+			rate.BaseCurrency,
+			rate.QuoteCurrency,
+			rate.Rate,
+			rate.EffectiveFrom,
+		).Scan(&rate.ID)
+		if err != nil {
+			return apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("create exchange rate query error: %v", err)
+		}
+		return auditChange(ctx, operations, custom_types.AuditActionCreate, "exchange_rates", rate.ID, nil)
+	}
+
+	before, err := auditRow(ctx, operations, "exchange_rates", rate.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		updateExchangeRateSQL,
+		rate.Rate,
+		rate.ID,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("update exchange rate query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "exchange_rates", rate.ID, before)
+}
+
+// GetExchangeRate returns the pair's rate that takes effect on exactly the given date.
+func (s *exchangeRateDomain) GetExchangeRate(
+	ctx context.Context,
+	operations db.SQLOperations,
+	base, quote string,
+	effectiveFrom time.Time,
+) (*models.ExchangeRate, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getExchangeRateSQL,
+		base,
+		quote,
+		effectiveFrom,
+	)
+
+	return s.scanRow(row)
+}
+
+// GetExchangeRateOn returns the pair's rate in effect on the given date: the latest one that took
+// effect on or before it.
+func (s *exchangeRateDomain) GetExchangeRateOn(
+	ctx context.Context,
+	operations db.SQLOperations,
+	base, quote string,
+	on time.Time,
+) (*models.ExchangeRate, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getExchangeRateOnSQL,
+		base,
+		quote,
+		on,
+	)
+
+	return s.scanRow(row)
+}
+
+func (s *exchangeRateDomain) GetExchangeRatesCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) (int, error) {
+	countFilter := filter.NoPagination()
+	countFilter.CountQuery = true
+
+	query, args := s.buildQuery(getExchangeRatesCountSQL, countFilter)
+	row := operations.QueryRowContext(ctx, query, args...)
+
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get exchange rates count query error: %v", err)
+	}
+	return count, nil
+}
+
+func (s *exchangeRateDomain) GetExchangeRates(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) ([]*models.ExchangeRate, error) {
+
+	query, args := s.buildQuery(getExchangeRatesSQL, filter)
+
+	rows, err := operations.QueryContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return []*models.ExchangeRate{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get exchange rates query error: %v", err)
+	}
+	defer rows.Close()
+
+	rates := make([]*models.ExchangeRate, 0)
+	for rows.Next() {
+		rate, err := s.scanRow(rows)
+		if err != nil {
+			return []*models.ExchangeRate{}, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if rows.Err() != nil {
+		return []*models.ExchangeRate{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list exchange rates err: %v", rows.Err())
+	}
+	return rates, nil
+}
+
+func (s *exchangeRateDomain) buildQuery(
+	query string,
+	filter *models.Filter,
+) (string, []interface{}) {
+	args := make([]interface{}, 0)
+	conditions := make([]string, 0)
+	counter := utils.NewPlaceholder()
+
+	if filter.Currency != "" {
+		placeholder := counter.Touch()
+		conditions = append(conditions, fmt.Sprintf("(base_currency = $%d OR quote_currency = $%d)", placeholder, placeholder))
+		args = append(args, filter.Currency)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if filter.CountQuery {
+		return query, args
+	}
+
+	query += " ORDER BY base_currency, quote_currency, effective_from DESC"
+
+	if filter.Page > 0 && filter.Per > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
+		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
+	}
+
+	return query, args
+}
+
+func (s *exchangeRateDomain) scanRow(
+	row db.RowScanner,
+) (*models.ExchangeRate, error) {
+
+	var rate models.ExchangeRate
+	err := row.Scan(
+		&rate.ID,
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.EffectiveFrom,
+		&rate.CreatedAt,
+		&rate.UpdatedAt,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("scan row error: %v", err)
+	}
+	return &rate, nil
+}
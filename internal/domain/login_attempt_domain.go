@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+)
+
+const (
+	createLoginAttemptSQL              = "INSERT INTO login_attempts (username, ip_address, succeeded, created_at) VALUES ($1, $2, $3, $4) RETURNING id"
+	getFailedLoginAttemptsByIPCountSQL = "SELECT COUNT(*) FROM login_attempts WHERE ip_address = $1 AND succeeded = false AND created_at >= $2"
+)
+
+type (
+	LoginAttemptDomain interface {
+		CreateLoginAttempt(ctx context.Context, operations db.SQLOperations, attempt *models.LoginAttempt) error
+		GetFailedLoginAttemptsByIPCount(ctx context.Context, operations db.SQLOperations, ipAddress string, since time.Time) (int, error)
+	}
+
+	loginAttemptDomain struct{}
+)
+
+func NewLoginAttemptDomain() LoginAttemptDomain {
+	return &loginAttemptDomain{}
+}
+
+func (s *loginAttemptDomain) CreateLoginAttempt(
+	ctx context.Context,
+	operations db.SQLOperations,
+	attempt *models.LoginAttempt,
+) error {
+
+	if attempt.CreatedAt.IsZero() {
+		attempt.CreatedAt = time.Now()
+	}
+
+	err := operations.QueryRowContext(
+		ctx,
+		createLoginAttemptSQL,
+		attempt.Username,
+		attempt.IPAddress,
+		attempt.Succeeded,
+		attempt.CreatedAt,
+	).Scan(&attempt.ID)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("create login attempt query error: %v", err)
+	}
+
+	return auditChange(ctx, operations, custom_types.AuditActionCreate, "login_attempts", attempt.ID, nil)
+}
+
+func (s *loginAttemptDomain) GetFailedLoginAttemptsByIPCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	ipAddress string,
+	since time.Time,
+) (int, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getFailedLoginAttemptsByIPCountSQL,
+		ipAddress,
+		since,
+	)
+
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get failed login attempts count query error: %v", err)
+	}
+
+	return count, nil
+}
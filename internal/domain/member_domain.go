@@ -6,29 +6,42 @@ import (
 	"strings"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
 	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 )
 
 const (
-	createMemberSQL        = "INSERT INTO members (full_name, is_active, benefit_limit, used_amount) VALUES ($1, $2, $3, $4) RETURNING id"
-	getMembersSQL          = "SELECT id, full_name, is_active, benefit_limit, used_amount, created_at, updated_at FROM members"
-	getMemberByIDSQL       = getMembersSQL + " WHERE id = $1"
-	getMemberByFullNameSQL = getMembersSQL + " WHERE full_name = $1"
-	getMembersCountSQL     = "SELECT COUNT(*) FROM members"
-	updateMemberSQL        = "UPDATE members SET full_name = $1, is_active = $2, benefit_limit = $3, used_amount = $4 WHERE id = $5"
-	deleteMemberSQL        = "DELETE FROM members WHERE id = $1"
+	createMemberSQL                = "INSERT INTO members (full_name, membership_number, national_id, card_number, is_active, benefit_limit, used_amount, benefit_currency) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+	getMembersSQL                  = "SELECT id, full_name, membership_number, national_id, card_number, is_active, benefit_limit, used_amount, benefit_currency, created_at, updated_at FROM members"
+	getMemberByIDSQL               = getMembersSQL + " WHERE id = $1"
+	getMemberByIDForUpdateSQL      = getMemberByIDSQL + " FOR UPDATE"
+	getMemberByFullNameSQL         = getMembersSQL + " WHERE full_name = $1"
+	getMemberByMembershipNumberSQL = getMembersSQL + " WHERE membership_number = $1"
+	getMemberByNationalIDSQL       = getMembersSQL + " WHERE national_id = $1"
+	getMemberByCardNumberSQL       = getMembersSQL + " WHERE card_number = $1"
+	getMembersCountSQL             = "SELECT COUNT(*) FROM members"
+	updateMemberSQL                = "UPDATE members SET full_name = $1, membership_number = $2, national_id = $3, card_number = $4, is_active = $5, benefit_limit = $6, used_amount = $7, benefit_currency = $8 WHERE id = $9"
+	deleteMemberSQL                = "DELETE FROM members WHERE id = $1"
+	auditReleasedMembersSQL        = "t.id IN (SELECT member_id FROM claims WHERE id >= $1)"
+	releaseUsedAmountsSQL          = "UPDATE members m SET used_amount = GREATEST(m.used_amount - c.total, 0) FROM (SELECT member_id, SUM(approved_amount) AS total FROM claims WHERE id >= $1 AND member_id IS NOT NULL GROUP BY member_id) c WHERE m.id = c.member_id"
 )
 
 type (
 	MemberDomain interface {
 		CreateMember(ctx context.Context, operations db.SQLOperations, member *models.Member) error
 		GetMemberByID(ctx context.Context, operations db.SQLOperations, id int64) (*models.Member, error)
+		GetMemberByIDForUpdate(ctx context.Context, operations db.SQLOperations, id int64) (*models.Member, error)
 		GetMemberByFullName(ctx context.Context, operations db.SQLOperations, fullName string) (*models.Member, error)
+		GetMembersByFullName(ctx context.Context, operations db.SQLOperations, fullName string) ([]*models.Member, error)
+		GetMemberByMembershipNumber(ctx context.Context, operations db.SQLOperations, membershipNumber string) (*models.Member, error)
+		GetMemberByNationalID(ctx context.Context, operations db.SQLOperations, nationalID string) (*models.Member, error)
+		GetMemberByCardNumber(ctx context.Context, operations db.SQLOperations, cardNumber string) (*models.Member, error)
 		GetMembersCount(ctx context.Context, operations db.SQLOperations, filter *models.Filter) (int, error)
 		GetMembers(ctx context.Context, operations db.SQLOperations, filter *models.Filter) ([]*models.Member, error)
 		DeleteMember(ctx context.Context, operations db.SQLOperations, id int64) error
+		ReleaseUsedAmountsFromClaim(ctx context.Context, operations db.SQLOperations, claimID int64) error
 	}
 
 	memberDomain struct{}
@@ -50,25 +63,38 @@ func (s *memberDomain) CreateMember(
 			ctx,
 			createMemberSQL,
 			member.FullName,
+			member.MembershipNumber,
+			member.NationalID,
+			member.CardNumber,
 			member.IsActive,
 			member.BenefitLimit,
 			member.UsedAmount,
+			member.BenefitCurrency,
 		).Scan(&member.ID)
 		if err != nil {
 			return apperr.NewDatabaseError(
 				err,
 			).LogErrorMessage("create member query error: %v", err)
 		}
-		return nil
+		return auditChange(ctx, operations, custom_types.AuditActionCreate, "members", member.ID, nil)
 	}
 
-	_, err := operations.ExecContext(
+	before, err := auditRow(ctx, operations, "members", member.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
 		ctx,
 		updateMemberSQL,
 		member.FullName,
+		member.MembershipNumber,
+		member.NationalID,
+		member.CardNumber,
 		member.IsActive,
 		member.BenefitLimit,
 		member.UsedAmount,
+		member.BenefitCurrency,
 		member.ID,
 	)
 	if err != nil {
@@ -77,7 +103,7 @@ func (s *memberDomain) CreateMember(
 		).LogErrorMessage("update member query error: %v", err)
 	}
 
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "members", member.ID, before)
 }
 
 func (s *memberDomain) GetMemberByID(
@@ -94,6 +120,22 @@ func (s *memberDomain) GetMemberByID(
 	return s.scanRow(row)
 }
 
+// GetMemberByIDForUpdate reads the member and locks the row until the transaction ends, so a
+// member written back in full cannot overwrite the used amount of a claim approved meanwhile.
+func (s *memberDomain) GetMemberByIDForUpdate(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) (*models.Member, error) {
+	row := operations.QueryRowContext(
+		ctx,
+		getMemberByIDForUpdateSQL,
+		id,
+	)
+
+	return s.scanRow(row)
+}
+
 func (s *memberDomain) GetMemberByFullName(
 	ctx context.Context,
 	operations db.SQLOperations,
@@ -108,12 +150,92 @@ func (s *memberDomain) GetMemberByFullName(
 	return s.scanRow(row)
 }
 
+func (s *memberDomain) GetMemberByMembershipNumber(
+	ctx context.Context,
+	operations db.SQLOperations,
+	membershipNumber string,
+) (*models.Member, error) {
+	row := operations.QueryRowContext(
+		ctx,
+		getMemberByMembershipNumberSQL,
+		membershipNumber,
+	)
+
+	return s.scanRow(row)
+}
+
+func (s *memberDomain) GetMemberByNationalID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	nationalID string,
+) (*models.Member, error) {
+	row := operations.QueryRowContext(
+		ctx,
+		getMemberByNationalIDSQL,
+		nationalID,
+	)
+
+	return s.scanRow(row)
+}
+
+func (s *memberDomain) GetMemberByCardNumber(
+	ctx context.Context,
+	operations db.SQLOperations,
+	cardNumber string,
+) (*models.Member, error) {
+	row := operations.QueryRowContext(
+		ctx,
+		getMemberByCardNumberSQL,
+		cardNumber,
+	)
+
+	return s.scanRow(row)
+}
+
+// GetMembersByFullName returns every member with the exact name, since names are not unique.
+func (s *memberDomain) GetMembersByFullName(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fullName string,
+) ([]*models.Member, error) {
+	rows, err := operations.QueryContext(
+		ctx,
+		getMemberByFullNameSQL+" ORDER BY id",
+		fullName,
+	)
+	if err != nil {
+		return []*models.Member{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get members by full name query error: %v", err)
+	}
+	defer rows.Close()
+
+	members := make([]*models.Member, 0)
+	for rows.Next() {
+		member, err := s.scanRow(rows)
+		if err != nil {
+			return []*models.Member{}, err
+		}
+		members = append(members, member)
+	}
+
+	if rows.Err() != nil {
+		return []*models.Member{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list members by full name err: %v", rows.Err())
+	}
+	return members, nil
+}
+
 func (s *memberDomain) GetMembersCount(
 	ctx context.Context,
 	operations db.SQLOperations,
 	filter *models.Filter,
 ) (int, error) {
-	query, args := s.buildQuery(getMembersCountSQL, filter.NoPagination())
+	countFilter := filter.NoPagination()
+	countFilter.CountQuery = true
+
+	query, args := s.buildQuery(getMembersCountSQL, countFilter)
 	rows := operations.QueryRowContext(ctx, query, args...)
 	var count int
 	err := rows.Scan(&count)
@@ -131,7 +253,7 @@ func (s *memberDomain) GetMembers(
 	filter *models.Filter,
 ) ([]*models.Member, error) {
 
-	query, args := s.buildQuery(getMembersSQL, filter.NoPagination())
+	query, args := s.buildQuery(getMembersSQL, filter)
 
 	rows, err := operations.QueryContext(
 		ctx, 
@@ -170,7 +292,12 @@ func (s *memberDomain) DeleteMember(
 	operations db.SQLOperations,
 	id int64,
 ) error {
-	_, err := operations.ExecContext(
+	before, err := auditRow(ctx, operations, "members", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
 		ctx, 
 		deleteMemberSQL, 
 		id,
@@ -181,7 +308,35 @@ func (s *memberDomain) DeleteMember(
 			err,
 		).LogErrorMessage("delete member query error: %v", err)
 	}
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionDelete, "members", id, before)
+}
+
+// ReleaseUsedAmountsFromClaim takes the approved amount of the given claim and every later claim
+// back off each member's used benefit, restoring balances to what they were before that claim.
+func (s *memberDomain) ReleaseUsedAmountsFromClaim(
+	ctx context.Context,
+	operations db.SQLOperations,
+	claimID int64,
+) error {
+	before, err := auditRows(ctx, operations, "members", auditReleasedMembersSQL, claimID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		releaseUsedAmountsSQL,
+		claimID,
+	)
+
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("release used amounts query error: %v", err)
+	}
+
+	entityID := fmt.Sprintf("claim_id>=%d", claimID)
+	return auditRowsChange(ctx, operations, custom_types.AuditActionUpdate, "members", entityID, before, auditReleasedMembersSQL, claimID)
 }
 
 func (s *memberDomain) buildQuery(
@@ -193,8 +348,13 @@ func (s *memberDomain) buildQuery(
 	conditions := make([]string, 0)
 	counter := utils.NewPlaceholder()
 
+	if filter.Active.Valid {
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", counter.Touch()))
+		args = append(args, filter.Active.Bool)
+	}
+
 	if filter.Term != "" {
-		textCols := []string{"full_name"}
+		textCols := []string{"full_name", "membership_number", "national_id", "card_number"}
 		likeStatements := make([]string, 0)
 		term := strings.ToLower(filter.Term)
 
@@ -212,6 +372,12 @@ func (s *memberDomain) buildQuery(
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
+	if filter.CountQuery {
+		return query, args
+	}
+
+	query += " ORDER BY id"
+
 	if filter.Page > 0 && filter.Per > 0 {
 		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
 		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
@@ -228,9 +394,13 @@ func (s *memberDomain) scanRow(
 	err := row.Scan(
 		&member.ID,
 		&member.FullName,
+		&member.MembershipNumber,
+		&member.NationalID,
+		&member.CardNumber,
 		&member.IsActive,
 		&member.BenefitLimit,
 		&member.UsedAmount,
+		&member.BenefitCurrency,
 		&member.CreatedAt,
 		&member.UpdatedAt,
 	)
@@ -239,5 +409,7 @@ func (s *memberDomain) scanRow(
 			err,
 		).LogErrorMessage("scan row error: %v", err)
 	}
+	member.BenefitLimit.Currency = member.BenefitCurrency
+	member.UsedAmount.Currency = member.BenefitCurrency
 	return &member, nil
 }
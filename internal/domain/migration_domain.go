@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+)
+
+const (
+	// goose records every apply and roll back; the latest row of a version says whether it is applied
+	getAppliedMigrationVersionsSQL = `SELECT version_id FROM (
+		SELECT DISTINCT ON (version_id) version_id, is_applied FROM goose_db_version ORDER BY version_id, id DESC
+	) AS latest WHERE is_applied ORDER BY version_id`
+)
+
+type (
+	MigrationDomain interface {
+		GetAppliedMigrationVersions(ctx context.Context, operations db.SQLOperations) ([]int64, error)
+	}
+
+	migrationDomain struct{}
+)
+
+func NewMigrationDomain() MigrationDomain {
+	return &migrationDomain{}
+}
+
+func (s *migrationDomain) GetAppliedMigrationVersions(
+	ctx context.Context,
+	operations db.SQLOperations,
+) ([]int64, error) {
+
+	rows, err := operations.QueryContext(ctx, getAppliedMigrationVersionsSQL)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get applied migration versions query error: %v", err)
+	}
+	defer rows.Close()
+
+	versions := make([]int64, 0)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("scan applied migration version error: %v", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if rows.Err() != nil {
+		return nil, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list applied migration versions err: %v", rows.Err())
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,272 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/null"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+const (
+	createPaymentBatchSQL         = "INSERT INTO payment_batches (provider_id, status, claim_count, total_amount, currency, payment_reference, approved_by, approved_at, paid_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+	getPaymentBatchesSQL          = "SELECT id, provider_id, status, claim_count, total_amount, currency, payment_reference, approved_by, approved_at, paid_at, created_at, updated_at FROM payment_batches"
+	getPaymentBatchByIDSQL        = getPaymentBatchesSQL + " WHERE id = $1"
+	getPaymentBatchByReferenceSQL = getPaymentBatchesSQL + " WHERE payment_reference = $1"
+	getPaymentBatchesCountSQL     = "SELECT COUNT(*) FROM payment_batches"
+	updatePaymentBatchSQL         = "UPDATE payment_batches SET status = $1, claim_count = $2, total_amount = $3, payment_reference = $4, approved_by = $5, approved_at = $6, paid_at = $7 WHERE id = $8"
+	deletePaymentBatchSQL         = "DELETE FROM payment_batches WHERE id = $1"
+)
+
+type (
+	PaymentBatchDomain interface {
+		CreatePaymentBatch(ctx context.Context, operations db.SQLOperations, batch *models.PaymentBatch) error
+		GetPaymentBatchByID(ctx context.Context, operations db.SQLOperations, id int64) (*models.PaymentBatch, error)
+		GetPaymentBatchByReference(ctx context.Context, operations db.SQLOperations, reference string) (*models.PaymentBatch, error)
+		GetPaymentBatchesCount(ctx context.Context, operations db.SQLOperations, filter *models.Filter) (int, error)
+		GetPaymentBatches(ctx context.Context, operations db.SQLOperations, filter *models.Filter) ([]*models.PaymentBatch, error)
+		DeletePaymentBatch(ctx context.Context, operations db.SQLOperations, id int64) error
+	}
+
+	paymentBatchDomain struct{}
+)
+
+func NewPaymentBatchDomain() PaymentBatchDomain {
+	return &paymentBatchDomain{}
+}
+
+func (s *paymentBatchDomain) CreatePaymentBatch(
+	ctx context.Context,
+	operations db.SQLOperations,
+	batch *models.PaymentBatch,
+) error {
+	batch.Touch()
+
+	if batch.IsNew() {
+		err := operations.QueryRowContext(
+			ctx,
+			createPaymentBatchSQL,
+			batch.ProviderID,
+			batch.Status,
+			batch.ClaimCount,
+			batch.TotalAmount,
+			batch.Currency,
+			batch.PaymentReference,
+			batch.ApprovedBy,
+			batch.ApprovedAt,
+			batch.PaidAt,
+		).Scan(&batch.ID)
+		if err != nil {
+			return apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("create payment batch query error: %v", err)
+		}
+		return auditChange(ctx, operations, custom_types.AuditActionCreate, "payment_batches", batch.ID, nil)
+	}
+
+	before, err := auditRow(ctx, operations, "payment_batches", batch.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		updatePaymentBatchSQL,
+		batch.Status,
+		batch.ClaimCount,
+		batch.TotalAmount,
+		batch.PaymentReference,
+		batch.ApprovedBy,
+		batch.ApprovedAt,
+		batch.PaidAt,
+		batch.ID,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("update payment batch query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "payment_batches", batch.ID, before)
+}
+
+func (s *paymentBatchDomain) GetPaymentBatchByID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) (*models.PaymentBatch, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getPaymentBatchByIDSQL,
+		id,
+	)
+
+	return s.scanRow(row)
+}
+
+func (s *paymentBatchDomain) GetPaymentBatchByReference(
+	ctx context.Context,
+	operations db.SQLOperations,
+	reference string,
+) (*models.PaymentBatch, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getPaymentBatchByReferenceSQL,
+		reference,
+	)
+
+	return s.scanRow(row)
+}
+
+func (s *paymentBatchDomain) GetPaymentBatchesCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) (int, error) {
+	countFilter := filter.NoPagination()
+	countFilter.CountQuery = true
+
+	query, args := s.buildQuery(getPaymentBatchesCountSQL, countFilter)
+	row := operations.QueryRowContext(
+		ctx,
+		query,
+		args...,
+	)
+
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, apperr.NewDatabaseError(err).LogErrorMessage("get payment batches count query error: %v", err)
+	}
+
+	return count, nil
+}
+
+func (s *paymentBatchDomain) GetPaymentBatches(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) ([]*models.PaymentBatch, error) {
+	query, args := s.buildQuery(getPaymentBatchesSQL, filter)
+	rows, err := operations.QueryContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return []*models.PaymentBatch{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get payment batches query error: %v", err)
+	}
+	defer rows.Close()
+
+	batches := make([]*models.PaymentBatch, 0)
+	for rows.Next() {
+		batch, err := s.scanRow(rows)
+		if err != nil {
+			return []*models.PaymentBatch{}, err
+		}
+		batches = append(batches, batch)
+	}
+
+	if rows.Err() != nil {
+		return []*models.PaymentBatch{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list payment batches err: %v", rows.Err())
+	}
+	return batches, nil
+}
+
+func (s *paymentBatchDomain) DeletePaymentBatch(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) error {
+
+	before, err := auditRow(ctx, operations, "payment_batches", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		deletePaymentBatchSQL,
+		id,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("delete payment batch query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionDelete, "payment_batches", id, before)
+}
+
+func (s *paymentBatchDomain) buildQuery(
+	query string,
+	filter *models.Filter,
+) (string, []interface{}) {
+	args := make([]interface{}, 0)
+	conditions := make([]string, 0)
+	counter := utils.NewPlaceholder()
+
+	if null.ValueFromNull(filter.Status) != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", counter.Touch()))
+		args = append(args, null.ValueFromNull(filter.Status))
+	}
+
+	if filter.ProviderID != nil {
+		conditions = append(conditions, fmt.Sprintf("provider_id = $%d", counter.Touch()))
+		args = append(args, null.ValueFromNull(filter.ProviderID))
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if filter.CountQuery {
+		return query, args
+	}
+
+	query += " ORDER BY id DESC"
+
+	if filter.Page > 0 && filter.Per > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
+		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
+	}
+
+	return query, args
+}
+
+func (s *paymentBatchDomain) scanRow(
+	row db.RowScanner,
+) (*models.PaymentBatch, error) {
+
+	var batch models.PaymentBatch
+	err := row.Scan(
+		&batch.ID,
+		&batch.ProviderID,
+		&batch.Status,
+		&batch.ClaimCount,
+		&batch.TotalAmount,
+		&batch.Currency,
+		&batch.PaymentReference,
+		&batch.ApprovedBy,
+		&batch.ApprovedAt,
+		&batch.PaidAt,
+		&batch.CreatedAt,
+		&batch.UpdatedAt,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("scan row error: %v", err)
+	}
+	batch.TotalAmount.Currency = batch.Currency
+	return &batch, nil
+}
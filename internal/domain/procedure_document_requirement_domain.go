@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+)
+
+const (
+	createProcedureDocumentRequirementSQL  = "INSERT INTO procedure_document_requirements (procedure_code, document_type) VALUES ($1, $2) RETURNING id"
+	getProcedureDocumentRequirementsSQL    = "SELECT id, procedure_code, document_type, created_at, updated_at FROM procedure_document_requirements WHERE procedure_code = $1 ORDER BY document_type"
+	deleteProcedureDocumentRequirementsSQL = "DELETE FROM procedure_document_requirements WHERE procedure_code = $1"
+	auditProcedureDocumentRequirementsSQL  = "t.procedure_code = $1"
+)
+
+type (
+	ProcedureDocumentRequirementDomain interface {
+		CreateProcedureDocumentRequirement(ctx context.Context, operations db.SQLOperations, requirement *models.ProcedureDocumentRequirement) error
+		GetProcedureDocumentRequirements(ctx context.Context, operations db.SQLOperations, procedureCode string) ([]*models.ProcedureDocumentRequirement, error)
+		DeleteProcedureDocumentRequirements(ctx context.Context, operations db.SQLOperations, procedureCode string) error
+	}
+
+	procedureDocumentRequirementDomain struct{}
+)
+
+func NewProcedureDocumentRequirementDomain() ProcedureDocumentRequirementDomain {
+	return &procedureDocumentRequirementDomain{}
+}
+
+func (s *procedureDocumentRequirementDomain) CreateProcedureDocumentRequirement(
+	ctx context.Context,
+	operations db.SQLOperations,
+	requirement *models.ProcedureDocumentRequirement,
+) error {
+	requirement.Touch()
+
+	err := operations.QueryRowContext(
+		ctx,
+		createProcedureDocumentRequirementSQL,
+		requirement.ProcedureCode,
+		requirement.DocumentType,
+	).Scan(&requirement.ID)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("create procedure document requirement query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionCreate, "procedure_document_requirements", requirement.ID, nil)
+}
+
+func (s *procedureDocumentRequirementDomain) GetProcedureDocumentRequirements(
+	ctx context.Context,
+	operations db.SQLOperations,
+	procedureCode string,
+) ([]*models.ProcedureDocumentRequirement, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getProcedureDocumentRequirementsSQL,
+		procedureCode,
+	)
+	if err != nil {
+		return []*models.ProcedureDocumentRequirement{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get procedure document requirements query error: %v", err)
+	}
+	defer rows.Close()
+
+	requirements := make([]*models.ProcedureDocumentRequirement, 0)
+	for rows.Next() {
+		var requirement models.ProcedureDocumentRequirement
+		err := rows.Scan(
+			&requirement.ID,
+			&requirement.ProcedureCode,
+			&requirement.DocumentType,
+			&requirement.CreatedAt,
+			&requirement.UpdatedAt,
+		)
+		if err != nil {
+			return []*models.ProcedureDocumentRequirement{}, apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("scan row error: %v", err)
+		}
+		requirements = append(requirements, &requirement)
+	}
+
+	if rows.Err() != nil {
+		return []*models.ProcedureDocumentRequirement{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list procedure document requirements err: %v", rows.Err())
+	}
+	return requirements, nil
+}
+
+func (s *procedureDocumentRequirementDomain) DeleteProcedureDocumentRequirements(
+	ctx context.Context,
+	operations db.SQLOperations,
+	procedureCode string,
+) error {
+	before, err := auditRows(ctx, operations, "procedure_document_requirements", auditProcedureDocumentRequirementsSQL, procedureCode)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		deleteProcedureDocumentRequirementsSQL,
+		procedureCode,
+	)
+
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("delete procedure document requirements query error: %v", err)
+	}
+
+	entityID := "procedure_code=" + procedureCode
+	return auditRowsChange(ctx, operations, custom_types.AuditActionDelete, "procedure_document_requirements", entityID, before, auditProcedureDocumentRequirementsSQL, procedureCode)
+}
@@ -6,18 +6,20 @@ import (
 	"strings"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
 	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 )
 
+// average_cost is read from the procedure version in effect today, falling back to the stored column.
 const (
-	createProcedureSQL    = "INSERT INTO procedures (code, description, average_cost) VALUES ($1, $2, $3) RETURNING id"
-	getProceduresSQL      = "SELECT id, code, description, average_cost, created_at, updated_at FROM procedures"
+	createProcedureSQL    = "INSERT INTO procedures (code, description, average_cost, currency) VALUES ($1, $2, $3, $4) RETURNING id"
+	getProceduresSQL      = "SELECT id, code, description, COALESCE((SELECT v.average_cost FROM procedure_versions v WHERE v.procedure_id = procedures.id AND v.effective_from <= CURRENT_DATE ORDER BY v.effective_from DESC LIMIT 1), average_cost), currency, created_at, updated_at FROM procedures"
 	getProcedureByIDSQL   = getProceduresSQL + " WHERE id = $1"
 	getProcedureByCodeSQL = getProceduresSQL + " WHERE code = $1"
 	getProceduresCountSQL = "SELECT COUNT(*) FROM procedures"
-	updateProcedureSQL    = "UPDATE procedures SET code = $1, description = $2, average_cost = $3 WHERE id = $4"
+	updateProcedureSQL    = "UPDATE procedures SET code = $1, description = $2, average_cost = $3, currency = $4 WHERE id = $5"
 	deleteProcedureSQL    = "DELETE FROM procedures WHERE id = $1"
 )
 
@@ -53,20 +55,27 @@ func (s *procedureDomain) CreateProcedure(
 			procedure.Code,
 			procedure.Description,
 			procedure.AverageCost,
+			procedure.Currency,
 		).Scan(&procedure.ID)
 		if err != nil {
 			return apperr.NewDatabaseError(
 				err,
 			).LogErrorMessage("create procedure query error: %v", err)
 		}
-		return nil
+		return auditChange(ctx, operations, custom_types.AuditActionCreate, "procedures", procedure.ID, nil)
 	}
-	_, err := operations.ExecContext(
+	before, err := auditRow(ctx, operations, "procedures", procedure.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
 		ctx,
 		updateProcedureSQL,
 		procedure.Code,
 		procedure.Description,
 		procedure.AverageCost,
+		procedure.Currency,
 		procedure.ID,
 	)
 	if err != nil {
@@ -74,7 +83,7 @@ func (s *procedureDomain) CreateProcedure(
 			err,
 		).LogErrorMessage("update procedure query error: %v", err)
 	}
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "procedures", procedure.ID, before)
 }
 
 func (s *procedureDomain) GetProcedureByID(
@@ -174,7 +183,12 @@ func (s *procedureDomain) DeleteProcedure(
 	operations db.SQLOperations,
 	id int64,
 ) error {
-	_, err := operations.ExecContext(
+	before, err := auditRow(ctx, operations, "procedures", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
 		ctx,
 		deleteProcedureSQL,
 		id,
@@ -185,7 +199,7 @@ func (s *procedureDomain) DeleteProcedure(
 			err,
 		).LogErrorMessage("delete procedure query error: %v", err)
 	}
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionDelete, "procedures", id, before)
 }
 
 func (s *procedureDomain) buildQuery(
@@ -227,6 +241,7 @@ func (s *procedureDomain) scanRow(
 		&procedure.Code,
 		&procedure.Description,
 		&procedure.AverageCost,
+		&procedure.Currency,
 		&procedure.CreatedAt,
 		&procedure.UpdatedAt,
 	)
@@ -235,5 +250,6 @@ func (s *procedureDomain) scanRow(
 			err,
 		).LogErrorMessage("scan row error: %v", err)
 	}
+	procedure.AverageCost.Currency = procedure.Currency
 	return &procedure, nil
 }
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+)
+
+const (
+	createProcedureVersionSQL          = "INSERT INTO procedure_versions (procedure_id, average_cost, effective_from, effective_to) VALUES ($1, $2, $3, $4) RETURNING id"
+	getProcedureVersionsSQL            = "SELECT v.id, v.procedure_id, p.code, v.average_cost, p.currency, v.effective_from, v.effective_to, v.created_at, v.updated_at FROM procedure_versions v JOIN procedures p ON p.id = v.procedure_id"
+	getProcedureVersionByIDSQL         = getProcedureVersionsSQL + " WHERE v.id = $1"
+	getProcedureVersionOnSQL           = getProcedureVersionsSQL + " WHERE p.code = $1 AND v.effective_from <= $2::DATE AND (v.effective_to IS NULL OR v.effective_to >= $2::DATE) ORDER BY v.effective_from DESC LIMIT 1"
+	getLatestProcedureVersionSQL       = getProcedureVersionsSQL + " WHERE v.procedure_id = $1 ORDER BY v.effective_from DESC LIMIT 1"
+	getProcedureVersionsByProcedureSQL = getProcedureVersionsSQL + " WHERE v.procedure_id = $1 ORDER BY v.effective_from"
+	updateProcedureVersionSQL          = "UPDATE procedure_versions SET average_cost = $1, effective_from = $2, effective_to = $3 WHERE id = $4"
+)
+
+type (
+	ProcedureVersionDomain interface {
+		CreateProcedureVersion(ctx context.Context, operations db.SQLOperations, version *models.ProcedureVersion) error
+		GetProcedureVersionByID(ctx context.Context, operations db.SQLOperations, id int64) (*models.ProcedureVersion, error)
+		GetProcedureVersionOn(ctx context.Context, operations db.SQLOperations, procedureCode string, on time.Time) (*models.ProcedureVersion, error)
+		GetLatestProcedureVersion(ctx context.Context, operations db.SQLOperations, procedureID int64) (*models.ProcedureVersion, error)
+		GetProcedureVersions(ctx context.Context, operations db.SQLOperations, procedureID int64) ([]*models.ProcedureVersion, error)
+	}
+
+	procedureVersionDomain struct{}
+)
+
+func NewProcedureVersionDomain() ProcedureVersionDomain {
+	return &procedureVersionDomain{}
+}
+
+func (s *procedureVersionDomain) CreateProcedureVersion(
+	ctx context.Context,
+	operations db.SQLOperations,
+	version *models.ProcedureVersion,
+) error {
+	version.Touch()
+
+	if version.IsNew() {
+		err := operations.QueryRowContext(
+			ctx,
+			createProcedureVersionSQL,
+			version.ProcedureID,
+			version.AverageCost,
+			version.EffectiveFrom,
+			version.EffectiveTo,
+		).Scan(&version.ID)
+		if err != nil {
+			return apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("create procedure version query error: %v", err)
+		}
+		return auditChange(ctx, operations, custom_types.AuditActionCreate, "procedure_versions", version.ID, nil)
+	}
+
+	before, err := auditRow(ctx, operations, "procedure_versions", version.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		updateProcedureVersionSQL,
+		version.AverageCost,
+		version.EffectiveFrom,
+		version.EffectiveTo,
+		version.ID,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("update procedure version query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "procedure_versions", version.ID, before)
+}
+
+func (s *procedureVersionDomain) GetProcedureVersionByID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) (*models.ProcedureVersion, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getProcedureVersionByIDSQL,
+		id,
+	)
+
+	return s.scanRow(row)
+}
+
+// GetProcedureVersionOn returns the procedure price in effect on the given date.
+func (s *procedureVersionDomain) GetProcedureVersionOn(
+	ctx context.Context,
+	operations db.SQLOperations,
+	procedureCode string,
+	on time.Time,
+) (*models.ProcedureVersion, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getProcedureVersionOnSQL,
+		procedureCode,
+		on,
+	)
+
+	return s.scanRow(row)
+}
+
+func (s *procedureVersionDomain) GetLatestProcedureVersion(
+	ctx context.Context,
+	operations db.SQLOperations,
+	procedureID int64,
+) (*models.ProcedureVersion, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getLatestProcedureVersionSQL,
+		procedureID,
+	)
+
+	return s.scanRow(row)
+}
+
+func (s *procedureVersionDomain) GetProcedureVersions(
+	ctx context.Context,
+	operations db.SQLOperations,
+	procedureID int64,
+) ([]*models.ProcedureVersion, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getProcedureVersionsByProcedureSQL,
+		procedureID,
+	)
+	if err != nil {
+		return []*models.ProcedureVersion{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get procedure versions query error: %v", err)
+	}
+	defer rows.Close()
+
+	versions := make([]*models.ProcedureVersion, 0)
+	for rows.Next() {
+		version, err := s.scanRow(rows)
+		if err != nil {
+			return []*models.ProcedureVersion{}, err
+		}
+		versions = append(versions, version)
+	}
+
+	if rows.Err() != nil {
+		return []*models.ProcedureVersion{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list procedure versions err: %v", rows.Err())
+	}
+	return versions, nil
+}
+
+func (s *procedureVersionDomain) scanRow(
+	row db.RowScanner,
+) (*models.ProcedureVersion, error) {
+
+	var version models.ProcedureVersion
+	err := row.Scan(
+		&version.ID,
+		&version.ProcedureID,
+		&version.ProcedureCode,
+		&version.AverageCost,
+		&version.Currency,
+		&version.EffectiveFrom,
+		&version.EffectiveTo,
+		&version.CreatedAt,
+		&version.UpdatedAt,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("scan row error: %v", err)
+	}
+	version.AverageCost.Currency = version.Currency
+	return &version, nil
+}
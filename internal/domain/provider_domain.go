@@ -6,19 +6,23 @@ import (
 	"strings"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/null"
 	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 )
 
 const (
-	createProviderSQL    = "INSERT INTO providers (name, location) VALUES ($1, $2) RETURNING id"
-	getProvidersSQL      = "SELECT id, name, location, created_at, updated_at FROM providers"
-	getProviderByIDSQL   = getProvidersSQL + " WHERE id = $1"
-	getProviderByNameSQL = getProvidersSQL + " WHERE name = $1"
-	getProvidersCountSQL = "SELECT COUNT(*) FROM providers"
-	updateProviderSQL    = "UPDATE providers SET name = $1, location = $2 WHERE id = $3"
-	deleteProviderSQL    = "DELETE FROM providers WHERE id = $1"
+	createProviderSQL             = "INSERT INTO providers (name, location, licence_number, accreditation_expiry, network_tier, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	getProvidersSQL               = "SELECT id, name, location, licence_number, accreditation_expiry, network_tier, status, created_at, updated_at FROM providers"
+	getProviderByIDSQL            = getProvidersSQL + " WHERE id = $1"
+	getProviderByNameSQL          = getProvidersSQL + " WHERE name = $1"
+	getProviderByLicenceNumberSQL = getProvidersSQL + " WHERE licence_number = $1"
+	getProviderByNameLocationSQL  = getProvidersSQL + " WHERE name = $1 AND COALESCE(location, '') = $2"
+	getProvidersCountSQL          = "SELECT COUNT(*) FROM providers"
+	updateProviderSQL             = "UPDATE providers SET name = $1, location = $2, licence_number = $3, accreditation_expiry = $4, network_tier = $5, status = $6 WHERE id = $7"
+	deleteProviderSQL             = "DELETE FROM providers WHERE id = $1"
 )
 
 type (
@@ -26,6 +30,8 @@ type (
 		CreateProvider(ctx context.Context, operations db.SQLOperations, provider *models.Provider) error
 		GetProviderByID(ctx context.Context, operations db.SQLOperations, id int64) (*models.Provider, error)
 		GetProviderByName(ctx context.Context, operations db.SQLOperations, name string) (*models.Provider, error)
+		GetProviderByLicenceNumber(ctx context.Context, operations db.SQLOperations, licenceNumber string) (*models.Provider, error)
+		GetProviderByNameAndLocation(ctx context.Context, operations db.SQLOperations, name, location string) (*models.Provider, error)
 		GetProvidersCount(ctx context.Context, operations db.SQLOperations, filter *models.Filter) (int, error)
 		GetProviders(ctx context.Context, operations db.SQLOperations, filter *models.Filter) ([]*models.Provider, error)
 		DeleteProvider(ctx context.Context, operations db.SQLOperations, id int64) error
@@ -51,19 +57,32 @@ func (s *providerDomain) CreateProvider(
 			createProviderSQL,
 			provider.Name,
 			provider.Location,
+			provider.LicenceNumber,
+			provider.AccreditationExpiry,
+			provider.NetworkTier,
+			provider.Status,
 		).Scan(&provider.ID)
 		if err != nil {
 			return apperr.NewDatabaseError(
 				err,
 			).LogErrorMessage("create provider query error: %v", err)
 		}
-		return nil
+		return auditChange(ctx, operations, custom_types.AuditActionCreate, "providers", provider.ID, nil)
 	}
-	_, err := operations.ExecContext(
+	before, err := auditRow(ctx, operations, "providers", provider.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
 		ctx,
 		updateProviderSQL,
 		provider.Name,
 		provider.Location,
+		provider.LicenceNumber,
+		provider.AccreditationExpiry,
+		provider.NetworkTier,
+		provider.Status,
 		provider.ID,
 	)
 	if err != nil {
@@ -71,7 +90,7 @@ func (s *providerDomain) CreateProvider(
 			err,
 		).LogErrorMessage("update provider query error: %v", err)
 	}
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "providers", provider.ID, before)
 }
 
 func (s *providerDomain) GetProviderByID(
@@ -102,12 +121,47 @@ func (s *providerDomain) GetProviderByName(
 	return s.scanRow(row)
 }	
 
+func (s *providerDomain) GetProviderByLicenceNumber(
+	ctx context.Context,
+	operations db.SQLOperations,
+	licenceNumber string,
+) (*models.Provider, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getProviderByLicenceNumberSQL,
+		licenceNumber,
+	)
+
+	return s.scanRow(row)
+}
+
+func (s *providerDomain) GetProviderByNameAndLocation(
+	ctx context.Context,
+	operations db.SQLOperations,
+	name,
+	location string,
+) (*models.Provider, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getProviderByNameLocationSQL,
+		name,
+		location,
+	)
+
+	return s.scanRow(row)
+}
+
 func (s *providerDomain) GetProvidersCount(
 	ctx context.Context,
 	operations db.SQLOperations,
 	filter *models.Filter,
 ) (int, error) {
-	query, args := s.buildQuery(getProvidersCountSQL, filter.NoPagination())
+	countFilter := filter.NoPagination()
+	countFilter.CountQuery = true
+
+	query, args := s.buildQuery(getProvidersCountSQL, countFilter)
 	rows := operations.QueryRowContext(
 		ctx, 
 		query, 
@@ -128,7 +182,7 @@ func (s *providerDomain) GetProviders(
 	operations db.SQLOperations,
 	filter *models.Filter,
 ) ([]*models.Provider, error) {
-	query, args := s.buildQuery(getProvidersSQL, filter.NoPagination())
+	query, args := s.buildQuery(getProvidersSQL, filter)
 	rows, err := operations.QueryContext(
 		ctx, 
 		query, 
@@ -163,7 +217,12 @@ func (s *providerDomain) DeleteProvider(
 	operations db.SQLOperations,
 	id int64,
 ) error {
-	_, err := operations.ExecContext(
+	before, err := auditRow(ctx, operations, "providers", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
 		ctx,
 		deleteProviderSQL,
 		id,
@@ -171,7 +230,7 @@ func (s *providerDomain) DeleteProvider(
 	if err != nil {
 		return apperr.NewDatabaseError(err).LogErrorMessage("delete provider query error: %v", err)
 	}
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionDelete, "providers", id, before)
 }
 
 func (s *providerDomain) buildQuery(
@@ -182,8 +241,18 @@ func (s *providerDomain) buildQuery(
 	conditions := make([]string, 0)
 	counter := utils.NewPlaceholder()
 
+	if null.ValueFromNull(filter.Status) != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", counter.Touch()))
+		args = append(args, null.ValueFromNull(filter.Status))
+	}
+
+	if filter.Type != "" {
+		conditions = append(conditions, fmt.Sprintf("network_tier = $%d", counter.Touch()))
+		args = append(args, filter.Type)
+	}
+
 	if filter.Term != "" {
-		textCols := []string{"name", "location"}
+		textCols := []string{"name", "location", "licence_number"}
 		likeStatements := make([]string, 0)
 		term := strings.ToLower(filter.Term)
 		for _, col := range textCols {
@@ -198,6 +267,12 @@ func (s *providerDomain) buildQuery(
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
+	if filter.CountQuery {
+		return query, args
+	}
+
+	query += " ORDER BY id"
+
 	if filter.Page > 0 && filter.Per > 0 {
 		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
 		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
@@ -215,6 +290,10 @@ func (s *providerDomain) scanRow(
 		&provider.ID,
 		&provider.Name,
 		&provider.Location,
+		&provider.LicenceNumber,
+		&provider.AccreditationExpiry,
+		&provider.NetworkTier,
+		&provider.Status,
 		&provider.CreatedAt,
 		&provider.UpdatedAt,
 	)
@@ -0,0 +1,293 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/null"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+const (
+	createProviderTariffSQL      = "INSERT INTO provider_tariffs (provider_id, procedure_code, agreed_price, currency, effective_from, effective_to) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	getProviderTariffsSQL        = "SELECT id, provider_id, procedure_code, agreed_price, currency, effective_from, effective_to, created_at, updated_at FROM provider_tariffs"
+	getProviderTariffByIDSQL     = getProviderTariffsSQL + " WHERE id = $1"
+	getActiveProviderTariffSQL   = getProviderTariffsSQL + " WHERE provider_id = $1 AND procedure_code = $2 AND effective_from <= $3::DATE AND (effective_to IS NULL OR effective_to >= $3::DATE) ORDER BY effective_from DESC LIMIT 1"
+	getProviderTariffsCountSQL   = "SELECT COUNT(*) FROM provider_tariffs"
+	getOverlappingTariffCountSQL = "SELECT COUNT(*) FROM provider_tariffs WHERE provider_id = $1 AND procedure_code = $2 AND id <> $3 AND effective_from <= COALESCE($4::DATE, 'infinity'::DATE) AND COALESCE(effective_to, 'infinity'::DATE) >= $5::DATE"
+	updateProviderTariffSQL      = "UPDATE provider_tariffs SET agreed_price = $1, currency = $2, effective_from = $3, effective_to = $4 WHERE id = $5"
+	deleteProviderTariffSQL      = "DELETE FROM provider_tariffs WHERE id = $1"
+)
+
+type (
+	ProviderTariffDomain interface {
+		CreateProviderTariff(ctx context.Context, operations db.SQLOperations, tariff *models.ProviderTariff) error
+		GetProviderTariffByID(ctx context.Context, operations db.SQLOperations, id int64) (*models.ProviderTariff, error)
+		GetActiveProviderTariff(ctx context.Context, operations db.SQLOperations, providerID int64, procedureCode string, on time.Time) (*models.ProviderTariff, error)
+		GetOverlappingProviderTariffsCount(ctx context.Context, operations db.SQLOperations, tariff *models.ProviderTariff) (int, error)
+		GetProviderTariffsCount(ctx context.Context, operations db.SQLOperations, filter *models.Filter) (int, error)
+		GetProviderTariffs(ctx context.Context, operations db.SQLOperations, filter *models.Filter) ([]*models.ProviderTariff, error)
+		DeleteProviderTariff(ctx context.Context, operations db.SQLOperations, id int64) error
+	}
+
+	providerTariffDomain struct{}
+)
+
+func NewProviderTariffDomain() ProviderTariffDomain {
+	return &providerTariffDomain{}
+}
+
+func (s *providerTariffDomain) CreateProviderTariff(
+	ctx context.Context,
+	operations db.SQLOperations,
+	tariff *models.ProviderTariff,
+) error {
+	tariff.Touch()
+
+	if tariff.IsNew() {
+		err := operations.QueryRowContext(
+			ctx,
+			createProviderTariffSQL,
+			tariff.ProviderID,
+			tariff.ProcedureCode,
+			tariff.AgreedPrice,
+			tariff.Currency,
+			tariff.EffectiveFrom,
+			tariff.EffectiveTo,
+		).Scan(&tariff.ID)
+		if err != nil {
+			return apperr.NewDatabaseError(
+				err,
+			).LogErrorMessage("create provider tariff query error: %v", err)
+		}
+		return auditChange(ctx, operations, custom_types.AuditActionCreate, "provider_tariffs", tariff.ID, nil)
+	}
+
+	before, err := auditRow(ctx, operations, "provider_tariffs", tariff.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		updateProviderTariffSQL,
+		tariff.AgreedPrice,
+		tariff.Currency,
+		tariff.EffectiveFrom,
+		tariff.EffectiveTo,
+		tariff.ID,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("update provider tariff query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "provider_tariffs", tariff.ID, before)
+}
+
+func (s *providerTariffDomain) GetProviderTariffByID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) (*models.ProviderTariff, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getProviderTariffByIDSQL,
+		id,
+	)
+
+	return s.scanRow(row)
+}
+
+// GetActiveProviderTariff returns the tariff in force for the provider and procedure on the given date.
+func (s *providerTariffDomain) GetActiveProviderTariff(
+	ctx context.Context,
+	operations db.SQLOperations,
+	providerID int64,
+	procedureCode string,
+	on time.Time,
+) (*models.ProviderTariff, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getActiveProviderTariffSQL,
+		providerID,
+		procedureCode,
+		on,
+	)
+
+	return s.scanRow(row)
+}
+
+// GetOverlappingProviderTariffsCount counts other tariffs for the same provider and procedure whose
+// effective period intersects the given tariff's period.
+func (s *providerTariffDomain) GetOverlappingProviderTariffsCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	tariff *models.ProviderTariff,
+) (int, error) {
+
+	var count int
+	err := operations.QueryRowContext(
+		ctx,
+		getOverlappingTariffCountSQL,
+		tariff.ProviderID,
+		tariff.ProcedureCode,
+		tariff.ID,
+		tariff.EffectiveTo,
+		tariff.EffectiveFrom,
+	).Scan(&count)
+	if err != nil {
+		return 0, apperr.NewDatabaseError(err).LogErrorMessage("get overlapping provider tariffs count query error: %v", err)
+	}
+
+	return count, nil
+}
+
+func (s *providerTariffDomain) GetProviderTariffsCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) (int, error) {
+	countFilter := filter.NoPagination()
+	countFilter.CountQuery = true
+
+	query, args := s.buildQuery(getProviderTariffsCountSQL, countFilter)
+	row := operations.QueryRowContext(
+		ctx,
+		query,
+		args...,
+	)
+
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, apperr.NewDatabaseError(err).LogErrorMessage("get provider tariffs count query error: %v", err)
+	}
+
+	return count, nil
+}
+
+func (s *providerTariffDomain) GetProviderTariffs(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *models.Filter,
+) ([]*models.ProviderTariff, error) {
+	query, args := s.buildQuery(getProviderTariffsSQL, filter)
+	rows, err := operations.QueryContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return []*models.ProviderTariff{}, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("get provider tariffs query error: %v", err)
+	}
+	defer rows.Close()
+
+	tariffs := make([]*models.ProviderTariff, 0)
+	for rows.Next() {
+		tariff, err := s.scanRow(rows)
+		if err != nil {
+			return []*models.ProviderTariff{}, err
+		}
+		tariffs = append(tariffs, tariff)
+	}
+
+	if rows.Err() != nil {
+		return []*models.ProviderTariff{}, apperr.NewDatabaseError(
+			rows.Err(),
+		).LogErrorMessage("list provider tariffs err: %v", rows.Err())
+	}
+	return tariffs, nil
+}
+
+func (s *providerTariffDomain) DeleteProviderTariff(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) error {
+	before, err := auditRow(ctx, operations, "provider_tariffs", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		deleteProviderTariffSQL,
+		id,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(err).LogErrorMessage("delete provider tariff query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionDelete, "provider_tariffs", id, before)
+}
+
+func (s *providerTariffDomain) buildQuery(
+	query string,
+	filter *models.Filter,
+) (string, []interface{}) {
+	args := make([]interface{}, 0)
+	conditions := make([]string, 0)
+	counter := utils.NewPlaceholder()
+
+	if null.ValueFromNull(filter.ProviderID) != "" {
+		conditions = append(conditions, fmt.Sprintf("provider_id = $%d", counter.Touch()))
+		args = append(args, null.ValueFromNull(filter.ProviderID))
+	}
+
+	if filter.Term != "" {
+		conditions = append(conditions, fmt.Sprintf("LOWER(procedure_code) LIKE '%%' || $%d || '%%'", counter.Touch()))
+		args = append(args, strings.ToLower(filter.Term))
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if filter.CountQuery {
+		return query, args
+	}
+
+	query += " ORDER BY provider_id, procedure_code, effective_from"
+
+	if filter.Page > 0 && filter.Per > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
+		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
+	}
+
+	return query, args
+}
+
+func (s *providerTariffDomain) scanRow(
+	row db.RowScanner,
+) (*models.ProviderTariff, error) {
+
+	var tariff models.ProviderTariff
+	err := row.Scan(
+		&tariff.ID,
+		&tariff.ProviderID,
+		&tariff.ProcedureCode,
+		&tariff.AgreedPrice,
+		&tariff.Currency,
+		&tariff.EffectiveFrom,
+		&tariff.EffectiveTo,
+		&tariff.CreatedAt,
+		&tariff.UpdatedAt,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("scan row error: %v", err)
+	}
+	tariff.AgreedPrice.Currency = tariff.Currency
+	return &tariff, nil
+}
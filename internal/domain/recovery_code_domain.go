@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+)
+
+const (
+	createRecoveryCodeSQL          = "INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, $3) RETURNING id"
+	useRecoveryCodeSQL             = "UPDATE user_recovery_codes SET used_at = $1 WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL"
+	deleteRecoveryCodesByUserIDSQL = "DELETE FROM user_recovery_codes WHERE user_id = $1"
+	auditRecoveryCodeSQL           = "t.user_id = $1 AND t.code_hash = $2"
+	auditUnusedRecoveryCodeSQL     = auditRecoveryCodeSQL + " AND t.used_at IS NULL"
+	auditUserRecoveryCodesSQL      = "t.user_id = $1"
+)
+
+type (
+	RecoveryCodeDomain interface {
+		CreateRecoveryCode(ctx context.Context, operations db.SQLOperations, code *models.RecoveryCode) error
+		UseRecoveryCode(ctx context.Context, operations db.SQLOperations, userID int64, codeHash string) (bool, error)
+		DeleteRecoveryCodesByUserID(ctx context.Context, operations db.SQLOperations, userID int64) error
+	}
+
+	recoveryCodeDomain struct{}
+)
+
+func NewRecoveryCodeDomain() RecoveryCodeDomain {
+	return &recoveryCodeDomain{}
+}
+
+func (s *recoveryCodeDomain) CreateRecoveryCode(
+	ctx context.Context,
+	operations db.SQLOperations,
+	code *models.RecoveryCode,
+) error {
+
+	if code.CreatedAt.IsZero() {
+		code.CreatedAt = time.Now()
+	}
+
+	err := operations.QueryRowContext(
+		ctx,
+		createRecoveryCodeSQL,
+		code.UserID,
+		code.CodeHash,
+		code.CreatedAt,
+	).Scan(&code.ID)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("create recovery code query error: %v", err)
+	}
+
+	return auditChange(ctx, operations, custom_types.AuditActionCreate, "user_recovery_codes", code.ID, nil)
+}
+
+// UseRecoveryCode marks an unused code as used and reports whether one matched.
+func (s *recoveryCodeDomain) UseRecoveryCode(
+	ctx context.Context,
+	operations db.SQLOperations,
+	userID int64,
+	codeHash string,
+) (bool, error) {
+
+	before, err := auditRows(ctx, operations, "user_recovery_codes", auditUnusedRecoveryCodeSQL, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := operations.ExecContext(
+		ctx,
+		useRecoveryCodeSQL,
+		time.Now(),
+		userID,
+		codeHash,
+	)
+	if err != nil {
+		return false, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("use recovery code query error: %v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("use recovery code rows affected error: %v", err)
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	entityID := fmt.Sprintf("user_id=%d", userID)
+	err = auditRowsChange(ctx, operations, custom_types.AuditActionUpdate, "user_recovery_codes", entityID, before, auditRecoveryCodeSQL, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *recoveryCodeDomain) DeleteRecoveryCodesByUserID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	userID int64,
+) error {
+
+	before, err := auditRows(ctx, operations, "user_recovery_codes", auditUserRecoveryCodesSQL, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		deleteRecoveryCodesByUserIDSQL,
+		userID,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("delete recovery codes query error: %v", err)
+	}
+
+	entityID := fmt.Sprintf("user_id=%d", userID)
+	return auditRowsChange(ctx, operations, custom_types.AuditActionDelete, "user_recovery_codes", entityID, before, auditUserRecoveryCodesSQL, userID)
+}
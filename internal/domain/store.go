@@ -1,19 +1,45 @@
 package domain
 
 type Store struct {
-	ClaimDomain     ClaimDomain
-	MemberDomain    MemberDomain
-	ProcedureDomain ProcedureDomain
-	ProviderDomain  ProviderDomain
-	UserDomain      UserDomain
+	AuditEntryDomain                   AuditEntryDomain
+	ClaimAttachmentDomain              ClaimAttachmentDomain
+	ClaimDomain                        ClaimDomain
+	CostProposalDomain                 CostProposalDomain
+	CostRunDomain                      CostRunDomain
+	ExchangeRateDomain                 ExchangeRateDomain
+	LoginAttemptDomain                 LoginAttemptDomain
+	MemberDomain                       MemberDomain
+	MigrationDomain                    MigrationDomain
+	PaymentBatchDomain                 PaymentBatchDomain
+	ProcedureDocumentRequirementDomain ProcedureDocumentRequirementDomain
+	ProcedureDomain                    ProcedureDomain
+	ProcedureVersionDomain             ProcedureVersionDomain
+	ProviderDomain                     ProviderDomain
+	ProviderTariffDomain               ProviderTariffDomain
+	RecoveryCodeDomain                 RecoveryCodeDomain
+	UserDomain                         UserDomain
+	UserTokenDomain                    UserTokenDomain
 }
 
 func NewStore() *Store {
 	return &Store{
-		ClaimDomain:     NewClaimDomain(),
-		MemberDomain:    NewMemberDomain(),
-		ProcedureDomain: NewProcedureDomain(),
-		ProviderDomain:  NewProviderDomain(),
-		UserDomain:      NewUserDomain(),
+		AuditEntryDomain:                   NewAuditEntryDomain(),
+		ClaimAttachmentDomain:              NewClaimAttachmentDomain(),
+		ClaimDomain:                        NewClaimDomain(),
+		CostProposalDomain:                 NewCostProposalDomain(),
+		CostRunDomain:                      NewCostRunDomain(),
+		ExchangeRateDomain:                 NewExchangeRateDomain(),
+		LoginAttemptDomain:                 NewLoginAttemptDomain(),
+		MemberDomain:                       NewMemberDomain(),
+		MigrationDomain:                    NewMigrationDomain(),
+		PaymentBatchDomain:                 NewPaymentBatchDomain(),
+		ProcedureDocumentRequirementDomain: NewProcedureDocumentRequirementDomain(),
+		ProcedureDomain:                    NewProcedureDomain(),
+		ProcedureVersionDomain:             NewProcedureVersionDomain(),
+		ProviderDomain:                     NewProviderDomain(),
+		ProviderTariffDomain:               NewProviderTariffDomain(),
+		RecoveryCodeDomain:                 NewRecoveryCodeDomain(),
+		UserDomain:                         NewUserDomain(),
+		UserTokenDomain:                    NewUserTokenDomain(),
 	}
 }
@@ -4,22 +4,28 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
 	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 )
 
 const (
-	createUserSQL        = "INSERT INTO users (username, email, password_hash, is_active) VALUES ($1, $2, $3, $4) RETURNING id"
-	getUsersSQL          = "SELECT id, username, email, password_hash, is_active, created_at, updated_at FROM users"
+	createUserSQL        = "INSERT INTO users (username, email, password_hash, is_active, role, failed_login_count, locked_until, mfa_secret, mfa_enabled, mfa_last_step, email_verified, tokens_valid_after) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id"
+	getUsersSQL          = "SELECT id, username, email, password_hash, is_active, role, failed_login_count, locked_until, mfa_secret, mfa_enabled, mfa_last_step, email_verified, tokens_valid_after, created_at, updated_at FROM users"
 	getUserByIDSQL       = getUsersSQL + " WHERE id = $1"
 	getUserByUsernameSQL = getUsersSQL + " WHERE username = $1"
-	getUserByEmailSQL = getUsersSQL + " WHERE email = $1"
+	getUserByEmailSQL    = getUsersSQL + " WHERE email = $1"
 	getUsersCountSQL     = "SELECT COUNT(*) FROM users"
-	updateUserSQL        = "UPDATE users SET username = $1, email = $2, password_hash = $3, is_active = $4 WHERE id = $5"
+	updateUserSQL        = "UPDATE users SET username = $1, email = $2, password_hash = $3, is_active = $4, role = $5, failed_login_count = $6, locked_until = $7, mfa_secret = $8, mfa_enabled = $9, mfa_last_step = $10, email_verified = $11, tokens_valid_after = $12 WHERE id = $13"
 	deleteUserSQL        = "DELETE FROM users WHERE id = $1"
+	// the lockout doubles from $4 seconds for every failure past $2, capped at $5 seconds
+	incrementFailedLoginsSQL = "UPDATE users SET failed_login_count = failed_login_count + 1, locked_until = CASE WHEN failed_login_count + 1 >= $2 THEN $3::TIMESTAMPTZ + LEAST($4 * POWER(2, LEAST(failed_login_count + 1 - $2, 32)), $5) * INTERVAL '1 second' ELSE locked_until END WHERE id = $1 RETURNING failed_login_count, locked_until"
+	resetFailedLoginsSQL     = "UPDATE users SET failed_login_count = 0, locked_until = NULL WHERE id = $1"
+	useMFAStepSQL            = "UPDATE users SET mfa_last_step = $2 WHERE id = $1 AND mfa_last_step < $2"
 )
 
 type (
@@ -31,6 +37,9 @@ type (
 		GetUsersCount(ctx context.Context, operations db.SQLOperations, filter *models.Filter) (int, error)
 		GetUsers(ctx context.Context, operations db.SQLOperations, filter *models.Filter) ([]*models.User, error)
 		DeleteUser(ctx context.Context, operations db.SQLOperations, id int64) error
+		IncrementFailedLogins(ctx context.Context, operations db.SQLOperations, user *models.User, lockAt int, lockout, maxLockout time.Duration) error
+		ResetFailedLogins(ctx context.Context, operations db.SQLOperations, user *models.User) error
+		UseMFAStep(ctx context.Context, operations db.SQLOperations, user *models.User, step int64) (bool, error)
 	}
 
 	userDomain struct{}
@@ -54,25 +63,46 @@ func (s *userDomain) CreateUser(
 			user.Email,
 			user.PasswordHash,
 			user.IsActive,
+			user.Role,
+			user.FailedLoginCount,
+			user.LockedUntil,
+			user.MFASecret,
+			user.MFAEnabled,
+			user.MFALastStep,
+			user.EmailVerified,
+			user.TokensValidAfter,
 		).Scan(&user.ID)
 		if err != nil {
 			return apperr.NewDatabaseError(err).LogErrorMessage("create user query error: %v", err)
 		}
-		return nil
+		return auditChange(ctx, operations, custom_types.AuditActionCreate, "users", user.ID, nil)
 	}
-	_, err := operations.ExecContext(
+	before, err := auditRow(ctx, operations, "users", user.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
 		ctx,
 		updateUserSQL,
 		user.Username,
 		user.Email,
 		user.PasswordHash,
 		user.IsActive,
+		user.Role,
+		user.FailedLoginCount,
+		user.LockedUntil,
+		user.MFASecret,
+		user.MFAEnabled,
+		user.MFALastStep,
+		user.EmailVerified,
+		user.TokensValidAfter,
 		user.ID,
 	)
 	if err != nil {
 		return apperr.NewDatabaseError(err).LogErrorMessage("update user query error: %v", err)
 	}
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "users", user.ID, before)
 }
 
 func (s *userDomain) GetUserByID(
@@ -121,7 +151,10 @@ func (s *userDomain) GetUsersCount(
 	filter *models.Filter,
 ) (int, error) {
 
-	query, args := s.buildQuery(getUsersCountSQL, filter.NoPagination())
+	countFilter := filter.NoPagination()
+	countFilter.CountQuery = true
+
+	query, args := s.buildQuery(getUsersCountSQL, countFilter)
 	rows := operations.QueryRowContext(
 		ctx,
 		query,
@@ -141,7 +174,7 @@ func (s *userDomain) GetUsers(
 	operations db.SQLOperations,
 	filter *models.Filter,
 ) ([]*models.User, error) {
-	query, args := s.buildQuery(getUsersSQL, filter.NoPagination())
+	query, args := s.buildQuery(getUsersSQL, filter)
 	rows, err := operations.QueryContext(
 		ctx,
 		query,
@@ -170,7 +203,12 @@ func (s *userDomain) DeleteUser(
 	operations db.SQLOperations,
 	id int64,
 ) error {
-	_, err := operations.ExecContext(
+	before, err := auditRow(ctx, operations, "users", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
 		ctx,
 		deleteUserSQL,
 		id,
@@ -178,7 +216,105 @@ func (s *userDomain) DeleteUser(
 	if err != nil {
 		return apperr.NewDatabaseError(err).LogErrorMessage("delete user query error: %v", err)
 	}
-	return nil
+	return auditChange(ctx, operations, custom_types.AuditActionDelete, "users", id, before)
+}
+
+// IncrementFailedLogins counts a failed login in a single statement, so concurrent failures
+// cannot overwrite each other, and locks the account once the count reaches lockAt. The lockout
+// doubles from lockout for every further failure, capped at maxLockout. The user's count and
+// lock are set from the row written.
+func (s *userDomain) IncrementFailedLogins(
+	ctx context.Context,
+	operations db.SQLOperations,
+	user *models.User,
+	lockAt int,
+	lockout,
+	maxLockout time.Duration,
+) error {
+	before, err := auditRow(ctx, operations, "users", user.ID)
+	if err != nil {
+		return err
+	}
+
+	err = operations.QueryRowContext(
+		ctx,
+		incrementFailedLoginsSQL,
+		user.ID,
+		lockAt,
+		time.Now(),
+		lockout.Seconds(),
+		maxLockout.Seconds(),
+	).Scan(&user.FailedLoginCount, &user.LockedUntil)
+	if err != nil {
+		return apperr.NewDatabaseError(err).LogErrorMessage("increment failed logins query error: %v", err)
+	}
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "users", user.ID, before)
+}
+
+// ResetFailedLogins clears the failed login count and lock without writing the rest of the row.
+func (s *userDomain) ResetFailedLogins(
+	ctx context.Context,
+	operations db.SQLOperations,
+	user *models.User,
+) error {
+	before, err := auditRow(ctx, operations, "users", user.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		resetFailedLoginsSQL,
+		user.ID,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(err).LogErrorMessage("reset failed logins query error: %v", err)
+	}
+
+	user.FailedLoginCount = 0
+	user.LockedUntil = nil
+	return auditChange(ctx, operations, custom_types.AuditActionUpdate, "users", user.ID, before)
+}
+
+// UseMFAStep records the TOTP time step as used unless the user already used it or a later one.
+// It reports false when the step was not newer, so a code is accepted once even under
+// concurrent requests.
+func (s *userDomain) UseMFAStep(
+	ctx context.Context,
+	operations db.SQLOperations,
+	user *models.User,
+	step int64,
+) (bool, error) {
+	before, err := auditRow(ctx, operations, "users", user.ID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := operations.ExecContext(
+		ctx,
+		useMFAStepSQL,
+		user.ID,
+		step,
+	)
+	if err != nil {
+		return false, apperr.NewDatabaseError(err).LogErrorMessage("use mfa step query error: %v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperr.NewDatabaseError(err).LogErrorMessage("use mfa step rows affected error: %v", err)
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	user.MFALastStep = step
+	err = auditChange(ctx, operations, custom_types.AuditActionUpdate, "users", user.ID, before)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func (s *userDomain) scanRow(
@@ -191,6 +327,14 @@ func (s *userDomain) scanRow(
 		&user.Email,
 		&user.PasswordHash,
 		&user.IsActive,
+		&user.Role,
+		&user.FailedLoginCount,
+		&user.LockedUntil,
+		&user.MFASecret,
+		&user.MFAEnabled,
+		&user.MFALastStep,
+		&user.EmailVerified,
+		&user.TokensValidAfter,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -208,6 +352,16 @@ func (s *userDomain) buildQuery(
 	conditions := make([]string, 0)
 	counter := utils.NewPlaceholder()
 
+	if filter.Active.Valid {
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", counter.Touch()))
+		args = append(args, filter.Active.Bool)
+	}
+
+	if filter.Role != "" {
+		conditions = append(conditions, fmt.Sprintf("role = $%d", counter.Touch()))
+		args = append(args, filter.Role)
+	}
+
 	if filter.Term != "" {
 		textCols := []string{"username", "email"}
 		likeStatements := make([]string, 0)
@@ -222,6 +376,10 @@ func (s *userDomain) buildQuery(
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
+	if filter.CountQuery {
+		return query, args
+	}
+	query += " ORDER BY id"
 	if filter.Page > 0 && filter.Per > 0 {
 		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
 		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+)
+
+const (
+	createUserTokenSQL          = "INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	getUserTokenByHashSQL       = "SELECT id, user_id, purpose, token_hash, expires_at, used_at, created_at FROM user_tokens WHERE purpose = $1 AND token_hash = $2"
+	useUserTokenSQL             = "UPDATE user_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL"
+	deleteUserTokensByUserIDSQL = "DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2"
+	auditUserTokensSQL          = "t.user_id = $1 AND t.purpose = $2"
+)
+
+type (
+	UserTokenDomain interface {
+		CreateUserToken(ctx context.Context, operations db.SQLOperations, token *models.UserToken) error
+		GetUserTokenByHash(ctx context.Context, operations db.SQLOperations, purpose custom_types.TokenPurpose, tokenHash string) (*models.UserToken, error)
+		UseUserToken(ctx context.Context, operations db.SQLOperations, id int64) (bool, error)
+		DeleteUserTokensByUserID(ctx context.Context, operations db.SQLOperations, userID int64, purpose custom_types.TokenPurpose) error
+	}
+
+	userTokenDomain struct{}
+)
+
+func NewUserTokenDomain() UserTokenDomain {
+	return &userTokenDomain{}
+}
+
+func (s *userTokenDomain) CreateUserToken(
+	ctx context.Context,
+	operations db.SQLOperations,
+	token *models.UserToken,
+) error {
+
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+
+	err := operations.QueryRowContext(
+		ctx,
+		createUserTokenSQL,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("create user token query error: %v", err)
+	}
+
+	return auditChange(ctx, operations, custom_types.AuditActionCreate, "user_tokens", token.ID, nil)
+}
+
+func (s *userTokenDomain) GetUserTokenByHash(
+	ctx context.Context,
+	operations db.SQLOperations,
+	purpose custom_types.TokenPurpose,
+	tokenHash string,
+) (*models.UserToken, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getUserTokenByHashSQL,
+		purpose,
+		tokenHash,
+	)
+
+	var token models.UserToken
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("scan user token row error: %v", err)
+	}
+
+	return &token, nil
+}
+
+// UseUserToken marks the token as used and reports whether it was still unused,
+// so two concurrent requests cannot both redeem it.
+func (s *userTokenDomain) UseUserToken(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) (bool, error) {
+
+	before, err := auditRow(ctx, operations, "user_tokens", id)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := operations.ExecContext(
+		ctx,
+		useUserTokenSQL,
+		time.Now(),
+		id,
+	)
+	if err != nil {
+		return false, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("use user token query error: %v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("use user token rows affected error: %v", err)
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	err = auditChange(ctx, operations, custom_types.AuditActionUpdate, "user_tokens", id, before)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *userTokenDomain) DeleteUserTokensByUserID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	userID int64,
+	purpose custom_types.TokenPurpose,
+) error {
+
+	before, err := auditRows(ctx, operations, "user_tokens", auditUserTokensSQL, userID, purpose)
+	if err != nil {
+		return err
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		deleteUserTokensByUserIDSQL,
+		userID,
+		purpose,
+	)
+	if err != nil {
+		return apperr.NewDatabaseError(
+			err,
+		).LogErrorMessage("delete user tokens query error: %v", err)
+	}
+
+	entityID := fmt.Sprintf("user_id=%d", userID)
+	return auditRowsChange(ctx, operations, custom_types.AuditActionDelete, "user_tokens", entityID, before, auditUserTokensSQL, userID, purpose)
+}
@@ -1,17 +1,89 @@
 package dtos
 
+import (
+	"io"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
 type ClaimSubmissionForm struct {
-	MemberID        int64  `json:"member_id"         binding:"required"`
-	ProviderID      int64  `json:"provider_id"       binding:"required"`
-	ProcedureCode   string  `json:"procedure_code"    binding:"required"`
-	DiagnosisCode   string  `json:"diagnosis_code"    binding:"required"`
-	RequestedAmount float64 `json:"requested_amount"  binding:"required,gt=0"`
+	MemberID         int64       `json:"member_id"` // member_id or membership_number is required
+	MembershipNumber string      `json:"membership_number"`
+	ProviderID       int64       `json:"provider_id"       binding:"required"`
+	ProcedureCode    string      `json:"procedure_code"    binding:"required"`
+	DiagnosisCode    string      `json:"diagnosis_code"    binding:"required"`
+	RequestedAmount  money.Money `json:"requested_amount"  binding:"required,gt=0"`
+	Currency         string      `json:"currency"`                             // of requested_amount, defaults to the member's benefit currency
+	ServiceDate      string      `json:"service_date"      binding:"required"` // YYYY-MM-DD
+	AdmissionDate    string      `json:"admission_date"`                       // YYYY-MM-DD, inpatient claims only
+	DischargeDate    string      `json:"discharge_date"`                       // YYYY-MM-DD, inpatient claims only
+	DryRun           bool        `json:"dry_run"`                              // run the pipeline and roll back
+
+	// set for claims filed in an 837
+	X12SenderID          string `json:"-"`
+	PatientControlNumber string `json:"-"`
 }
 
 type ClaimSubmissionResponse struct {
-	ClaimID         int64   `json:"claim_id"`
-	Status          string  `json:"status"`
-	FraudFlag       bool    `json:"fraud_flag"`
-	ApprovedAmount  float64 `json:"approved_amount"`
-	RejectionReason string  `json:"rejection_reason,omitempty"`
-}
\ No newline at end of file
+	ClaimID         int64       `json:"claim_id"`
+	MemberID        int64       `json:"member_id"`
+	Status          string      `json:"status"`
+	FraudFlag       bool        `json:"fraud_flag"`
+	ApprovedAmount  money.Money `json:"approved_amount"` // in BenefitCurrency
+	BenefitCurrency string      `json:"benefit_currency"`
+	ExchangeRate    money.Rate  `json:"exchange_rate"`
+	RejectionReason string      `json:"rejection_reason,omitempty"`
+	DryRun          bool        `json:"dry_run,omitempty"`
+}
+
+// ClaimReplayDifference is a historical claim whose decision changes under the current rules.
+type ClaimReplayDifference struct {
+	ClaimID                int64       `json:"claim_id"`
+	OriginalStatus         string      `json:"original_status"`
+	ReplayedStatus         string      `json:"replayed_status"`
+	OriginalApprovedAmount money.Money `json:"original_approved_amount"`
+	ReplayedApprovedAmount money.Money `json:"replayed_approved_amount"`
+	OriginalFraudFlag      bool        `json:"original_fraud_flag"`
+	ReplayedFraudFlag      bool        `json:"replayed_fraud_flag"`
+	ReplayedReason         string      `json:"replayed_rejection_reason,omitempty"`
+}
+
+// ClaimReplayReport summarises a re-adjudication of the claims submitted in a date range. Approved
+// totals are per benefit currency.
+type ClaimReplayReport struct {
+	From                   string                   `json:"from"`
+	To                     string                   `json:"to"`
+	Replayed               int                      `json:"replayed"`
+	Unchanged              int                      `json:"unchanged"`
+	OriginalApprovedAmount money.Totals             `json:"original_approved_amount"`
+	ReplayedApprovedAmount money.Totals             `json:"replayed_approved_amount"`
+	Differences            []*ClaimReplayDifference `json:"differences"`
+}
+
+// ClaimAttachmentUpload is a file uploaded to POST /claims/:id/attachments.
+type ClaimAttachmentUpload struct {
+	DocumentType string
+	FileName     string
+	File         io.Reader
+	UploadedBy   string
+}
+
+// X12ClaimResult is the outcome of one CLM loop from an 837.
+type X12ClaimResult struct {
+	PatientControlNumber string      `json:"patient_control_number"`
+	Type                 string      `json:"type"`
+	ClaimID              int64       `json:"claim_id,omitempty"`
+	Status               string      `json:"status"`
+	ApprovedAmount       money.Money `json:"approved_amount"`
+	FraudFlag            bool        `json:"fraud_flag"`
+	RejectionReason      string      `json:"rejection_reason,omitempty"`
+	Duplicate            bool        `json:"duplicate,omitempty"` // filed before; the saved decision is returned
+	Error                string      `json:"error,omitempty"`     // the claim could not be adjudicated
+}
+
+// X12Result is the response to an 837 upload: each claim's outcome and the 835 remittance advice.
+type X12Result struct {
+	DryRun     bool              `json:"dry_run"`
+	Claims     []*X12ClaimResult `json:"claims"`
+	Remittance string            `json:"remittance_835"`
+}
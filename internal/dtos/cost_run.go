@@ -0,0 +1,8 @@
+package dtos
+
+// CostRunRequest configures a recomputation of procedure costs. Zero values use the service defaults.
+type CostRunRequest struct {
+	Method     string `json:"method"`      // median or trimmed_mean
+	WindowDays int    `json:"window_days"` // rolling window of claims to sample
+	MinSamples int    `json:"min_samples"` // procedures with fewer claims are left unchanged
+}
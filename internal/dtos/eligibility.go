@@ -0,0 +1,33 @@
+package dtos
+
+import "github.com/Doris-Mwito5/ginja-ai/internal/money"
+
+// EligibilityRequest is read from the query string of GET /members/:id/eligibility.
+type EligibilityRequest struct {
+	ProcedureCode string
+	Amount        money.Money // 0 uses the expected price for the procedure
+	Currency      string      // of Amount, defaults to the member's benefit currency
+	ProviderID    int64       // optional; adds the provider checks and tariff
+	ServiceDate   string      // YYYY-MM-DD, defaults to today
+}
+
+// EligibilityResponse is a projection of the claims pipeline. Nothing is written. Amounts are in
+// the benefit currency; a requested amount in another currency is converted at ExchangeRate.
+type EligibilityResponse struct {
+	MemberID                int64       `json:"member_id"`
+	Active                  bool        `json:"active"`
+	BenefitCurrency         string      `json:"benefit_currency"`
+	BenefitLimit            money.Money `json:"benefit_limit"`
+	UsedAmount              money.Money `json:"used_amount"`
+	RemainingBenefit        money.Money `json:"remaining_benefit"`
+	ProcedureCode           string      `json:"procedure_code,omitempty"`
+	ExpectedPrice           money.Money `json:"expected_price,omitzero"`
+	Amount                  money.Money `json:"amount,omitzero"`
+	ExchangeRate            money.Rate  `json:"exchange_rate,omitzero"`
+	Eligible                bool        `json:"eligible"`
+	ProjectedStatus         string      `json:"projected_status,omitempty"`
+	ProjectedApprovedAmount money.Money `json:"projected_approved_amount"`
+	FraudFlag               bool        `json:"fraud_flag"`
+	BlockingReasons         []string    `json:"blocking_reasons"`
+	Notes                   []string    `json:"notes"`
+}
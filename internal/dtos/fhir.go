@@ -0,0 +1,171 @@
+package dtos
+
+import "github.com/Doris-Mwito5/ginja-ai/internal/money"
+
+// FHIR R4 resources exchanged on the /fhir endpoints. Only the elements the claims pipeline reads
+// or writes are modelled; anything else in an inbound resource is ignored.
+
+type FHIRReference struct {
+	Reference  string          `json:"reference,omitempty"` // e.g. "Patient/12"
+	Identifier *FHIRIdentifier `json:"identifier,omitempty"`
+	Display    string          `json:"display,omitempty"`
+}
+
+type FHIRIdentifier struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+type FHIRCoding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type FHIRCodeableConcept struct {
+	Coding []FHIRCoding `json:"coding,omitempty"`
+	Text   string       `json:"text,omitempty"`
+}
+
+type FHIRMoney struct {
+	Value    money.Money `json:"value"`
+	Currency string      `json:"currency,omitempty"`
+}
+
+type FHIRPeriod struct {
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+// FHIRClaim is an inbound Claim. use "preauthorization" or "predetermination" runs the pipeline
+// without storing the claim.
+type FHIRClaim struct {
+	ResourceType   string               `json:"resourceType"`
+	ID             string               `json:"id,omitempty"`
+	Status         string               `json:"status,omitempty"`
+	Type           *FHIRCodeableConcept `json:"type,omitempty"`
+	Use            string               `json:"use,omitempty"`
+	Patient        *FHIRReference       `json:"patient,omitempty"`
+	BillablePeriod *FHIRPeriod          `json:"billablePeriod,omitempty"`
+	Created        string               `json:"created,omitempty"`
+	Provider       *FHIRReference       `json:"provider,omitempty"`
+	Diagnosis      []FHIRClaimDiagnosis `json:"diagnosis,omitempty"`
+	Item           []FHIRClaimItem      `json:"item,omitempty"`
+	Total          *FHIRMoney           `json:"total,omitempty"`
+}
+
+type FHIRClaimDiagnosis struct {
+	Sequence                 int                  `json:"sequence"`
+	DiagnosisCodeableConcept *FHIRCodeableConcept `json:"diagnosisCodeableConcept,omitempty"`
+}
+
+type FHIRClaimItem struct {
+	Sequence         int                  `json:"sequence"`
+	ProductOrService *FHIRCodeableConcept `json:"productOrService,omitempty"`
+	ServicedDate     string               `json:"servicedDate,omitempty"`
+	ServicedPeriod   *FHIRPeriod          `json:"servicedPeriod,omitempty"`
+	UnitPrice        *FHIRMoney           `json:"unitPrice,omitempty"`
+	Net              *FHIRMoney           `json:"net,omitempty"`
+}
+
+type FHIRClaimResponse struct {
+	ResourceType string                  `json:"resourceType"`
+	ID           string                  `json:"id,omitempty"`
+	Identifier   []FHIRIdentifier        `json:"identifier,omitempty"`
+	Status       string                  `json:"status"`
+	Type         *FHIRCodeableConcept    `json:"type,omitempty"`
+	Use          string                  `json:"use"`
+	Patient      *FHIRReference          `json:"patient,omitempty"`
+	Created      string                  `json:"created"`
+	Insurer      *FHIRReference          `json:"insurer,omitempty"`
+	Request      *FHIRReference          `json:"request,omitempty"`
+	Outcome      string                  `json:"outcome"`
+	Disposition  string                  `json:"disposition,omitempty"`
+	Item         []FHIRClaimResponseItem `json:"item,omitempty"`
+	Total        []FHIRAdjudication      `json:"total,omitempty"`
+	ProcessNote  []FHIRClaimResponseNote `json:"processNote,omitempty"`
+}
+
+type FHIRClaimResponseItem struct {
+	ItemSequence int                `json:"itemSequence"`
+	Adjudication []FHIRAdjudication `json:"adjudication"`
+}
+
+// FHIRAdjudication is used for both item adjudications and ClaimResponse totals.
+type FHIRAdjudication struct {
+	Category FHIRCodeableConcept  `json:"category"`
+	Reason   *FHIRCodeableConcept `json:"reason,omitempty"`
+	Amount   *FHIRMoney           `json:"amount,omitempty"`
+}
+
+type FHIRClaimResponseNote struct {
+	Type string `json:"type,omitempty"`
+	Text string `json:"text"`
+}
+
+type FHIRCoverageEligibilityRequest struct {
+	ResourceType string                        `json:"resourceType"`
+	ID           string                        `json:"id,omitempty"`
+	Status       string                        `json:"status,omitempty"`
+	Purpose      []string                      `json:"purpose,omitempty"`
+	Patient      *FHIRReference                `json:"patient,omitempty"`
+	ServicedDate string                        `json:"servicedDate,omitempty"`
+	Created      string                        `json:"created,omitempty"`
+	Provider     *FHIRReference                `json:"provider,omitempty"`
+	Item         []FHIRCoverageEligibilityItem `json:"item,omitempty"`
+}
+
+type FHIRCoverageEligibilityItem struct {
+	ProductOrService *FHIRCodeableConcept `json:"productOrService,omitempty"`
+	UnitPrice        *FHIRMoney           `json:"unitPrice,omitempty"`
+}
+
+type FHIRCoverageEligibilityResponse struct {
+	ResourceType string                     `json:"resourceType"`
+	Status       string                     `json:"status"`
+	Purpose      []string                   `json:"purpose"`
+	Patient      *FHIRReference             `json:"patient,omitempty"`
+	ServicedDate string                     `json:"servicedDate,omitempty"`
+	Created      string                     `json:"created"`
+	Request      *FHIRReference             `json:"request,omitempty"`
+	Outcome      string                     `json:"outcome"`
+	Disposition  string                     `json:"disposition,omitempty"`
+	Insurer      *FHIRReference             `json:"insurer,omitempty"`
+	Insurance    []FHIREligibilityInsurance `json:"insurance,omitempty"`
+	Error        []FHIREligibilityError     `json:"error,omitempty"`
+}
+
+type FHIREligibilityInsurance struct {
+	Coverage FHIRReference             `json:"coverage"`
+	Inforce  bool                      `json:"inforce"`
+	Item     []FHIREligibilityBenefits `json:"item,omitempty"`
+}
+
+type FHIREligibilityBenefits struct {
+	ProductOrService *FHIRCodeableConcept     `json:"productOrService,omitempty"`
+	Excluded         bool                     `json:"excluded"`
+	Description      string                   `json:"description,omitempty"`
+	Benefit          []FHIREligibilityBenefit `json:"benefit,omitempty"`
+}
+
+type FHIREligibilityBenefit struct {
+	Type         FHIRCodeableConcept `json:"type"`
+	AllowedMoney *FHIRMoney          `json:"allowedMoney,omitempty"`
+	UsedMoney    *FHIRMoney          `json:"usedMoney,omitempty"`
+}
+
+type FHIREligibilityError struct {
+	Code FHIRCodeableConcept `json:"code"`
+}
+
+// FHIROperationOutcome reports a failed FHIR interaction.
+type FHIROperationOutcome struct {
+	ResourceType string                      `json:"resourceType"`
+	Issue        []FHIROperationOutcomeIssue `json:"issue"`
+}
+
+type FHIROperationOutcomeIssue struct {
+	Severity    string `json:"severity"` // fatal | error | warning | information
+	Code        string `json:"code"`     // FHIR issue type, e.g. invalid, not-found
+	Diagnostics string `json:"diagnostics,omitempty"`
+}
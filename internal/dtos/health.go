@@ -0,0 +1,41 @@
+package dtos
+
+import "time"
+
+// HealthCheck is the result of one readiness check.
+type HealthCheck struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// Readiness is returned by GET /readyz: the server is ready when every check is healthy.
+type Readiness struct {
+	Ready  bool           `json:"ready"`
+	Checks []*HealthCheck `json:"checks"`
+}
+
+// DatabasePoolStatus reports one of the two connection pools, the sql.DB ("sql") or the pgx
+// pool ("pgx").
+type DatabasePoolStatus struct {
+	Pool            string `json:"pool"`
+	OpenConnections int    `json:"open_connections"`
+	InUse           int    `json:"in_use"`
+	Idle            int    `json:"idle"`
+	MaxConnections  int    `json:"max_connections"`
+	WaitCount       int64  `json:"wait_count"`
+	WaitDuration    string `json:"wait_duration"`
+}
+
+// ServiceStatus is returned by GET /v1/status.
+type ServiceStatus struct {
+	Version           string                `json:"version"`
+	GoVersion         string                `json:"go_version"`
+	StartedAt         time.Time             `json:"started_at"`
+	Uptime            string                `json:"uptime"`
+	UptimeSeconds     int64                 `json:"uptime_seconds"`
+	Ready             bool                  `json:"ready"`
+	Checks            []*HealthCheck        `json:"checks"`
+	PendingMigrations []int64               `json:"pending_migrations"`
+	DatabasePools     []*DatabasePoolStatus `json:"database_pools"`
+}
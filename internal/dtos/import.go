@@ -0,0 +1,24 @@
+package dtos
+
+// ImportOptions controls how a CSV upload is applied. With AllOrNothing a single invalid row
+// rejects the whole file; otherwise valid rows are saved and invalid rows reported. DryRun
+// validates every row and reports the outcome without saving anything.
+type ImportOptions struct {
+	DryRun       bool
+	AllOrNothing bool
+}
+
+// ImportRowError reports why a single CSV row was rejected. Row is the line number in the file.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportResult is the response for CSV upload endpoints.
+type ImportResult struct {
+	DryRun   bool             `json:"dry_run"`
+	Imported int              `json:"imported"`
+	Created  int              `json:"created"`
+	Updated  int              `json:"updated"`
+	Errors   []ImportRowError `json:"errors"`
+}
@@ -1,8 +1,34 @@
 package dtos
 
+import "github.com/Doris-Mwito5/ginja-ai/internal/money"
+
 type Member struct {
-	FullName     string  `json:"full_name"`
-	IsActive     bool    `json:"is_active"`
-	BenefitLimit float64 `json:"benefit_limit"`
-	UsedAmount   float64 `json:"used_amount"`
-}
\ No newline at end of file
+	FullName         string      `json:"full_name"`
+	MembershipNumber string      `json:"membership_number"`
+	NationalID       string      `json:"national_id"`
+	CardNumber       string      `json:"card_number"`
+	IsActive         bool        `json:"is_active"`
+	BenefitLimit     money.Money `json:"benefit_limit"`
+	UsedAmount       money.Money `json:"used_amount"`
+	BenefitCurrency  string      `json:"benefit_currency"` // defaults to KES
+}
+
+// UpdateMemberRequest is the inbound payload for PATCH /members/:id. Omitted fields are left unchanged.
+type UpdateMemberRequest struct {
+	FullName         *string      `json:"full_name"`
+	MembershipNumber *string      `json:"membership_number"`
+	NationalID       *string      `json:"national_id"`
+	CardNumber       *string      `json:"card_number"`
+	IsActive         *bool        `json:"is_active"`
+	BenefitLimit     *money.Money `json:"benefit_limit"`
+	UsedAmount       *money.Money `json:"used_amount"`
+	BenefitCurrency  *string      `json:"benefit_currency"` // only while nothing of the benefit is used
+}
+
+// MemberLookupRequest is read from the query string of GET /members/lookup. Exactly one
+// identifier is given.
+type MemberLookupRequest struct {
+	MembershipNumber string
+	NationalID       string
+	CardNumber       string
+}
@@ -0,0 +1,30 @@
+package dtos
+
+// MFAEnrollmentResponse is the response for POST /mfa/enroll
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFACodeRequest is the inbound payload for POST /mfa/confirm and POST /mfa/disable
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse returns the plaintext recovery codes; they are only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallengeResponse is returned by POST /login for enrolled users instead of an access token
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+// MFALoginRequest is the inbound payload for POST /login/mfa. Code is a TOTP code or a recovery code.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code"      binding:"required"`
+}
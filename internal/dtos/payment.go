@@ -0,0 +1,13 @@
+package dtos
+
+// PaymentBatchRequest drafts payment batches from approved, unpaid claims. Without a provider a
+// batch is drafted for every provider with payable claims.
+type PaymentBatchRequest struct {
+	ProviderID  int64  `json:"provider_id"`
+	SubmittedTo string `json:"submitted_to"` // YYYY-MM-DD; only claims submitted on or before are included, defaults to today
+}
+
+// PaymentBatchPaymentForm records the payment made for an approved batch.
+type PaymentBatchPaymentForm struct {
+	PaymentReference string `json:"payment_reference" binding:"required"`
+}
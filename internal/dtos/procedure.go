@@ -1,7 +1,23 @@
 package dtos
 
+import "github.com/Doris-Mwito5/ginja-ai/internal/money"
+
 type Procedure struct {
-	Code        string  `json:"code"`
-	Description string  `json:"description"`
-	AverageCost float64 `json:"average_cost"`
-}
\ No newline at end of file
+	Code          string      `json:"code"`
+	Description   string      `json:"description"`
+	AverageCost   money.Money `json:"average_cost"`
+	Currency      string      `json:"currency"`       // defaults to KES, fixed once created
+	EffectiveFrom string      `json:"effective_from"` // YYYY-MM-DD, defaults to today
+}
+
+// ProcedurePrice is the inbound payload for POST /procedures/:code/prices.
+type ProcedurePrice struct {
+	AverageCost   money.Money `json:"average_cost"   binding:"required,gt=0"`
+	EffectiveFrom string      `json:"effective_from" binding:"required"` // YYYY-MM-DD
+}
+
+// DocumentRequirements is the inbound payload for PUT /procedures/:code/required-documents. It
+// replaces the procedure's required document types; an empty list removes them all.
+type DocumentRequirements struct {
+	DocumentTypes []string `json:"document_types"`
+}
@@ -1,6 +1,20 @@
 package dtos
 
 type Provider struct {
-	Name     string `json:"name"`
-	Location string `json:"location"`
-}
\ No newline at end of file
+	Name                string `json:"name"                 binding:"required"`
+	Location            string `json:"location"`
+	LicenceNumber       string `json:"licence_number"`
+	AccreditationExpiry string `json:"accreditation_expiry"` // YYYY-MM-DD
+	NetworkTier         string `json:"network_tier"`
+	Status              string `json:"status"`
+}
+
+// UpdateProviderRequest is the inbound payload for PATCH /providers/:id. Omitted fields are left unchanged.
+type UpdateProviderRequest struct {
+	Name                *string `json:"name"`
+	Location            *string `json:"location"`
+	LicenceNumber       *string `json:"licence_number"`
+	AccreditationExpiry *string `json:"accreditation_expiry"` // YYYY-MM-DD, empty string clears it
+	NetworkTier         *string `json:"network_tier"`
+	Status              *string `json:"status"`
+}
@@ -0,0 +1,20 @@
+package dtos
+
+import "github.com/Doris-Mwito5/ginja-ai/internal/money"
+
+type ProviderTariff struct {
+	ProviderID    int64       `json:"provider_id"    binding:"required"`
+	ProcedureCode string      `json:"procedure_code" binding:"required"`
+	AgreedPrice   money.Money `json:"agreed_price"   binding:"required,gt=0"`
+	Currency      string      `json:"currency"`                          // defaults to the procedure's currency
+	EffectiveFrom string      `json:"effective_from" binding:"required"` // YYYY-MM-DD
+	EffectiveTo   string      `json:"effective_to"`                      // YYYY-MM-DD, empty for open ended
+}
+
+// UpdateProviderTariffRequest is the inbound payload for PATCH /tariffs/:id. Omitted fields are left unchanged.
+type UpdateProviderTariffRequest struct {
+	AgreedPrice   *money.Money `json:"agreed_price"`
+	Currency      *string      `json:"currency"`
+	EffectiveFrom *string      `json:"effective_from"`
+	EffectiveTo   *string      `json:"effective_to"` // empty string makes the tariff open ended
+}
@@ -19,3 +19,31 @@ type LoginResponse struct {
 	User        interface{} `json:"user"`
 	ExpiresAt   string      `json:"expires_at"`
 }
+
+// ChangePasswordRequest is the inbound payload for POST /password/change
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password"     binding:"required,min=8"`
+}
+
+// ForgotPasswordRequest is the inbound payload for POST /password/forgot
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest is the inbound payload for POST /password/reset
+type ResetPasswordRequest struct {
+	Token       string `json:"token"        binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+// VerifyEmailRequest is the inbound payload for POST /verify-email
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// UpdateUserRequest is the inbound payload for PATCH /users/:id. Omitted fields are left unchanged.
+type UpdateUserRequest struct {
+	Email *string `json:"email" binding:"omitempty,email"`
+	Role  *string `json:"role"`
+}
@@ -0,0 +1,41 @@
+package health
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Version is the release the binary was built from, set at build time with
+// -ldflags "-X github.com/Doris-Mwito5/ginja-ai/internal/health.Version=<version>".
+var Version = "dev"
+
+// State is the lifecycle of the running server: when it started and whether it has begun
+// shutting down. It is shared by the signal handler and the readiness probe.
+type State struct {
+	startedAt    time.Time
+	shuttingDown atomic.Bool
+}
+
+func NewState() *State {
+	return &State{
+		startedAt: time.Now(),
+	}
+}
+
+// StartShutdown makes readiness fail from now on, so load balancers stop sending traffic
+// while in-flight requests finish.
+func (s *State) StartShutdown() {
+	s.shuttingDown.Store(true)
+}
+
+func (s *State) ShuttingDown() bool {
+	return s.shuttingDown.Load()
+}
+
+func (s *State) StartedAt() time.Time {
+	return s.startedAt
+}
+
+func (s *State) Uptime() time.Duration {
+	return time.Since(s.startedAt)
+}
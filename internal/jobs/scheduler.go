@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/audit"
+	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+)
+
+// Every runs job on a fixed interval until ctx is cancelled. Failures are logged and the job
+// runs again on the next tick. Changes the job makes are audited as "job:<name>".
+func Every(
+	ctx context.Context,
+	interval time.Duration,
+	name string,
+	job func(ctx context.Context) error,
+) {
+	ctx = audit.WithActor(ctx, audit.Actor{Username: "job:" + name})
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger.Infof("scheduled job [%v] every %v", name, interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				logger.Errorf("scheduled job [%v] failed: %v", name, err)
+			}
+		}
+	}
+}
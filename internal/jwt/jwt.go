@@ -11,7 +11,8 @@ import (
 const minSecretKeySize = 32
 
 type JWTToken interface {
-	CreateToken(username string, duration time.Duration) (string, error)
+	CreateToken(username string, role string, duration time.Duration) (string, error)
+	CreateScopedToken(username string, role string, scope string, duration time.Duration) (string, error)
 	VerifyToken(token string) (*Payload, error)
 }
 
@@ -27,8 +28,13 @@ func NewJWTMaker(secretkey string) (JWTToken, error) {
 	return &jwtToken{secretkey: secretkey}, nil
 }
 
-func (maker *jwtToken) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+// CreateToken issues a full access token.
+func (maker *jwtToken) CreateToken(username string, role string, duration time.Duration) (string, error) {
+	return maker.CreateScopedToken(username, role, ScopeAccess, duration)
+}
+
+func (maker *jwtToken) CreateScopedToken(username string, role string, scope string, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, role, scope, duration)
 	if err != nil {
 		return "", err
 	}
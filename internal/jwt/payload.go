@@ -12,19 +12,38 @@ var (
 	errInvalidToken = errors.New("token is invalid")
 )
 
+// token scopes
+const (
+	ScopeAccess  = "access"  // full API access
+	ScopeLimited = "limited" // logged in with an unverified email, own account endpoints only
+	ScopeMFA     = "mfa"     // password verified, waiting for the second factor
+)
+
+// LoginScope returns the scope of the token issued when a login completes.
+func LoginScope(emailVerified bool) string {
+	if emailVerified {
+		return ScopeAccess
+	}
+	return ScopeLimited
+}
+
 
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Scope     string    `json:"scope"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, scope string, duration time.Duration) (*Payload, error) {
 	tokenID := uuid.NewRandom()
 	payload := &Payload{
 		ID: tokenID,
 		Username: username,
+		Role: role,
+		Scope: scope,
 		IssuedAt: time.Now(),
 		ExpiresAt: time.Now().Add(duration),
 	}
@@ -0,0 +1,36 @@
+package logger
+
+import "context"
+
+type (
+	requestIDKey struct{}
+	loggerKey    struct{}
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID and a logger that adds it to every
+// line as request_id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return context.WithValue(ctx, loggerKey{}, base().With("request_id", requestID))
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext returns the logger carried by ctx, or the global logger when there is none.
+func FromContext(ctx context.Context) Logger {
+	if ctxLogger, ok := ctx.Value(loggerKey{}).(*AppLogger); ok {
+		return ctxLogger
+	}
+	return base()
+}
+
+func base() *AppLogger {
+	if logger != nil {
+		return logger
+	}
+	return NewAppLogger("default")
+}
@@ -47,6 +47,11 @@ func NewAppLogger(service string) *AppLogger {
 	return &AppLogger{logger: l}
 }
 
+// With returns a copy of the logger that adds the field to every line.
+func (l *AppLogger) With(key, value string) *AppLogger {
+	return &AppLogger{logger: l.logger.With().Str(key, value).Logger()}
+}
+
 func (l *AppLogger) Error(msg string) {
 	l.logger.Error().Msg(msg)
 }
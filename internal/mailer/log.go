@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+)
+
+// logMailer is for local use: it logs every message and, when a file is set, appends it there.
+type logMailer struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func NewLogMailer(filePath string) Mailer {
+	return &logMailer{
+		filePath: filePath,
+	}
+}
+
+func (m *logMailer) Send(
+	ctx context.Context,
+	message *Message,
+) error {
+
+	logger.FromContext(ctx).Infof("mail to [%v] subject [%v]:\n%v", message.To, message.Subject, message.Body)
+
+	if m.filePath == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open mail log file failed: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(
+		file,
+		"--- %v\nTo: %v\nSubject: %v\n\n%v\n\n",
+		time.Now().UTC().Format(time.RFC3339),
+		message.To,
+		message.Subject,
+		message.Body,
+	)
+	if err != nil {
+		return fmt.Errorf("write mail log file failed: %w", err)
+	}
+
+	return nil
+}
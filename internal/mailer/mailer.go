@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/configs"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverLog  = "log"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email such as password reset and verification links.
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+// NewMailer builds the mailer selected by MAIL_DRIVER, defaulting to the log mailer.
+func NewMailer() Mailer {
+	switch configs.Config.MailDriver {
+	case DriverSMTP:
+		return NewSMTPMailer(
+			configs.Config.SMTPHost,
+			configs.Config.SMTPPort,
+			configs.Config.SMTPUsername,
+			configs.Config.SMTPPassword,
+			configs.Config.MailFrom,
+		)
+	default:
+		return NewLogMailer(configs.Config.MailLogFile)
+	}
+}
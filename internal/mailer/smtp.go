@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type smtpMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(
+	host string,
+	port string,
+	username string,
+	password string,
+	from string,
+) Mailer {
+	return &smtpMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (m *smtpMailer) Send(
+	ctx context.Context,
+	message *Message,
+) error {
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + message.To,
+		"Subject: " + message.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		message.Body,
+	}, "\r\n")
+
+	err := smtp.SendMail(net.JoinHostPort(m.host, m.port), auth, m.from, []string{message.To}, []byte(body))
+	if err != nil {
+		return fmt.Errorf("smtp send to [%v] failed: %w", message.To, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	poolSQL = "sql"
+	poolPgx = "pgx"
+)
+
+var (
+	dbOpenConnections = prometheus.NewDesc(
+		"db_pool_open_connections",
+		"Connections open in the pool, in use or idle.",
+		[]string{"pool"}, nil,
+	)
+	dbInUseConnections = prometheus.NewDesc(
+		"db_pool_in_use_connections",
+		"Connections currently in use.",
+		[]string{"pool"}, nil,
+	)
+	dbIdleConnections = prometheus.NewDesc(
+		"db_pool_idle_connections",
+		"Idle connections.",
+		[]string{"pool"}, nil,
+	)
+	dbMaxConnections = prometheus.NewDesc(
+		"db_pool_max_connections",
+		"Largest number of connections the pool will open; 0 is unlimited.",
+		[]string{"pool"}, nil,
+	)
+	dbWaitCount = prometheus.NewDesc(
+		"db_pool_wait_count_total",
+		"Times a caller had to wait for a connection.",
+		[]string{"pool"}, nil,
+	)
+	dbWaitDuration = prometheus.NewDesc(
+		"db_pool_wait_duration_seconds_total",
+		"Total time callers waited for a connection.",
+		[]string{"pool"}, nil,
+	)
+)
+
+// dbCollector reports the statistics of both connection pools held by db.DB, the sql.DB used
+// for queries (pool="sql") and the pgx pool (pool="pgx"), when Prometheus scrapes.
+type dbCollector struct {
+	dB db.DB
+}
+
+// RegisterDB adds the connection pool statistics of dB to the default registry.
+func RegisterDB(dB db.DB) {
+	prometheus.MustRegister(&dbCollector{dB: dB})
+}
+
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbOpenConnections
+	ch <- dbInUseConnections
+	ch <- dbIdleConnections
+	ch <- dbMaxConnections
+	ch <- dbWaitCount
+	ch <- dbWaitDuration
+}
+
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	sqlStats := c.dB.Stats()
+	ch <- prometheus.MustNewConstMetric(dbOpenConnections, prometheus.GaugeValue, float64(sqlStats.OpenConnections), poolSQL)
+	ch <- prometheus.MustNewConstMetric(dbInUseConnections, prometheus.GaugeValue, float64(sqlStats.InUse), poolSQL)
+	ch <- prometheus.MustNewConstMetric(dbIdleConnections, prometheus.GaugeValue, float64(sqlStats.Idle), poolSQL)
+	ch <- prometheus.MustNewConstMetric(dbMaxConnections, prometheus.GaugeValue, float64(sqlStats.MaxOpenConnections), poolSQL)
+	ch <- prometheus.MustNewConstMetric(dbWaitCount, prometheus.CounterValue, float64(sqlStats.WaitCount), poolSQL)
+	ch <- prometheus.MustNewConstMetric(dbWaitDuration, prometheus.CounterValue, sqlStats.WaitDuration.Seconds(), poolSQL)
+
+	pgxStats := c.dB.PoolStat()
+	ch <- prometheus.MustNewConstMetric(dbOpenConnections, prometheus.GaugeValue, float64(pgxStats.TotalConns()), poolPgx)
+	ch <- prometheus.MustNewConstMetric(dbInUseConnections, prometheus.GaugeValue, float64(pgxStats.AcquiredConns()), poolPgx)
+	ch <- prometheus.MustNewConstMetric(dbIdleConnections, prometheus.GaugeValue, float64(pgxStats.IdleConns()), poolPgx)
+	ch <- prometheus.MustNewConstMetric(dbMaxConnections, prometheus.GaugeValue, float64(pgxStats.MaxConns()), poolPgx)
+	ch <- prometheus.MustNewConstMetric(dbWaitCount, prometheus.CounterValue, float64(pgxStats.EmptyAcquireCount()), poolPgx)
+	ch <- prometheus.MustNewConstMetric(dbWaitDuration, prometheus.CounterValue, pgxStats.EmptyAcquireWaitTime().Seconds(), poolPgx)
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to handle HTTP requests, by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	claimsSubmitted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "claims_submitted_total",
+		Help: "Claims adjudicated and saved, by status and fraud flag.",
+	}, []string{"status", "fraud_flag"})
+
+	claimsBenefitExhausted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "claims_benefit_exhausted_total",
+		Help: "Claims rejected because the member's benefit limit was used up.",
+	})
+
+	claimProcessingDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "claims_processing_duration_seconds",
+		Help:    "Time taken to adjudicate and save a claim submission.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	claimStageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "claims_pipeline_stage_duration_seconds",
+		Help:    "Time spent in each stage of the claims validation pipeline.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"stage"})
+)
+
+// Handler serves the metrics of the default registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records a handled request. route is the route template, such as
+// /v1/claims/:id, so that IDs in paths do not each become a series.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, statusLabel).Inc()
+	httpRequestDuration.WithLabelValues(method, route, statusLabel).Observe(duration.Seconds())
+}
+
+// ObserveClaim records a saved claim decision and how long the submission took.
+func ObserveClaim(status string, fraudFlag bool, benefitExhausted bool, duration time.Duration) {
+	claimsSubmitted.WithLabelValues(status, strconv.FormatBool(fraudFlag)).Inc()
+	if benefitExhausted {
+		claimsBenefitExhausted.Inc()
+	}
+	claimProcessingDuration.Observe(duration.Seconds())
+}
+
+// StageTimer times consecutive stages of the claims pipeline: each call to Done records the
+// time since the previous call (or since the timer was started) under the stage name.
+type StageTimer struct {
+	last time.Time
+}
+
+func NewStageTimer() *StageTimer {
+	return &StageTimer{
+		last: time.Now(),
+	}
+}
+
+func (t *StageTimer) Done(stage string) {
+	now := time.Now()
+	claimStageDuration.WithLabelValues(stage).Observe(now.Sub(t.last).Seconds())
+	t.last = now
+}
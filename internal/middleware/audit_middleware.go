@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/audit"
+	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditMiddleware records the client IP and request ID as the source of any change the request
+// makes. AuthMiddleware adds the user once the token is verified. It must run after
+// RequestIDMiddleware.
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := audit.Actor{
+			IPAddress: c.ClientIP(),
+			RequestID: logger.RequestIDFromContext(c.Request.Context()),
+		}
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), actor))
+
+		c.Next()
+	}
+}
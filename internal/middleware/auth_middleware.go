@@ -2,9 +2,16 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
+	"github.com/Doris-Mwito5/ginja-ai/internal/audit"
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
 	"github.com/Doris-Mwito5/ginja-ai/internal/jwt"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,7 +22,14 @@ const (
 )
 
 // AuthMiddleware validates the JWT Bearer token on every request.
-func AuthMiddleware(jwtMaker jwt.JWTToken) gin.HandlerFunc {
+// Only full access tokens are accepted unless other scopes are listed.
+// The user is loaded on every request, so deactivating a user or revoking their tokens takes
+// effect at once, and the role comes from the user rather than the token.
+func AuthMiddleware(jwtMaker jwt.JWTToken, dB db.DB, userDomain domain.UserDomain, scopes ...string) gin.HandlerFunc {
+	if len(scopes) == 0 {
+		scopes = []string{jwt.ScopeAccess}
+	}
+
 	return func(c *gin.Context) {
 
 		// 1. Get the Authorization header
@@ -49,12 +63,83 @@ func AuthMiddleware(jwtMaker jwt.JWTToken) gin.HandlerFunc {
 			return
 		}
 
-		// 4. Stash the payload on context for downstream handlers
+		// 4. The token scope must be allowed on this route; MFA challenge tokens never are
+		if !slices.Contains(scopes, payload.Scope) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    "INVALID_TOKEN_SCOPE",
+				"message": "token is not valid for this endpoint",
+			})
+			return
+		}
+
+		// 5. The user must still exist, be active and not have had their tokens revoked since
+		// this one was issued
+		user, err := userDomain.GetUserByUsername(c.Request.Context(), dB, payload.Username)
+		if err != nil {
+			if apperr.IsNoRowsErr(err) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"code":    "INVALID_TOKEN",
+					"message": "token is invalid",
+				})
+				return
+			}
+			utils.HandleError(c, err)
+			c.Abort()
+			return
+		}
+
+		if !user.IsActive {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    "ACCOUNT_INACTIVE",
+				"message": "account is deactivated",
+			})
+			return
+		}
+
+		if user.TokenRevoked(payload.IssuedAt) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    "TOKEN_REVOKED",
+				"message": "token has been revoked, log in again",
+			})
+			return
+		}
+
+		// a role changed since the token was issued applies straight away
+		payload.Role = user.Role.String()
+
+		// 6. Stash the payload on context for downstream handlers, and record the user as the
+		// actor of any change the request makes
 		c.Set(authPayloadKey, payload)
+
+		actor := audit.ActorFromContext(c.Request.Context())
+		actor.Username = payload.Username
+		actor.Role = payload.Role
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), actor))
+
 		c.Next()
 	}
 }
 
+// RequireRole only lets through requests whose user has one of the given roles.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...custom_types.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		payload := GetAuthPayload(c)
+		for _, role := range roles {
+			if payload.Role == role.String() {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"code":    "PERMISSION_DENIED",
+			"message": "you do not have permission to perform this action",
+		})
+	}
+}
+
 // GetAuthPayload retrieves the JWT payload from the Gin context.
 // Call this in any handler that needs the logged-in user's info.
 func GetAuthPayload(c *gin.Context) *jwt.Payload {
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so unknown paths share one series.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware counts every request and records its latency by method, route template
+// and status.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
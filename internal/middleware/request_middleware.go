@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/pborman/uuid"
+)
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 100
+)
+
+// RequestIDMiddleware takes the request ID from the X-Request-ID header, or generates one when it
+// is missing or not usable, and returns it in the same response header. The ID is carried in the
+// request context, where the logger, the audit log, error responses and trace spans pick it up.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewRandom().String()
+		}
+
+		c.Header(requestIDHeader, requestID)
+		ctx := logger.WithRequestID(c.Request.Context(), requestID)
+		ctx = tracing.SetAttributes(ctx, tracing.RequestID(requestID))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// RequestLogger logs every request once it has been handled, with its request ID.
+// It must run after RequestIDMiddleware.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		logger.FromContext(c.Request.Context()).Infof(
+			"%v %v %d %v from IP [%v]",
+			c.Request.Method,
+			path,
+			c.Writer.Status(),
+			time.Since(start),
+			c.ClientIP(),
+		)
+	}
+}
+
+// validRequestID accepts IDs of up to maxRequestIDLength letters, digits, '-', '_', '.' and ':',
+// so a client cannot inject arbitrary text into logs.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
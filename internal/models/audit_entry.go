@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+)
+
+// AuditEntry records one change to the data: who made it, from where, and the entity before and
+// after. Before is null for a create and After is null for a delete. Secrets such as password
+// hashes are left out of both.
+type AuditEntry struct {
+	custom_types.SequentialIdentifier
+	Actor      string                   `json:"actor"` // username, empty for unauthenticated requests
+	ActorRole  string                   `json:"actor_role"`
+	Action     custom_types.AuditAction `json:"action"`
+	EntityType string                   `json:"entity_type"` // table name
+	EntityID   string                   `json:"entity_id"`
+	Before     json.RawMessage          `json:"before"`
+	After      json.RawMessage          `json:"after"`
+	RequestID  string                   `json:"request_id"`
+	IPAddress  string                   `json:"ip_address"`
+	// ChainPosition, PrevHash and Hash are set when the entry is sealed into the hash chain,
+	// shortly after its transaction commits; until then they are null and empty.
+	ChainPosition *int64    `json:"chain_position"`
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"` // SHA-256 over the entry and PrevHash
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AuditEntryList struct {
+	AuditEntries []*AuditEntry `json:"audit_entries"`
+	Pagination   *Pagination   `json:"pagination"`
+}
+
+// AuditLogVerification is the result of checking the audit log's hash chain. BrokenAt is the
+// first entry whose hash or link to the previous entry does not match. Pending entries are not
+// sealed into the chain yet and are not checked.
+type AuditLogVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int    `json:"entries"`
+	Pending  int    `json:"pending"`
+	HeadHash string `json:"head_hash"` // hash of the latest entry, to compare with an earlier copy
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
@@ -1,19 +1,34 @@
 package models
 
 import (
+	"time"
+
 	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
 )
 
 type Claim struct {
 	custom_types.SequentialIdentifier
-	MemberID        int64                   `json:"member_id"`
-	ProviderID      int64                   `json:"provider_id"`
-	ProcedureCode   string                   `json:"procedure_code"`
-	DiagnosisCode   string                   `json:"diagnosis_code"`
-	RequestedAmount float64                  `json:"requested_amount"`
-	ApprovedAmount  float64                  `json:"approved_amount"`
-	Status          custom_types.ClaimStatus `json:"status"`
-	FraudFlag       bool                     `json:"fraud_flag"`
-	RejectionReason string                   `json:"rejection_reason"`
+	MemberID             int64                    `json:"member_id"`
+	ProviderID           int64                    `json:"provider_id"`
+	ProcedureCode        string                   `json:"procedure_code"`
+	DiagnosisCode        string                   `json:"diagnosis_code"`
+	RequestedAmount      money.Money              `json:"requested_amount"` // as billed, in Currency
+	Currency             string                   `json:"currency"`
+	ExchangeRate         money.Rate               `json:"exchange_rate"`    // Currency to BenefitCurrency on the service date
+	ConvertedAmount      money.Money              `json:"converted_amount"` // requested amount in BenefitCurrency
+	ApprovedAmount       money.Money              `json:"approved_amount"`  // in BenefitCurrency
+	BenefitCurrency      string                   `json:"benefit_currency"`
+	Status               custom_types.ClaimStatus `json:"status"`
+	FraudFlag            bool                     `json:"fraud_flag"`
+	RejectionReason      string                   `json:"rejection_reason"`
+	ProcedureVersionID   int64                    `json:"procedure_version_id"` // price version the claim was adjudicated against
+	ServiceDate          time.Time                `json:"service_date"`
+	AdmissionDate        *time.Time               `json:"admission_date"` // inpatient claims only
+	DischargeDate        *time.Time               `json:"discharge_date"` // inpatient claims only
+	PaymentBatchID       int64                    `json:"payment_batch_id"`
+	PaidAt               *time.Time               `json:"paid_at"`
+	X12SenderID          string                   `json:"x12_sender_id,omitempty"`          // ISA06 of the 837 the claim was filed in
+	PatientControlNumber string                   `json:"patient_control_number,omitempty"` // CLM01, unique per X12 sender
 	custom_types.Timestamps
 }
@@ -0,0 +1,34 @@
+package models
+
+import "github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+
+// ClaimAttachment is a supporting document for a claim. The file is kept in storage under
+// StorageKey; Checksum is the hex SHA-256 of its contents.
+type ClaimAttachment struct {
+	custom_types.SequentialIdentifier
+	ClaimID      int64                     `json:"claim_id"`
+	DocumentType custom_types.DocumentType `json:"document_type"`
+	FileName     string                    `json:"file_name"`
+	ContentType  string                    `json:"content_type"`
+	SizeBytes    int64                     `json:"size_bytes"`
+	Checksum     string                    `json:"checksum_sha256"`
+	StorageKey   string                    `json:"-"`
+	UploadedBy   string                    `json:"uploaded_by"`
+	custom_types.Timestamps
+}
+
+// ClaimAttachmentList is a claim's attachments and the required document types still missing.
+type ClaimAttachmentList struct {
+	Attachments           []*ClaimAttachment          `json:"attachments"`
+	RequiredDocumentTypes []custom_types.DocumentType `json:"required_document_types"`
+	MissingDocumentTypes  []custom_types.DocumentType `json:"missing_document_types"`
+	Complete              bool                        `json:"complete"`
+}
+
+// ProcedureDocumentRequirement requires claims for the procedure to carry a document type.
+type ProcedureDocumentRequirement struct {
+	custom_types.SequentialIdentifier
+	ProcedureCode string                    `json:"procedure_code"`
+	DocumentType  custom_types.DocumentType `json:"document_type"`
+	custom_types.Timestamps
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+// CostRun is one recomputation of procedure costs from claim history. Its proposals only change
+// procedure prices once the run is approved.
+type CostRun struct {
+	custom_types.SequentialIdentifier
+	Method     custom_types.CostMethod    `json:"method"`
+	WindowDays int                        `json:"window_days"`
+	MinSamples int                        `json:"min_samples"`
+	Status     custom_types.CostRunStatus `json:"status"`
+	ReviewedBy string                     `json:"reviewed_by"`
+	ReviewedAt *time.Time                 `json:"reviewed_at"`
+	Proposals  []*CostProposal            `json:"proposals,omitempty"`
+	custom_types.Timestamps
+}
+
+type CostProposal struct {
+	custom_types.SequentialIdentifier
+	RunID         int64       `json:"run_id"`
+	ProcedureID   int64       `json:"procedure_id"`
+	ProcedureCode string      `json:"procedure_code"`
+	CurrentCost   money.Money `json:"current_cost"`
+	ProposedCost  money.Money `json:"proposed_cost"`
+	Currency      string      `json:"currency"` // the procedure's currency
+	SampleSize    int         `json:"sample_size"`
+	custom_types.Timestamps
+}
+
+// ClaimAmount is the approved amount of a single claim, used as a cost sample.
+type ClaimAmount struct {
+	ProcedureCode string
+	Amount        money.Money
+}
@@ -0,0 +1,6 @@
+package models
+
+type CostRunList struct {
+	Runs       []*CostRun  `json:"runs"`
+	Pagination *Pagination `json:"pagination"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+// ExchangeRate is the price of one unit of BaseCurrency in QuoteCurrency from EffectiveFrom until
+// the pair's next rate takes effect.
+type ExchangeRate struct {
+	custom_types.SequentialIdentifier
+	BaseCurrency  string     `json:"base_currency"`
+	QuoteCurrency string     `json:"quote_currency"`
+	Rate          money.Rate `json:"rate"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	custom_types.Timestamps
+}
+
+type ExchangeRateList struct {
+	ExchangeRates []*ExchangeRate `json:"exchange_rates"`
+	Pagination    *Pagination     `json:"pagination"`
+}
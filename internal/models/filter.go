@@ -28,6 +28,13 @@ type Filter struct {
 	CountQuery bool
 	MemberID   *string
 	ProviderID *string
+	Role       string
+	Currency   string
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
 }
 
 func (f *Filter) ConvertTime() error {
@@ -65,6 +72,15 @@ func (f *Filter) NoPagination() *Filter {
 		Active:     f.Active,
 		MemberID:   f.MemberID,
 		ProviderID: f.ProviderID,
+		Role:       f.Role,
+		Currency:   f.Currency,
+		FromTime:   f.FromTime,
+		ToTime:     f.ToTime,
+		Actor:      f.Actor,
+		Action:     f.Action,
+		EntityType: f.EntityType,
+		EntityID:   f.EntityID,
+		RequestID:  f.RequestID,
 	}
 }
 
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+)
+
+type LoginAttempt struct {
+	custom_types.SequentialIdentifier
+	Username  string    `json:"username"`
+	IPAddress string    `json:"ip_address"`
+	Succeeded bool      `json:"succeeded"`
+	CreatedAt time.Time `json:"created_at"`
+}
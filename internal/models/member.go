@@ -1,12 +1,19 @@
 package models
 
-import "github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
 
 type Member struct {
 	custom_types.SequentialIdentifier
-	FullName     string  `json:"full_name"`
-	IsActive     bool    `json:"is_active"`
-	BenefitLimit float64 `json:"benefit_limit"`
-	UsedAmount   float64 `json:"used_amount"`
+	FullName         string      `json:"full_name"`
+	MembershipNumber string      `json:"membership_number"` // scheme membership number, unique when set
+	NationalID       string      `json:"national_id"`       // unique when set
+	CardNumber       string      `json:"card_number"`       // scheme card number, unique when set
+	IsActive         bool        `json:"is_active"`
+	BenefitLimit     money.Money `json:"benefit_limit"`
+	UsedAmount       money.Money `json:"used_amount"`
+	BenefitCurrency  string      `json:"benefit_currency"` // currency of the benefit limit and of approved amounts
 	custom_types.Timestamps
 }
@@ -0,0 +1,6 @@
+package models
+
+type MemberList struct {
+	Members    []*Member   `json:"members"`
+	Pagination *Pagination `json:"pagination"`
+}
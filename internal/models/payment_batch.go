@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+// PaymentBatch is one payment to a provider covering a group of approved claims in one currency.
+// Claims are held by the batch from the moment it is drafted and marked paid when the batch is paid.
+type PaymentBatch struct {
+	custom_types.SequentialIdentifier
+	ProviderID       int64                           `json:"provider_id"`
+	Status           custom_types.PaymentBatchStatus `json:"status"`
+	ClaimCount       int                             `json:"claim_count"`
+	TotalAmount      money.Money                     `json:"total_amount"`
+	Currency         string                          `json:"currency"`
+	PaymentReference string                          `json:"payment_reference"`
+	ApprovedBy       string                          `json:"approved_by"`
+	ApprovedAt       *time.Time                      `json:"approved_at"`
+	PaidAt           *time.Time                      `json:"paid_at"`
+	Claims           []*RemittanceLine               `json:"claims,omitempty"`
+	custom_types.Timestamps
+}
+
+type PaymentBatchList struct {
+	Batches    []*PaymentBatch `json:"batches"`
+	Pagination *Pagination     `json:"pagination"`
+}
+
+// PayableProvider is a provider owed payable claims in one currency.
+type PayableProvider struct {
+	ProviderID int64
+	Currency   string
+}
+
+// RemittanceLine is a single claim as it appears on a remittance or provider statement. Amounts
+// after the requested amount are in PaymentCurrency, the member's benefit currency. The adjustment
+// is the part of the converted requested amount that was not approved.
+type RemittanceLine struct {
+	ClaimID          int64                    `json:"claim_id"`
+	MemberID         int64                    `json:"member_id"`
+	ProcedureCode    string                   `json:"procedure_code"`
+	ServiceDate      time.Time                `json:"service_date"`
+	Status           custom_types.ClaimStatus `json:"status"`
+	FraudFlag        bool                     `json:"fraud_flag"`
+	RequestedAmount  money.Money              `json:"requested_amount"`
+	Currency         string                   `json:"currency"`
+	ExchangeRate     money.Rate               `json:"exchange_rate"`
+	ConvertedAmount  money.Money              `json:"converted_amount"`
+	ApprovedAmount   money.Money              `json:"approved_amount"`
+	PaymentCurrency  string                   `json:"payment_currency"`
+	AdjustmentAmount money.Money              `json:"adjustment_amount"`
+	AdjustmentReason string                   `json:"adjustment_reason"`
+	PaymentBatchID   int64                    `json:"payment_batch_id"`
+	PaymentReference string                   `json:"payment_reference"`
+	PaidAt           *time.Time               `json:"paid_at"`
+}
+
+// ProviderStatement lists a provider's claims submitted in a period with what was approved,
+// what has been paid and what is still owed. Totals are per payment currency; requested amounts
+// are totalled as converted.
+type ProviderStatement struct {
+	Provider       *Provider         `json:"provider"`
+	From           string            `json:"from"`
+	To             string            `json:"to"`
+	Claims         []*RemittanceLine `json:"claims"`
+	TotalRequested money.Totals      `json:"total_requested"`
+	TotalApproved  money.Totals      `json:"total_approved"`
+	TotalPaid      money.Totals      `json:"total_paid"`
+	Outstanding    money.Totals      `json:"outstanding"`
+}
@@ -1,11 +1,15 @@
 package models
 
-import "github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
 
 type Procedure struct {
 	custom_types.SequentialIdentifier
-	Code        string  `json:"code"`
-	Description string  `json:"description"`
-	AverageCost float64 `json:"average_cost"`
+	Code        string      `json:"code"`
+	Description string      `json:"description"`
+	AverageCost money.Money `json:"average_cost"`
+	Currency    string      `json:"currency"` // of every price version, fixed at creation
 	custom_types.Timestamps
 }
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+// ProcedureVersion is the price of a procedure over an effective period. EffectiveTo is nil for
+// the current open-ended version.
+type ProcedureVersion struct {
+	custom_types.SequentialIdentifier
+	ProcedureID   int64       `json:"procedure_id"`
+	ProcedureCode string      `json:"procedure_code"`
+	AverageCost   money.Money `json:"average_cost"`
+	Currency      string      `json:"currency"` // the procedure's currency
+	EffectiveFrom time.Time   `json:"effective_from"`
+	EffectiveTo   *time.Time  `json:"effective_to"`
+	custom_types.Timestamps
+}
+
+type ProcedureHistory struct {
+	Procedure *Procedure          `json:"procedure"`
+	Versions  []*ProcedureVersion `json:"versions"`
+}
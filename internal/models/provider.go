@@ -1,10 +1,27 @@
 package models
 
-import "github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+)
 
 type Provider struct {
 	custom_types.SequentialIdentifier
-	Name     string `json:"name"`
-	Location string `json:"location"`
+	Name                string                           `json:"name"`
+	Location            string                           `json:"location"`
+	LicenceNumber       string                           `json:"licence_number"`
+	AccreditationExpiry *time.Time                       `json:"accreditation_expiry"`
+	NetworkTier         custom_types.ProviderNetworkTier `json:"network_tier"`
+	Status              custom_types.ProviderStatus      `json:"status"`
 	custom_types.Timestamps
 }
+
+// IsAccredited reports whether the accreditation is still valid on the given date.
+// Providers without a recorded expiry are treated as accredited.
+func (p *Provider) IsAccredited(on time.Time) bool {
+	if p.AccreditationExpiry == nil {
+		return true
+	}
+	return on.Before(p.AccreditationExpiry.AddDate(0, 0, 1))
+}
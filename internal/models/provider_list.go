@@ -0,0 +1,6 @@
+package models
+
+type ProviderList struct {
+	Providers  []*Provider `json:"providers"`
+	Pagination *Pagination `json:"pagination"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+type ProviderTariff struct {
+	custom_types.SequentialIdentifier
+	ProviderID    int64       `json:"provider_id"`
+	ProcedureCode string      `json:"procedure_code"`
+	AgreedPrice   money.Money `json:"agreed_price"`
+	Currency      string      `json:"currency"`
+	EffectiveFrom time.Time   `json:"effective_from"`
+	EffectiveTo   *time.Time  `json:"effective_to"`
+	custom_types.Timestamps
+}
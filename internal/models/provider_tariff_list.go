@@ -0,0 +1,6 @@
+package models
+
+type ProviderTariffList struct {
+	Tariffs    []*ProviderTariff `json:"tariffs"`
+	Pagination *Pagination       `json:"pagination"`
+}
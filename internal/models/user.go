@@ -1,14 +1,40 @@
 package models
 
 import (
+	"time"
+
 	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 )
 
 type User struct {
 	custom_types.SequentialIdentifier
-	Username     string `json:"username"`
-	Email        string `json:"email"`
-	PasswordHash string `json:"-"`
-	IsActive     bool   `json:"is_active"`
+	Username         string                `json:"username"`
+	Email            string                `json:"email"`
+	PasswordHash     string                `json:"-"`
+	IsActive         bool                  `json:"is_active"`
+	EmailVerified    bool                  `json:"email_verified"`
+	Role             custom_types.UserRole `json:"role"`
+	FailedLoginCount int                   `json:"failed_login_count"`
+	LockedUntil      *time.Time            `json:"locked_until"`
+	MFASecret        string                `json:"-"`
+	MFAEnabled       bool                  `json:"mfa_enabled"`
+	MFALastStep      int64                 `json:"-"`
+	TokensValidAfter *time.Time            `json:"-"`
 	custom_types.Timestamps
-}
\ No newline at end of file
+}
+
+// IsLocked reports whether the account is temporarily locked after repeated failed logins.
+func (u *User) IsLocked() bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(time.Now())
+}
+
+// RevokeTokens makes every token issued to the user so far invalid.
+func (u *User) RevokeTokens() {
+	now := time.Now()
+	u.TokensValidAfter = &now
+}
+
+// TokenRevoked reports whether a token issued at issuedAt has been revoked by RevokeTokens.
+func (u *User) TokenRevoked(issuedAt time.Time) bool {
+	return u.TokensValidAfter != nil && issuedAt.Before(*u.TokensValidAfter)
+}
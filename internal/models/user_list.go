@@ -0,0 +1,6 @@
+package models
+
+type UserList struct {
+	Users      []*User     `json:"users"`
+	Pagination *Pagination `json:"pagination"`
+}
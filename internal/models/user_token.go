@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+)
+
+type UserToken struct {
+	custom_types.SequentialIdentifier
+	UserID    int64                     `json:"user_id"`
+	Purpose   custom_types.TokenPurpose `json:"purpose"`
+	TokenHash string                    `json:"-"`
+	ExpiresAt time.Time                 `json:"expires_at"`
+	UsedAt    *time.Time                `json:"used_at"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+// IsUsable reports whether the token has neither been used nor expired.
+func (t *UserToken) IsUsable() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -0,0 +1,320 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultCurrency is the currency of amounts that do not carry one.
+	DefaultCurrency = "KES"
+	// MinorUnits is the number of minor units in one unit of currency, matching the two decimal
+	// places of the DECIMAL amount columns.
+	MinorUnits = 100
+	// MaxAmount is the largest amount, in minor units, the DECIMAL(14, 2) amount columns hold.
+	MaxAmount = 99999999999999
+)
+
+// Currencies are the currencies amounts can be held in.
+var Currencies = []string{"KES", "UGX", "TZS", "USD"}
+
+var (
+	errInvalidAmount    = errors.New("invalid amount")
+	errAmountOutOfRange = errors.New("amount out of range")
+)
+
+// Money is an exact amount held as a whole number of minor units (hundredths) of a currency.
+// Arithmetic never rounds. Combining two amounts in different currencies panics: convert first.
+// An empty currency takes the currency of the other operand.
+type Money struct {
+	Amount   int64  // minor units
+	Currency string // ISO 4217 code
+}
+
+// IsCurrency reports whether the code is one of Currencies.
+func IsCurrency(code string) bool {
+	for _, currency := range Currencies {
+		if currency == code {
+			return true
+		}
+	}
+	return false
+}
+
+// New returns the amount of minor units in the currency.
+func New(minor int64, currency string) Money {
+	return Money{Amount: minor, Currency: currency}
+}
+
+// FromMinor returns the amount of minor units in the default currency.
+func FromMinor(minor int64) Money {
+	return New(minor, DefaultCurrency)
+}
+
+// Parse reads a decimal amount such as "1500", "1500.5" or "-20.75" in the default currency.
+// Digits past the second decimal place are rounded half away from zero, as the database does.
+func Parse(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+
+	negative := false
+	switch {
+	case strings.HasPrefix(value, "-"):
+		negative = true
+		value = value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if (whole == "" && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, errInvalidAmount
+	}
+
+	cents := fraction
+	if len(cents) > 2 {
+		cents = cents[:2]
+	}
+	cents += strings.Repeat("0", 2-len(cents))
+
+	minor, err := strconv.ParseInt(strings.TrimLeft(whole, "0")+cents, 10, 64)
+	if err != nil {
+		return Money{}, errAmountOutOfRange
+	}
+	if len(fraction) > 2 && fraction[2] >= '5' {
+		if minor == math.MaxInt64 {
+			return Money{}, errAmountOutOfRange
+		}
+		minor++
+	}
+	if negative {
+		minor = -minor
+	}
+	return FromMinor(minor), nil
+}
+
+// MustParse is Parse for amounts known to be valid, such as constants.
+func MustParse(value string) Money {
+	m, err := Parse(value)
+	if err != nil {
+		panic(fmt.Sprintf("money: %v: %q", err, value))
+	}
+	return m
+}
+
+// FromFloat rounds a float amount to the nearest minor unit. It is only for floats a database
+// driver hands back; amounts are never computed in floating point.
+func FromFloat(value float64, currency string) Money {
+	return New(int64(math.Round(value*MinorUnits)), currency)
+}
+
+func (m Money) Add(o Money) Money {
+	return Money{Amount: m.Amount + o.Amount, Currency: m.currencyWith(o)}
+}
+
+func (m Money) Sub(o Money) Money {
+	return Money{Amount: m.Amount - o.Amount, Currency: m.currencyWith(o)}
+}
+
+// Mul multiplies the amount by a whole number.
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than o.
+func (m Money) Cmp(o Money) int {
+	m.currencyWith(o)
+	switch {
+	case m.Amount < o.Amount:
+		return -1
+	case m.Amount > o.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m Money) GreaterThan(o Money) bool {
+	return m.Cmp(o) > 0
+}
+
+func (m Money) LessThan(o Money) bool {
+	return m.Cmp(o) < 0
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// FitsColumn reports whether the amount can be stored in a DECIMAL(14, 2) amount column.
+func (m Money) FitsColumn() bool {
+	return -MaxAmount <= m.Amount && m.Amount <= MaxAmount
+}
+
+// Float64 is the amount in units of currency, for statistics and formats that need a float.
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / MinorUnits
+}
+
+// String formats the amount with two decimal places and no currency, e.g. "1500.50".
+func (m Money) String() string {
+	sign := ""
+	abs := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		abs = uint64(-(m.Amount + 1)) + 1
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, abs/MinorUnits, abs%MinorUnits)
+}
+
+func Min(a, b Money) Money {
+	if b.LessThan(a) {
+		return b.withCurrency(a.currencyWith(b))
+	}
+	return a.withCurrency(a.currencyWith(b))
+}
+
+func Max(a, b Money) Money {
+	if b.GreaterThan(a) {
+		return b.withCurrency(a.currencyWith(b))
+	}
+	return a.withCurrency(a.currencyWith(b))
+}
+
+func Sum(values ...Money) Money {
+	var total Money
+	for _, value := range values {
+		total = total.Add(value)
+	}
+	return total
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimal places.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or numeric string without going through float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if value == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+
+	parsed, err := Parse(value)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, value)
+	}
+
+	m.Amount = parsed.Amount
+	if m.Currency == "" {
+		m.Currency = parsed.Currency
+	}
+	return nil
+}
+
+// Value stores the amount as decimal text so DECIMAL columns receive it exactly.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads a DECIMAL column. NULL is read as zero.
+func (m *Money) Scan(src interface{}) error {
+	var minor int64
+	switch value := src.(type) {
+	case nil:
+	case []byte:
+		parsed, err := Parse(string(value))
+		if err != nil {
+			return fmt.Errorf("money: scan %q: %w", value, err)
+		}
+		minor = parsed.Amount
+	case string:
+		parsed, err := Parse(value)
+		if err != nil {
+			return fmt.Errorf("money: scan %q: %w", value, err)
+		}
+		minor = parsed.Amount
+	case int64:
+		minor = value * MinorUnits
+	case float64:
+		minor = FromFloat(value, "").Amount
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+
+	m.Amount = minor
+	if m.Currency == "" {
+		m.Currency = DefaultCurrency
+	}
+	return nil
+}
+
+// Totals sums amounts per currency, for lists whose amounts are in more than one currency.
+type Totals map[string]Money
+
+func (t Totals) Add(m Money) {
+	currency := m.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	t[currency] = t[currency].Add(m.withCurrency(currency))
+}
+
+// String lists the totals by currency, e.g. "1500.00 KES, 20.00 USD".
+func (t Totals) String() string {
+	currencies := make([]string, 0, len(t))
+	for currency := range t {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	totals := make([]string, len(currencies))
+	for i, currency := range currencies {
+		totals[i] = t[currency].String() + " " + currency
+	}
+	return strings.Join(totals, ", ")
+}
+
+func (m Money) withCurrency(currency string) Money {
+	m.Currency = currency
+	return m
+}
+
+func (m Money) currencyWith(o Money) string {
+	switch {
+	case m.Currency == o.Currency || o.Currency == "":
+		return m.Currency
+	case m.Currency == "":
+		return o.Currency
+	}
+	panic(fmt.Sprintf("money: currency mismatch: %s and %s", m.Currency, o.Currency))
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
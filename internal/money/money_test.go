@@ -0,0 +1,242 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// amount is a generated Money in the default currency, bounded so sums of a few amounts cannot
+// overflow.
+type amount struct {
+	Money
+}
+
+func (amount) Generate(r *rand.Rand, _ int) reflect.Value {
+	const bound = 1 << 40
+	return reflect.ValueOf(amount{FromMinor(r.Int63n(2*bound) - bound)})
+}
+
+func check(t *testing.T, property interface{}) {
+	t.Helper()
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddIsCommutativeAndAssociative(t *testing.T) {
+	check(t, func(a, b, c amount) bool {
+		return a.Add(b.Money) == b.Add(a.Money) &&
+			a.Add(b.Money).Add(c.Money) == a.Add(b.Add(c.Money))
+	})
+}
+
+func TestSubUndoesAdd(t *testing.T) {
+	check(t, func(a, b amount) bool {
+		return a.Add(b.Money).Sub(b.Money) == a.Money && a.Sub(a.Money).IsZero()
+	})
+}
+
+func TestZeroIsIdentity(t *testing.T) {
+	check(t, func(a amount) bool {
+		return a.Add(Money{}) == a.Money && Money{}.Add(a.Money) == a.Money
+	})
+}
+
+func TestRepeatedAddHasNoDrift(t *testing.T) {
+	check(t, func(a amount, n uint8) bool {
+		var total Money
+		for i := 0; i < int(n); i++ {
+			total = total.Add(a.Money)
+		}
+		return total.Amount == a.Mul(int64(n)).Amount
+	})
+
+	// the float64 sum of ten 0.10 is 0.9999999999999999
+	var total Money
+	for i := 0; i < 10; i++ {
+		total = total.Add(MustParse("0.10"))
+	}
+	if total != MustParse("1.00") {
+		t.Errorf("ten 0.10 = %v, want 1.00", total)
+	}
+}
+
+func TestCmpMatchesSub(t *testing.T) {
+	check(t, func(a, b amount) bool {
+		difference := a.Sub(b.Money)
+		switch a.Cmp(b.Money) {
+		case -1:
+			return difference.IsNegative() && a.LessThan(b.Money)
+		case 1:
+			return difference.IsPositive() && a.GreaterThan(b.Money)
+		default:
+			return difference.IsZero() && a.Money == b.Money
+		}
+	})
+}
+
+func TestMinAndMax(t *testing.T) {
+	check(t, func(a, b amount) bool {
+		low, high := Min(a.Money, b.Money), Max(a.Money, b.Money)
+		return !low.GreaterThan(a.Money) && !low.GreaterThan(b.Money) &&
+			!high.LessThan(a.Money) && !high.LessThan(b.Money) &&
+			low.Add(high) == a.Add(b.Money)
+	})
+}
+
+func TestSumEqualsRepeatedAdd(t *testing.T) {
+	check(t, func(a, b, c amount) bool {
+		return Sum(a.Money, b.Money, c.Money) == a.Add(b.Money).Add(c.Money)
+	})
+}
+
+func TestStringParsesBack(t *testing.T) {
+	check(t, func(a amount) bool {
+		parsed, err := Parse(a.String())
+		return err == nil && parsed == a.Money
+	})
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	check(t, func(a amount) bool {
+		data, err := json.Marshal(a.Money)
+		if err != nil {
+			return false
+		}
+		var decoded Money
+		return json.Unmarshal(data, &decoded) == nil && decoded == a.Money
+	})
+}
+
+func TestValueScansBack(t *testing.T) {
+	check(t, func(a amount) bool {
+		value, err := a.Value()
+		if err != nil {
+			return false
+		}
+		var scanned Money
+		return scanned.Scan([]byte(value.(string))) == nil && scanned == a.Money
+	})
+}
+
+func TestParse(t *testing.T) {
+	valid := map[string]int64{
+		"1500":     150000,
+		"1500.5":   150050,
+		"1500.50":  150050,
+		"+0.01":    1,
+		"-20.75":   -2075,
+		".5":       50,
+		"7.":       700,
+		"0.005":    1,
+		"-0.005":   -1,
+		"0.004":    0,
+		"19.999":   2000,
+		"  42.10 ": 4210,
+	}
+	for input, minor := range valid {
+		parsed, err := Parse(input)
+		if err != nil || parsed.Amount != minor || parsed.Currency != DefaultCurrency {
+			t.Errorf("Parse(%q) = %v, %v; want %d minor units", input, parsed, err, minor)
+		}
+	}
+
+	for _, input := range []string{"", ".", "-", "abc", "1,000", "1e3", "1/2", "0x10", "1.2.3", "--1", "99999999999999999999"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", input)
+		}
+	}
+}
+
+func TestCurrencyMismatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("adding KES to USD did not panic")
+		}
+	}()
+	FromMinor(100).Add(New(100, "USD"))
+}
+
+func TestConvertAtOneRateIsIdentity(t *testing.T) {
+	check(t, func(a amount) bool {
+		converted, err := a.Convert(OneRate(), DefaultCurrency)
+		return err == nil && converted == a.Money
+	})
+}
+
+func TestConvertRoundsOnce(t *testing.T) {
+	rate := MustParseRate("0.0077")
+	cases := map[int64]int64{
+		100000: 770, // 1000.00 KES is 7.70 USD
+		65:     1,   // 0.5005 cents rounds up
+		64:     0,   // 0.4928 cents rounds down
+		-65:    -1,
+	}
+	for minor, want := range cases {
+		if converted, err := FromMinor(minor).Convert(rate, "USD"); err != nil || converted != New(want, "USD") {
+			t.Errorf("Convert(%d) = %v, %v, want %d USD minor units", minor, converted, err, want)
+		}
+	}
+
+	if converted, err := New(5, "USD").Convert(MustParseRate("0.5"), DefaultCurrency); err != nil || converted.Amount != 3 {
+		t.Errorf("Convert(0.05 at 0.5) = %v, %v, want 0.03", converted, err)
+	}
+}
+
+func TestConvertOutOfRange(t *testing.T) {
+	if _, err := New(math.MaxInt64/2, "USD").Convert(MustParseRate("3700"), "UGX"); err == nil {
+		t.Error("converting past the int64 range did not fail")
+	}
+
+	converted, err := New(MaxAmount, "USD").Convert(MustParseRate("3700"), "UGX")
+	if err != nil || converted.FitsColumn() {
+		t.Errorf("Convert(MaxAmount at 3700) = %v, %v, want an amount too large for the column", converted, err)
+	}
+}
+
+func TestFitsColumn(t *testing.T) {
+	for _, m := range []Money{FromMinor(0), FromMinor(MaxAmount), FromMinor(-MaxAmount), MustParse("999999999999.99")} {
+		if !m.FitsColumn() {
+			t.Errorf("%v does not fit the column", m)
+		}
+	}
+	for _, m := range []Money{FromMinor(MaxAmount + 1), FromMinor(-MaxAmount - 1), MustParse("1000000000000")} {
+		if m.FitsColumn() {
+			t.Errorf("%v fits the column", m)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, input := range []string{"", "0", "0.0", "-1", "1e3", "abc", "1/2"} {
+		if _, err := ParseRate(input); err == nil {
+			t.Errorf("ParseRate(%q) succeeded, want error", input)
+		}
+	}
+
+	rate := MustParseRate("129.35")
+	if rate.String() != "129.35" || rate.Inverse().Inverse().String() != "129.35" {
+		t.Errorf("rate %v does not round-trip through its inverse", rate)
+	}
+	if inverse := rate.Inverse().Rounded().String(); inverse != "0.0077309625" {
+		t.Errorf("Inverse(129.35) = %v, want 0.0077309625", inverse)
+	}
+
+	// the inverse of a large rate is too small to keep at RateDecimals places
+	if MustParseRate("30000000000").Inverse().Rounded().IsValid() {
+		t.Error("the inverse of 3e10 rounded to a valid rate, want the zero Rate")
+	}
+}
+
+func TestRateFitsColumn(t *testing.T) {
+	if !MustParseRate("99999999999999.9999999999").FitsColumn() {
+		t.Error("the largest DECIMAL(24, 10) rate does not fit the column")
+	}
+	if MustParseRate("100000000000000").FitsColumn() {
+		t.Error("a rate with 15 integer digits fits the column")
+	}
+}
@@ -0,0 +1,163 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// RateDecimals is the number of decimal places a rate is stored with.
+	RateDecimals = 10
+	// RateIntegerDigits is the number of digits before the decimal point the DECIMAL(24, 10) rate
+	// columns hold.
+	RateIntegerDigits = 14
+)
+
+var maxRate = new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(RateIntegerDigits), nil))
+
+var errInvalidRate = errors.New("invalid rate")
+
+// Rate is an exact exchange rate: one unit of the base currency buys Rate units of the quote
+// currency. The zero Rate is not a valid rate.
+type Rate struct {
+	value *big.Rat
+}
+
+// ParseRate reads a positive decimal rate such as "129.35" or "0.0077".
+func ParseRate(value string) (Rate, error) {
+	value = strings.TrimSpace(value)
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if (whole == "" && fraction == "") || !isDigits(whole) || !isDigits(fraction) || len(whole) > 18 {
+		return Rate{}, errInvalidRate
+	}
+
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok || rat.Sign() <= 0 {
+		return Rate{}, errInvalidRate
+	}
+	return Rate{value: rat}, nil
+}
+
+// MustParseRate is ParseRate for rates known to be valid, such as constants.
+func MustParseRate(value string) Rate {
+	rate, err := ParseRate(value)
+	if err != nil {
+		panic(fmt.Sprintf("money: %v: %q", err, value))
+	}
+	return rate
+}
+
+// OneRate converts a currency to itself.
+func OneRate() Rate {
+	return Rate{value: big.NewRat(1, 1)}
+}
+
+func (r Rate) IsValid() bool {
+	return r.value != nil && r.value.Sign() > 0
+}
+
+// FitsColumn reports whether the rate is below 10^RateIntegerDigits, so a DECIMAL(24, 10) rate
+// column can hold it.
+func (r Rate) FitsColumn() bool {
+	return r.IsValid() && r.value.Cmp(maxRate) < 0
+}
+
+// Inverse is the rate for the opposite direction, kept exact.
+func (r Rate) Inverse() Rate {
+	if !r.IsValid() {
+		return Rate{}
+	}
+	return Rate{value: new(big.Rat).Inv(r.value)}
+}
+
+// Rounded is the rate as it is stored, to RateDecimals places.
+func (r Rate) Rounded() Rate {
+	if !r.IsValid() {
+		return Rate{}
+	}
+	rounded, _ := ParseRate(r.String())
+	return rounded
+}
+
+// String formats the rate to RateDecimals places without trailing zeros, e.g. "129.35".
+func (r Rate) String() string {
+	if r.value == nil {
+		return "0"
+	}
+	return strings.TrimSuffix(strings.TrimRight(r.value.FloatString(RateDecimals), "0"), ".")
+}
+
+// Convert turns the amount into the currency at the rate, rounding once to the nearest minor
+// unit, halves away from zero. It fails if the converted amount does not fit in an int64.
+func (m Money) Convert(rate Rate, currency string) (Money, error) {
+	if !rate.IsValid() {
+		panic("money: convert at an invalid rate")
+	}
+
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate.value)
+	quotient, remainder := new(big.Int).QuoRem(product.Num(), product.Denom(), new(big.Int))
+	if new(big.Int).Lsh(new(big.Int).Abs(remainder), 1).Cmp(product.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(product.Sign())))
+	}
+	if !quotient.IsInt64() {
+		return Money{}, errAmountOutOfRange
+	}
+	return New(quotient.Int64(), currency), nil
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if value == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+
+	parsed, err := ParseRate(value)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, value)
+	}
+	*r = parsed
+	return nil
+}
+
+// Value stores the rate as decimal text rounded to RateDecimals places.
+func (r Rate) Value() (driver.Value, error) {
+	if !r.IsValid() {
+		return nil, errInvalidRate
+	}
+	return r.String(), nil
+}
+
+func (r *Rate) Scan(src interface{}) error {
+	var value string
+	switch src := src.(type) {
+	case []byte:
+		value = string(src)
+	case string:
+		value = src
+	case float64:
+		value = strconv.FormatFloat(src, 'f', -1, 64)
+	case int64:
+		value = strconv.FormatInt(src, 10)
+	default:
+		return fmt.Errorf("money: cannot scan %T as a rate", src)
+	}
+
+	parsed, err := ParseRate(value)
+	if err != nil {
+		return fmt.Errorf("money: scan rate %q: %w", value, err)
+	}
+	*r = parsed
+	return nil
+}
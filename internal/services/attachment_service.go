@@ -0,0 +1,289 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/storage"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/pborman/uuid"
+)
+
+// attachmentContentTypes are the file types accepted as claim attachments, detected from the
+// file contents rather than trusted from the upload.
+var attachmentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+type AttachmentService interface {
+	UploadAttachment(ctx context.Context, dB db.DB, claimID int64, form *dtos.ClaimAttachmentUpload) (*models.ClaimAttachment, error)
+	GetClaimAttachments(ctx context.Context, dB db.DB, claimID int64) (*models.ClaimAttachmentList, error)
+	DownloadAttachment(ctx context.Context, dB db.DB, claimID, attachmentID int64) (*models.ClaimAttachment, io.ReadCloser, error)
+	GetDocumentRequirements(ctx context.Context, dB db.DB, procedureCode string) ([]*models.ProcedureDocumentRequirement, error)
+	SetDocumentRequirements(ctx context.Context, dB db.DB, procedureCode string, form *dtos.DocumentRequirements) ([]*models.ProcedureDocumentRequirement, error)
+}
+
+type attachmentService struct {
+	store    *domain.Store
+	storage  storage.Storage
+	maxBytes int64
+}
+
+// NewAttachmentService builds the claim attachments service. Files larger than maxBytes are refused.
+func NewAttachmentService(
+	store *domain.Store,
+	storage storage.Storage,
+	maxBytes int64,
+) AttachmentService {
+	return &attachmentService{
+		store:    store,
+		storage:  storage,
+		maxBytes: maxBytes,
+	}
+}
+
+func (s *attachmentService) UploadAttachment(
+	ctx context.Context,
+	dB db.DB,
+	claimID int64,
+	form *dtos.ClaimAttachmentUpload,
+) (*models.ClaimAttachment, error) {
+	ctx, span := tracing.Start(ctx, "AttachmentService.UploadAttachment", tracing.ClaimID(claimID))
+	defer span.End()
+
+	documentType := custom_types.DocumentType(strings.TrimSpace(form.DocumentType))
+	if !documentType.IsValid() {
+		return nil, apperr.NewBadRequest(fmt.Sprintf("invalid document_type [%v]", form.DocumentType))
+	}
+
+	claim, err := s.store.ClaimDomain.GetClaimByID(ctx, dB, claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	// read one byte past the limit to tell a file of exactly maxBytes from a larger one
+	data, err := io.ReadAll(io.LimitReader(form.File, s.maxBytes+1))
+	if err != nil {
+		return nil, apperr.NewBadRequest("failed to read uploaded file")
+	}
+	if int64(len(data)) > s.maxBytes {
+		return nil, apperr.NewPayloadTooLarge(s.maxBytes, int64(len(data)))
+	}
+	if len(data) == 0 {
+		return nil, apperr.NewBadRequest("file is empty")
+	}
+
+	contentType := strings.SplitN(http.DetectContentType(data), ";", 2)[0]
+	if !attachmentContentTypes[contentType] {
+		return nil, apperr.NewUnsupportedMediaType(fmt.Sprintf("content type [%v] is not accepted, upload a PDF, JPEG or PNG", contentType))
+	}
+
+	checksum := sha256.Sum256(data)
+	attachment := &models.ClaimAttachment{
+		ClaimID:      claim.ID,
+		DocumentType: documentType,
+		FileName:     attachmentFileName(form.FileName),
+		ContentType:  contentType,
+		SizeBytes:    int64(len(data)),
+		Checksum:     hex.EncodeToString(checksum[:]),
+		StorageKey:   fmt.Sprintf("claims/%d/%v", claim.ID, uuid.New()),
+		UploadedBy:   form.UploadedBy,
+	}
+
+	err = s.storage.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.SizeBytes, contentType)
+	if err != nil {
+		return nil, apperr.NewInternal("failed to store attachment").LogErrorMessage("store attachment [%v] error: %v", attachment.StorageKey, err)
+	}
+
+	if err := s.store.ClaimAttachmentDomain.CreateClaimAttachment(ctx, dB, attachment); err != nil {
+		// without its row the stored file is unreachable
+		if deleteErr := s.storage.Delete(ctx, attachment.StorageKey); deleteErr != nil {
+			logger.FromContext(ctx).Errorf("delete orphaned attachment [%v] error: %v", attachment.StorageKey, deleteErr)
+		}
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// GetClaimAttachments lists a claim's attachments and checks them against the document types
+// required for the claim's procedure.
+func (s *attachmentService) GetClaimAttachments(
+	ctx context.Context,
+	dB db.DB,
+	claimID int64,
+) (*models.ClaimAttachmentList, error) {
+	ctx, span := tracing.Start(ctx, "AttachmentService.GetClaimAttachments", tracing.ClaimID(claimID))
+	defer span.End()
+
+	claim, err := s.store.ClaimDomain.GetClaimByID(ctx, dB, claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.store.ClaimAttachmentDomain.GetClaimAttachments(ctx, dB, claim.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements := make([]*models.ProcedureDocumentRequirement, 0)
+	if claim.ProcedureCode != "" {
+		requirements, err = s.store.ProcedureDocumentRequirementDomain.GetProcedureDocumentRequirements(ctx, dB, claim.ProcedureCode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	attached := make(map[custom_types.DocumentType]bool, len(attachments))
+	for _, attachment := range attachments {
+		attached[attachment.DocumentType] = true
+	}
+
+	required := make([]custom_types.DocumentType, 0, len(requirements))
+	missing := make([]custom_types.DocumentType, 0)
+	for _, requirement := range requirements {
+		required = append(required, requirement.DocumentType)
+		if !attached[requirement.DocumentType] {
+			missing = append(missing, requirement.DocumentType)
+		}
+	}
+
+	return &models.ClaimAttachmentList{
+		Attachments:           attachments,
+		RequiredDocumentTypes: required,
+		MissingDocumentTypes:  missing,
+		Complete:              len(missing) == 0,
+	}, nil
+}
+
+// DownloadAttachment returns the attachment and its contents. The caller closes the reader.
+func (s *attachmentService) DownloadAttachment(
+	ctx context.Context,
+	dB db.DB,
+	claimID int64,
+	attachmentID int64,
+) (*models.ClaimAttachment, io.ReadCloser, error) {
+	ctx, span := tracing.Start(ctx, "AttachmentService.DownloadAttachment", tracing.ClaimID(claimID))
+	defer span.End()
+
+	attachment, err := s.store.ClaimAttachmentDomain.GetClaimAttachmentByID(ctx, dB, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attachment.ClaimID != claimID {
+		return nil, nil, apperr.NewNotFound("attachment", fmt.Sprint(attachmentID))
+	}
+
+	body, err := s.storage.Get(ctx, attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, apperr.NewNotFound("attachment file", attachment.StorageKey)
+	}
+	if err != nil {
+		return nil, nil, apperr.NewInternal("failed to read attachment").LogErrorMessage("read attachment [%v] error: %v", attachment.StorageKey, err)
+	}
+
+	return attachment, body, nil
+}
+
+func (s *attachmentService) GetDocumentRequirements(
+	ctx context.Context,
+	dB db.DB,
+	procedureCode string,
+) ([]*models.ProcedureDocumentRequirement, error) {
+	ctx, span := tracing.Start(ctx, "AttachmentService.GetDocumentRequirements")
+	defer span.End()
+
+	procedure, err := s.store.ProcedureDomain.GetProcedureByCode(ctx, dB, procedureCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.ProcedureDocumentRequirementDomain.GetProcedureDocumentRequirements(ctx, dB, procedure.Code)
+}
+
+// SetDocumentRequirements replaces the document types required for claims on the procedure.
+func (s *attachmentService) SetDocumentRequirements(
+	ctx context.Context,
+	dB db.DB,
+	procedureCode string,
+	form *dtos.DocumentRequirements,
+) ([]*models.ProcedureDocumentRequirement, error) {
+	ctx, span := tracing.Start(ctx, "AttachmentService.SetDocumentRequirements")
+	defer span.End()
+
+	documentTypes := make([]custom_types.DocumentType, 0, len(form.DocumentTypes))
+	seen := make(map[custom_types.DocumentType]bool, len(form.DocumentTypes))
+	for _, value := range form.DocumentTypes {
+		documentType := custom_types.DocumentType(strings.TrimSpace(value))
+		if !documentType.IsValid() {
+			return nil, apperr.NewBadRequest(fmt.Sprintf("invalid document_type [%v]", value))
+		}
+		if seen[documentType] {
+			continue
+		}
+		seen[documentType] = true
+		documentTypes = append(documentTypes, documentType)
+	}
+
+	var requirements []*models.ProcedureDocumentRequirement
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		procedure, err := s.store.ProcedureDomain.GetProcedureByCode(ctx, ops, procedureCode)
+		if err != nil {
+			return err
+		}
+
+		err = s.store.ProcedureDocumentRequirementDomain.DeleteProcedureDocumentRequirements(ctx, ops, procedure.Code)
+		if err != nil {
+			return err
+		}
+
+		for _, documentType := range documentTypes {
+			requirement := &models.ProcedureDocumentRequirement{
+				ProcedureCode: procedure.Code,
+				DocumentType:  documentType,
+			}
+			err := s.store.ProcedureDocumentRequirementDomain.CreateProcedureDocumentRequirement(ctx, ops, requirement)
+			if err != nil {
+				return err
+			}
+		}
+
+		requirements, err = s.store.ProcedureDocumentRequirementDomain.GetProcedureDocumentRequirements(ctx, ops, procedure.Code)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return requirements, nil
+}
+
+// attachmentFileName keeps only the base name of the uploaded file for display and download.
+func attachmentFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
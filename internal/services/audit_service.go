@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+type AuditService interface {
+	GetAuditEntries(ctx context.Context, dB db.DB, filter *models.Filter) (*models.AuditEntryList, error)
+	VerifyAuditLog(ctx context.Context, dB db.DB) (*models.AuditLogVerification, error)
+	SealAuditLog(ctx context.Context, dB db.DB) (int, error)
+}
+
+type auditService struct {
+	store *domain.Store
+}
+
+func NewAuditService(store *domain.Store) AuditService {
+	return &auditService{store: store}
+}
+
+// GetAuditEntries lists audit entries, latest first. from and to are inclusive dates.
+func (s *auditService) GetAuditEntries(
+	ctx context.Context,
+	dB db.DB,
+	filter *models.Filter,
+) (*models.AuditEntryList, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.GetAuditEntries")
+	defer span.End()
+
+	if filter.Action != "" && !custom_types.AuditAction(filter.Action).IsValid() {
+		return nil, apperr.NewBadRequest(fmt.Sprintf("invalid action [%v]", filter.Action))
+	}
+
+	if filter.From != "" {
+		from, err := utils.ParseDate(filter.From)
+		if err != nil {
+			return nil, apperr.NewBadRequest(fmt.Sprintf("from: %v", err))
+		}
+		filter.FromTime = &from
+	}
+
+	if filter.To != "" {
+		to, err := utils.ParseDate(filter.To)
+		if err != nil {
+			return nil, apperr.NewBadRequest(fmt.Sprintf("to: %v", err))
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.ToTime = &to
+	}
+
+	entries, err := s.store.AuditEntryDomain.GetAuditEntries(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.AuditEntryDomain.GetAuditEntriesCount(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuditEntryList{
+		AuditEntries: entries,
+		Pagination:   models.NewPagination(count, filter.Page, filter.Per),
+	}, nil
+}
+
+// VerifyAuditLog seals the committed entries still waiting to be chained, then checks the hash
+// chain of the whole audit log.
+func (s *auditService) VerifyAuditLog(
+	ctx context.Context,
+	dB db.DB,
+) (*models.AuditLogVerification, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.VerifyAuditLog")
+	defer span.End()
+
+	_, err := s.store.AuditEntryDomain.SealAuditLog(ctx, dB)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.AuditEntryDomain.VerifyAuditLog(ctx, dB)
+}
+
+// SealAuditLog chains the committed entries not yet in the hash chain.
+func (s *auditService) SealAuditLog(
+	ctx context.Context,
+	dB db.DB,
+) (int, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.SealAuditLog")
+	defer span.End()
+
+	return s.store.AuditEntryDomain.SealAuditLog(ctx, dB)
+}
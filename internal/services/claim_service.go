@@ -2,17 +2,32 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
 	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
 	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/metrics"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 )
 
+// errClaimDryRun rolls back the transaction of a dry-run submission or replay.
+var errClaimDryRun = errors.New("claim dry run")
+
 const (
-	// FraudAmountMultiplier flags a claim when requested amount exceeds procedure average cost by this factor.
-	FraudAmountMultiplier = 2.0
+	// FraudAmountMultiplier flags a claim when requested amount exceeds the expected price
+	// (the provider's tariff, or the procedure average cost without one) by this factor.
+	FraudAmountMultiplier = 2
+
+	benefitExhaustedReason = "Benefit limit exhausted"
 )
 
 type ClaimService interface {
@@ -23,17 +38,23 @@ type ClaimService interface {
 	GetClaims(ctx context.Context, dB db.DB, memberID string, filter *models.Filter) (*models.ClaimList, error)
 	DeleteClaim(ctx context.Context, dB db.DB, claimID int64) error
 	SubmitClaim(ctx context.Context, dB db.DB, form *dtos.ClaimSubmissionForm) (*dtos.ClaimSubmissionResponse, error)
+	ReplayClaims(ctx context.Context, dB db.DB, from, to time.Time) (*dtos.ClaimReplayReport, error)
 }
 
 type claimService struct {
-	store *domain.Store
+	store      *domain.Store
+	filingDays int
 }
 
+// NewClaimService builds the claims service. Claims filed more than filingDays after the date of
+// service (or discharge) are rejected; 0 disables the timely-filing rule.
 func NewClaimService(
 	store *domain.Store,
+	filingDays int,
 ) ClaimService {
 	return &claimService{
-		store: store,
+		store:      store,
+		filingDays: filingDays,
 	}
 }
 
@@ -42,120 +63,424 @@ func (s *claimService) SubmitClaim(
 	dB db.DB,
 	form *dtos.ClaimSubmissionForm,
 ) (*dtos.ClaimSubmissionResponse, error) {
+	ctx, span := tracing.Start(ctx, "ClaimService.SubmitClaim")
+	defer span.End()
+
+	form.MembershipNumber = strings.TrimSpace(form.MembershipNumber)
+	form.Currency = strings.ToUpper(strings.TrimSpace(form.Currency))
+	if form.Currency != "" && !money.IsCurrency(form.Currency) {
+		return nil, apperr.NewBadRequest("currency must be one of " + strings.Join(money.Currencies, ", "))
+	}
+	if !form.RequestedAmount.FitsColumn() {
+		return nil, amountOutOfRange("requested_amount")
+	}
+	if form.MemberID == 0 && form.MembershipNumber == "" {
+		return nil, apperr.NewBadRequest("member_id or membership_number is required")
+	}
+	if form.MemberID != 0 && form.MembershipNumber != "" {
+		return nil, apperr.NewBadRequest("give member_id or membership_number, not both")
+	}
+
+	start := time.Now()
+	submittedOn := today()
+	dates, err := parseClaimDates(form, submittedOn)
+	if err != nil {
+		return nil, err
+	}
 
 	var result *dtos.ClaimSubmissionResponse
-	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+	err = dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
 		var err error
-		result, err = s.submitClaimInTx(ctx, ops, form)
-		return err
+		result, err = s.submitClaimInTx(ctx, ops, form, dates, submittedOn)
+		if err != nil {
+			return err
+		}
+
+		// a dry run always rolls back, so only the decision is returned
+		if form.DryRun {
+			return errClaimDryRun
+		}
+		return nil
 	})
+	if errors.Is(err, errClaimDryRun) {
+		result.ClaimID = 0
+		result.DryRun = true
+		return result, nil
+	}
 	if err != nil {
 		return nil, err
 	}
+	tracing.SetAttributes(ctx, tracing.ClaimID(result.ClaimID), tracing.MemberID(result.MemberID))
+
+	metrics.ObserveClaim(
+		result.Status,
+		result.FraudFlag,
+		result.RejectionReason == benefitExhaustedReason,
+		time.Since(start),
+	)
 	return result, nil
 }
 
+// ReplayClaims re-adjudicates the claims submitted between from and to (inclusive) under the
+// current rules and reports every claim whose decision would change. Member balances are wound
+// back to before the first replayed claim and the claims are replayed in submission order, each
+// filed as of its original submission date. Nothing is saved: the transaction is always rolled back.
+func (s *claimService) ReplayClaims(
+	ctx context.Context,
+	dB db.DB,
+	from, to time.Time,
+) (*dtos.ClaimReplayReport, error) {
+	ctx, span := tracing.Start(ctx, "ClaimService.ReplayClaims")
+	defer span.End()
+
+	if to.Before(from) {
+		return nil, apperr.NewBadRequest("to cannot be before from")
+	}
+
+	report := &dtos.ClaimReplayReport{
+		From:                   from.Format("2006-01-02"),
+		To:                     to.Format("2006-01-02"),
+		OriginalApprovedAmount: money.Totals{},
+		ReplayedApprovedAmount: money.Totals{},
+		Differences:            make([]*dtos.ClaimReplayDifference, 0),
+	}
+
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		claims, err := s.store.ClaimDomain.GetClaimsSubmittedBetween(ctx, ops, from, to)
+		if err != nil {
+			return err
+		}
+		if len(claims) == 0 {
+			return errClaimDryRun
+		}
+
+		err = s.store.MemberDomain.ReleaseUsedAmountsFromClaim(ctx, ops, claims[0].ID)
+		if err != nil {
+			return err
+		}
+
+		for _, claim := range claims {
+			form := &dtos.ClaimSubmissionForm{
+				MemberID:        claim.MemberID,
+				ProviderID:      claim.ProviderID,
+				ProcedureCode:   claim.ProcedureCode,
+				DiagnosisCode:   claim.DiagnosisCode,
+				RequestedAmount: claim.RequestedAmount,
+				Currency:        claim.Currency,
+			}
+			dates := &claimDates{
+				ServiceDate:   claim.ServiceDate,
+				AdmissionDate: claim.AdmissionDate,
+				DischargeDate: claim.DischargeDate,
+			}
+			submittedOn := claim.CreatedAt.UTC().Truncate(24 * time.Hour)
+
+			result, err := s.submitClaimInTx(ctx, ops, form, dates, submittedOn)
+			if err != nil {
+				return err
+			}
+
+			report.Replayed++
+			report.OriginalApprovedAmount.Add(claim.ApprovedAmount)
+			report.ReplayedApprovedAmount.Add(result.ApprovedAmount)
+
+			if result.Status == string(claim.Status) &&
+				result.ApprovedAmount == claim.ApprovedAmount &&
+				result.FraudFlag == claim.FraudFlag {
+				report.Unchanged++
+				continue
+			}
+
+			report.Differences = append(report.Differences, &dtos.ClaimReplayDifference{
+				ClaimID:                claim.ID,
+				OriginalStatus:         string(claim.Status),
+				ReplayedStatus:         result.Status,
+				OriginalApprovedAmount: claim.ApprovedAmount,
+				ReplayedApprovedAmount: result.ApprovedAmount,
+				OriginalFraudFlag:      claim.FraudFlag,
+				ReplayedFraudFlag:      result.FraudFlag,
+				ReplayedReason:         result.RejectionReason,
+			})
+		}
+
+		return errClaimDryRun
+	})
+	if err != nil && !errors.Is(err, errClaimDryRun) {
+		return nil, err
+	}
+
+	return report, nil
+}
+
 func (s *claimService) submitClaimInTx(
 	ctx context.Context, 
 	ops db.SQLOperations, 
 	form *dtos.ClaimSubmissionForm,
+	dates *claimDates,
+	submittedOn time.Time,
 	) (*dtos.ClaimSubmissionResponse, error) {
 
+	ctx, span := tracing.Start(ctx, "ClaimService.adjudicate")
+	defer span.End()
+
+	serviceDate := dates.ServiceDate
+	stages := metrics.NewStageTimer()
+
+	// hospitals can identify the member by membership number; an unknown number leaves the
+	// member unset and the claim is rejected below
+	if form.MembershipNumber != "" {
+		member, err := s.store.MemberDomain.GetMemberByMembershipNumber(ctx, ops, form.MembershipNumber)
+		if err == nil {
+			form.MemberID = member.ID
+		}
+	}
+
+	// the member stays locked until the claim commits, so concurrent claims and member updates
+	// cannot overwrite each other's used amount
+	member, err := s.store.MemberDomain.GetMemberByIDForUpdate(ctx, ops, form.MemberID)
+	if err != nil {
+		member = nil
+	}
+	if member != nil {
+		ctx = tracing.SetAttributes(ctx, tracing.MemberID(member.ID))
+	}
+
+	// a claim billed without a currency is in the member's benefit currency
+	if form.Currency == "" {
+		form.Currency = money.DefaultCurrency
+		if member != nil {
+			form.Currency = member.BenefitCurrency
+		}
+	}
+	form.RequestedAmount.Currency = form.Currency
+
 	// validate member eligibility
-	member, err := s.store.MemberDomain.GetMemberByID(ctx, ops, form.MemberID)
-	if err != nil || member == nil {
-		return s.persistRejectedClaim(ctx, ops, form, "Member not found", false)
+	if member == nil {
+		rejected := *form
+		rejected.MemberID = 0
+		return s.persistRejectedClaim(ctx, ops, &rejected, dates, "Member not found", false)
 	}
 	if !member.IsActive {
-		return s.persistRejectedClaim(ctx, ops, form, "Member is not active", false)
+		return s.persistRejectedClaim(ctx, ops, form, dates, "Member is not active", false)
+	}
+	stages.Done("eligibility")
+
+	// validate provider is registered, active and accredited
+	provider, err := s.store.ProviderDomain.GetProviderByID(ctx, ops, form.ProviderID)
+	if err != nil || provider == nil {
+		rejected := *form
+		rejected.ProviderID = 0
+		return s.persistRejectedClaim(ctx, ops, &rejected, dates, "Provider not found", false)
 	}
+	if provider.Status != custom_types.ProviderStatusActive {
+		return s.persistRejectedClaim(ctx, ops, form, dates, "Provider is suspended", false)
+	}
+	if !provider.IsAccredited(serviceDate) {
+		return s.persistRejectedClaim(ctx, ops, form, dates, "Provider accreditation has expired", false)
+	}
+	stages.Done("provider")
 
 	// validate procedure exists
 	procedure, err := s.store.ProcedureDomain.GetProcedureByCode(ctx, ops, form.ProcedureCode)
 	if err != nil || procedure == nil {
-		return s.persistRejectedClaim(ctx, ops, form, "Invalid or unknown procedure code", false)
+		rejected := *form
+		rejected.ProcedureCode = ""
+		return s.persistRejectedClaim(ctx, ops, &rejected, dates, "Invalid or unknown procedure code", false)
+	}
+
+	// price the claim with the procedure version in effect on the service date
+	version, err := s.store.ProcedureVersionDomain.GetProcedureVersionOn(ctx, ops, procedure.Code, serviceDate)
+	if err != nil {
+		if !apperr.IsNoRowsErr(err) {
+			return nil, err
+		}
+		return s.persistRejectedClaim(ctx, ops, form, dates, "Procedure has no price in effect on the service date", false)
+	}
+
+	// timely filing: late submissions are rejected. The check follows the member, provider and
+	// procedure checks so the rejected claim only references rows that exist.
+	if s.filingDays > 0 && submittedOn.After(dates.filingStart().AddDate(0, 0, s.filingDays)) {
+		reason := fmt.Sprintf("Claim submitted after the %d day filing limit", s.filingDays)
+		return s.persistRejectedClaim(ctx, ops, form, dates, reason, false)
+	}
+
+	// a negotiated tariff replaces the procedure average cost as the expected price
+	tariff, err := tariffPriceOn(ctx, s.store, ops, provider.ID, procedure.Code, serviceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedPrice := version.AverageCost
+	if tariff != nil {
+		expectedPrice = tariff.AgreedPrice
+	}
+	stages.Done("pricing")
+
+	// convert the bill and the expected price into the member's benefit currency at the rates on
+	// the service date
+	rate, err := exchangeRateOn(ctx, s.store, ops, form.Currency, member.BenefitCurrency, serviceDate)
+	if err != nil {
+		return nil, err
+	}
+	if !rate.IsValid() {
+		return s.persistRejectedClaim(ctx, ops, form, dates, noExchangeRateReason(form.Currency, member.BenefitCurrency), false)
+	}
+	requestedAmount, err := form.RequestedAmount.Convert(rate, member.BenefitCurrency)
+	if err != nil || !requestedAmount.FitsColumn() {
+		return s.persistRejectedClaim(ctx, ops, form, dates, convertedOutOfRangeReason(member.BenefitCurrency), false)
+	}
+
+	priceRate, err := exchangeRateOn(ctx, s.store, ops, expectedPrice.Currency, member.BenefitCurrency, serviceDate)
+	if err != nil {
+		return nil, err
+	}
+	if !priceRate.IsValid() {
+		return s.persistRejectedClaim(ctx, ops, form, dates, noExchangeRateReason(expectedPrice.Currency, member.BenefitCurrency), false)
+	}
+	expectedPrice, err = expectedPrice.Convert(priceRate, member.BenefitCurrency)
+	if err != nil || !expectedPrice.FitsColumn() {
+		return s.persistRejectedClaim(ctx, ops, form, dates, convertedOutOfRangeReason(member.BenefitCurrency), false)
 	}
 
-	// fraud signal: requested amount significantly above procedure average cost
-	fraudFlag := form.RequestedAmount > (procedure.AverageCost * FraudAmountMultiplier)
+	var tariffPrice *money.Money
+	if tariff != nil {
+		tariffPrice = &expectedPrice
+	}
+	stages.Done("conversion")
+
+	// fraud signal: requested amount significantly above expected price
+	fraudFlag := requestedAmount.GreaterThan(expectedPrice.Mul(FraudAmountMultiplier))
+
+	conversion := &claimConversion{
+		BenefitCurrency: member.BenefitCurrency,
+		ExchangeRate:    rate,
+		ConvertedAmount: requestedAmount,
+	}
+	stages.Done("fraud")
 
 	// check remaining benefit
-	remaining := member.BenefitLimit - member.UsedAmount
-	if remaining <= 0 {
-		return s.persistRejectedClaim(ctx, ops, form, "Benefit limit exhausted", fraudFlag)
+	remaining := member.BenefitLimit.Sub(member.UsedAmount)
+	if !remaining.IsPositive() {
+		return s.persistConvertedRejectedClaim(ctx, ops, form, dates, conversion, benefitExhaustedReason, fraudFlag)
 	}
 
 	// determine status and approved amount
-	var status custom_types.ClaimStatus
-	var approvedAmount float64
-	var rejectionReason string
-
-	if form.RequestedAmount <= remaining {
-		status = custom_types.ClaimStatus("APPROVED")
-		approvedAmount = form.RequestedAmount
-	} else {
-		status = custom_types.ClaimStatus("PARTIAL")
-		approvedAmount = remaining
-		rejectionReason = "Requested amount exceeds remaining benefit; approved up to remaining limit."
-	}
+	approvedAmount, status, rejectionReason := projectApprovedAmount(requestedAmount, tariffPrice, remaining)
 
 	// update member used amount
-	member.UsedAmount += approvedAmount
+	member.UsedAmount = member.UsedAmount.Add(approvedAmount)
 	err = s.store.MemberDomain.CreateMember(ctx, ops, member)
 	if err != nil {
 		return nil, err
 	}
+	stages.Done("benefit")
 
 	// persist the claim
 	claim := &models.Claim{
-		MemberID:        form.MemberID,
-		ProviderID:      form.ProviderID,
-		ProcedureCode:   form.ProcedureCode,
-		DiagnosisCode:   form.DiagnosisCode,
-		RequestedAmount: form.RequestedAmount,
-		ApprovedAmount:  approvedAmount,
-		Status:          status,
-		FraudFlag:       fraudFlag,
-		RejectionReason: rejectionReason,
+		MemberID:             form.MemberID,
+		ProviderID:           form.ProviderID,
+		ProcedureCode:        form.ProcedureCode,
+		DiagnosisCode:        form.DiagnosisCode,
+		RequestedAmount:      form.RequestedAmount,
+		Currency:             form.Currency,
+		ExchangeRate:         conversion.ExchangeRate,
+		ConvertedAmount:      conversion.ConvertedAmount,
+		ApprovedAmount:       approvedAmount,
+		BenefitCurrency:      conversion.BenefitCurrency,
+		Status:               status,
+		FraudFlag:            fraudFlag,
+		RejectionReason:      rejectionReason,
+		ProcedureVersionID:   version.ID,
+		ServiceDate:          dates.ServiceDate,
+		AdmissionDate:        dates.AdmissionDate,
+		DischargeDate:        dates.DischargeDate,
+		X12SenderID:          form.X12SenderID,
+		PatientControlNumber: form.PatientControlNumber,
 	}
 	err = s.store.ClaimDomain.CreateClaim(ctx, ops, claim)
 	if err != nil {
 		return nil, err
 	}
+	tracing.SetAttributes(ctx, tracing.ClaimID(claim.ID))
+	stages.Done("persist")
 
 	return &dtos.ClaimSubmissionResponse{
 		ClaimID:         claim.ID,
+		MemberID:        claim.MemberID,
 		Status:          string(status),
 		ApprovedAmount:  approvedAmount,
+		BenefitCurrency: claim.BenefitCurrency,
+		ExchangeRate:    claim.ExchangeRate,
 		RejectionReason: rejectionReason,
 		FraudFlag:       fraudFlag,
 	}, nil
 }
 
+// claimConversion is how a claim's requested amount was converted into the benefit currency.
+type claimConversion struct {
+	BenefitCurrency string
+	ExchangeRate    money.Rate
+	ConvertedAmount money.Money
+}
+
+// persistRejectedClaim saves a claim rejected before its amount could be converted; it is
+// recorded in the currency it was billed in.
 func (s *claimService) persistRejectedClaim(
 	ctx context.Context,
 	ops db.SQLOperations, form *dtos.ClaimSubmissionForm,
+	dates *claimDates,
+	reason string,
+	fraudFlag bool,
+) (*dtos.ClaimSubmissionResponse, error) {
+	conversion := &claimConversion{
+		BenefitCurrency: form.RequestedAmount.Currency,
+		ExchangeRate:    money.OneRate(),
+		ConvertedAmount: form.RequestedAmount,
+	}
+	return s.persistConvertedRejectedClaim(ctx, ops, form, dates, conversion, reason, fraudFlag)
+}
+
+func (s *claimService) persistConvertedRejectedClaim(
+	ctx context.Context,
+	ops db.SQLOperations, form *dtos.ClaimSubmissionForm,
+	dates *claimDates,
+	conversion *claimConversion,
 	reason string,
 	fraudFlag bool,
 ) (*dtos.ClaimSubmissionResponse, error) {
 	claim := &models.Claim{
-		MemberID:        form.MemberID,
-		ProviderID:      form.ProviderID,
-		ProcedureCode:   form.ProcedureCode,
-		DiagnosisCode:   form.DiagnosisCode,
-		RequestedAmount: form.RequestedAmount,
-		ApprovedAmount:  0,
-		Status:          custom_types.ClaimStatus("REJECTED"),
-		FraudFlag:       fraudFlag,
-		RejectionReason: reason,
+		MemberID:             form.MemberID,
+		ProviderID:           form.ProviderID,
+		ProcedureCode:        form.ProcedureCode,
+		DiagnosisCode:        form.DiagnosisCode,
+		RequestedAmount:      form.RequestedAmount,
+		Currency:             form.RequestedAmount.Currency,
+		ExchangeRate:         conversion.ExchangeRate,
+		ConvertedAmount:      conversion.ConvertedAmount,
+		ApprovedAmount:       money.New(0, conversion.BenefitCurrency),
+		BenefitCurrency:      conversion.BenefitCurrency,
+		Status:               custom_types.ClaimStatus("REJECTED"),
+		FraudFlag:            fraudFlag,
+		RejectionReason:      reason,
+		ServiceDate:          dates.ServiceDate,
+		AdmissionDate:        dates.AdmissionDate,
+		DischargeDate:        dates.DischargeDate,
+		X12SenderID:          form.X12SenderID,
+		PatientControlNumber: form.PatientControlNumber,
 	}
 	if err := s.store.ClaimDomain.CreateClaim(ctx, ops, claim); err != nil {
 		return nil, err
 	}
+	tracing.SetAttributes(ctx, tracing.ClaimID(claim.ID))
+
 	return &dtos.ClaimSubmissionResponse{
 		ClaimID:         claim.ID,
+		MemberID:        claim.MemberID,
 		Status:          "REJECTED",
-		ApprovedAmount:  0,
+		ApprovedAmount:  claim.ApprovedAmount,
+		BenefitCurrency: claim.BenefitCurrency,
+		ExchangeRate:    claim.ExchangeRate,
 		RejectionReason: reason,
 		FraudFlag:       fraudFlag,
 	}, nil
@@ -166,19 +491,47 @@ func (s *claimService) CreateClaim(
 	dB db.DB, 
 	form *dtos.ClaimSubmissionForm,
 	) (*models.Claim, error) {
+	ctx, span := tracing.Start(ctx, "ClaimService.CreateClaim")
+	defer span.End()
+
+	dates, err := parseClaimDates(form, today())
+	if err != nil {
+		return nil, err
+	}
+
+	// a pending claim is converted when it is adjudicated
+	currency := strings.ToUpper(strings.TrimSpace(form.Currency))
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	if !money.IsCurrency(currency) {
+		return nil, apperr.NewBadRequest("currency must be one of " + strings.Join(money.Currencies, ", "))
+	}
+	if !form.RequestedAmount.FitsColumn() {
+		return nil, amountOutOfRange("requested_amount")
+	}
+	requestedAmount := money.New(form.RequestedAmount.Amount, currency)
+
 	claim := &models.Claim{
 		MemberID:        form.MemberID,
 		ProviderID:      form.ProviderID,
 		ProcedureCode:   form.ProcedureCode,
 		DiagnosisCode:   form.DiagnosisCode,
-		RequestedAmount: form.RequestedAmount,
-		ApprovedAmount:  0,
+		RequestedAmount: requestedAmount,
+		Currency:        currency,
+		ExchangeRate:    money.OneRate(),
+		ConvertedAmount: requestedAmount,
+		ApprovedAmount:  money.New(0, currency),
+		BenefitCurrency: currency,
 		Status:          custom_types.ClaimStatus("PENDING"),
 		FraudFlag:       false,
 		RejectionReason: "",
+		ServiceDate:     dates.ServiceDate,
+		AdmissionDate:   dates.AdmissionDate,
+		DischargeDate:   dates.DischargeDate,
 	}
 	
-	err := s.store.ClaimDomain.CreateClaim(ctx, dB, claim)
+	err = s.store.ClaimDomain.CreateClaim(ctx, dB, claim)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +544,8 @@ func (s *claimService) GetClaimByID(
 	dB db.DB, 
 	id int64,
 	) (*models.Claim, error) {
+	ctx, span := tracing.Start(ctx, "ClaimService.GetClaimByID", tracing.ClaimID(id))
+	defer span.End()
 
 	return s.store.ClaimDomain.GetClaimByID(ctx, dB, id)
 }
@@ -200,6 +555,9 @@ func (s *claimService) GetClaimByMemberID(
 	dB db.DB, 
 	memberID string,
 	) (*models.Claim, error) {
+	ctx, span := tracing.Start(ctx, "ClaimService.GetClaimByMemberID", tracing.MemberIDText(memberID))
+	defer span.End()
+
 	return s.store.ClaimDomain.GetClaimByMemberID(ctx, dB, memberID)
 }
 
@@ -208,6 +566,8 @@ func (s *claimService) GetClaimByProviderID(
 	dB db.DB, 
 	providerID string,
 	) (*models.Claim, error) {
+	ctx, span := tracing.Start(ctx, "ClaimService.GetClaimByProviderID")
+	defer span.End()
 		
 	return s.store.ClaimDomain.GetClaimByProviderID(ctx, dB, providerID)
 }
@@ -218,6 +578,8 @@ func (s *claimService) GetClaims(
 	memberID string, 
 	filter *models.Filter,
 	) (*models.ClaimList, error) {
+	ctx, span := tracing.Start(ctx, "ClaimService.GetClaims", tracing.MemberIDText(memberID))
+	defer span.End()
 		
 	claims, err := s.store.ClaimDomain.GetClaims(ctx, dB, memberID, filter)
 	if err != nil {
@@ -236,6 +598,114 @@ func (s *claimService) GetClaims(
 	return claimList, nil
 }
 
+// DeleteClaim refuses claims held by a payment batch so batch totals stay in step with their claims.
 func (s *claimService) DeleteClaim(ctx context.Context, dB db.DB, claimID int64) error {
+	ctx, span := tracing.Start(ctx, "ClaimService.DeleteClaim", tracing.ClaimID(claimID))
+	defer span.End()
+
+	claim, err := s.store.ClaimDomain.GetClaimByID(ctx, dB, claimID)
+	if err != nil {
+		return err
+	}
+
+	if claim.PaymentBatchID != 0 {
+		return apperr.NewBadRequest(fmt.Sprintf("claim is in payment batch [%v]", claim.PaymentBatchID))
+	}
+
 	return s.store.ClaimDomain.DeleteClaim(ctx, dB, claimID)
 }
+
+// claimDates are the parsed dates of a claim submission.
+type claimDates struct {
+	ServiceDate   time.Time
+	AdmissionDate *time.Time
+	DischargeDate *time.Time
+}
+
+// filingStart is the date the timely-filing period runs from: discharge for inpatient claims,
+// otherwise the date of service.
+func (d *claimDates) filingStart() time.Time {
+	if d.DischargeDate != nil {
+		return *d.DischargeDate
+	}
+	return d.ServiceDate
+}
+
+// parseClaimDates validates the claim dates: none may be after today, discharge needs an
+// admission date, and the service date must fall within the stay.
+func parseClaimDates(form *dtos.ClaimSubmissionForm, today time.Time) (*claimDates, error) {
+	serviceDate, err := utils.ParseDate(strings.TrimSpace(form.ServiceDate))
+	if err != nil {
+		return nil, apperr.NewBadRequest("service_date: " + err.Error())
+	}
+
+	admissionDate, err := parseOptionalDate(form.AdmissionDate)
+	if err != nil {
+		return nil, apperr.NewBadRequest("admission_date: " + err.Error())
+	}
+
+	dischargeDate, err := parseOptionalDate(form.DischargeDate)
+	if err != nil {
+		return nil, apperr.NewBadRequest("discharge_date: " + err.Error())
+	}
+
+	dates := &claimDates{
+		ServiceDate:   serviceDate,
+		AdmissionDate: admissionDate,
+		DischargeDate: dischargeDate,
+	}
+
+	if serviceDate.After(today) {
+		return nil, apperr.NewBadRequest("service_date cannot be in the future")
+	}
+	if admissionDate != nil && admissionDate.After(today) {
+		return nil, apperr.NewBadRequest("admission_date cannot be in the future")
+	}
+	if dischargeDate != nil && dischargeDate.After(today) {
+		return nil, apperr.NewBadRequest("discharge_date cannot be in the future")
+	}
+
+	if dischargeDate != nil && admissionDate == nil {
+		return nil, apperr.NewBadRequest("discharge_date requires admission_date")
+	}
+	if admissionDate != nil && dischargeDate != nil && dischargeDate.Before(*admissionDate) {
+		return nil, apperr.NewBadRequest("discharge_date cannot be before admission_date")
+	}
+	if admissionDate != nil && serviceDate.Before(*admissionDate) {
+		return nil, apperr.NewBadRequest("service_date cannot be before admission_date")
+	}
+	if dischargeDate != nil && serviceDate.After(*dischargeDate) {
+		return nil, apperr.NewBadRequest("service_date cannot be after discharge_date")
+	}
+
+	return dates, nil
+}
+
+// projectApprovedAmount caps the requested amount at the tariff price, if any, and the remaining
+// benefit, and returns the amount, the resulting status and the reason for any reduction. All
+// amounts are in the benefit currency.
+func projectApprovedAmount(
+	requestedAmount money.Money,
+	tariffPrice *money.Money,
+	remaining money.Money,
+) (money.Money, custom_types.ClaimStatus, string) {
+
+	var reason string
+
+	payableAmount := requestedAmount
+	if tariffPrice != nil && payableAmount.GreaterThan(*tariffPrice) {
+		payableAmount = *tariffPrice
+		reason = "Requested amount exceeds agreed tariff; approved up to tariff price."
+	}
+
+	approvedAmount := payableAmount
+	if payableAmount.GreaterThan(remaining) {
+		approvedAmount = remaining
+		reason = "Requested amount exceeds remaining benefit; approved up to remaining limit."
+	}
+
+	if approvedAmount.Cmp(requestedAmount) == 0 {
+		return approvedAmount, custom_types.ClaimStatus("APPROVED"), reason
+	}
+	return approvedAmount, custom_types.ClaimStatus("PARTIAL"), reason
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+// positiveAmount is a generated amount between 0.01 and 1,000,000.00.
+type positiveAmount struct {
+	money.Money
+}
+
+func (positiveAmount) Generate(r *rand.Rand, _ int) reflect.Value {
+	return reflect.ValueOf(positiveAmount{money.FromMinor(r.Int63n(100_000_000) + 1)})
+}
+
+func TestProjectApprovedAmountNeverExceedsItsCaps(t *testing.T) {
+	property := func(requested, agreedPrice, remaining positiveAmount, withTariff bool) bool {
+		var tariffPrice *money.Money
+		if withTariff {
+			tariffPrice = &agreedPrice.Money
+		}
+
+		approved, status, reason := projectApprovedAmount(requested.Money, tariffPrice, remaining.Money)
+
+		if approved.GreaterThan(requested.Money) || approved.GreaterThan(remaining.Money) || !approved.IsPositive() {
+			return false
+		}
+		if tariffPrice != nil && approved.GreaterThan(*tariffPrice) {
+			return false
+		}
+		if approved == requested.Money {
+			return status == "APPROVED" && reason == ""
+		}
+		return status == "PARTIAL" && reason != ""
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 5000}); err != nil {
+		t.Error(err)
+	}
+}
+
+// Approving claims against a benefit until it runs out must use it up exactly, with nothing
+// left over or overdrawn however the claims are sized.
+func TestBenefitIsUsedUpExactly(t *testing.T) {
+	property := func(limit positiveAmount, requests []positiveAmount) bool {
+		used := money.FromMinor(0)
+		for _, requested := range requests {
+			remaining := limit.Sub(used)
+			if !remaining.IsPositive() {
+				break
+			}
+			approved, _, _ := projectApprovedAmount(requested.Money, nil, remaining)
+			used = used.Add(approved)
+		}
+
+		total := money.FromMinor(0)
+		for _, requested := range requests {
+			total = total.Add(requested.Money)
+		}
+		return used == money.Min(limit.Money, total)
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+const (
+	// DefaultCostWindowDays is the rolling window of claims sampled when recomputing costs.
+	DefaultCostWindowDays = 180
+	// DefaultCostMinSamples is the fewest claims a procedure needs before its cost is recomputed.
+	DefaultCostMinSamples = 10
+	// CostTrimFraction is the share of samples dropped from each end for the trimmed mean.
+	CostTrimFraction = 0.1
+)
+
+type CostRunService interface {
+	PreviewCostRun(ctx context.Context, dB db.DB, form *dtos.CostRunRequest) (*models.CostRun, error)
+	CreateCostRun(ctx context.Context, dB db.DB, form *dtos.CostRunRequest) (*models.CostRun, error)
+	GetCostRunByID(ctx context.Context, dB db.DB, id int64) (*models.CostRun, error)
+	GetCostRuns(ctx context.Context, dB db.DB, filter *models.Filter) (*models.CostRunList, error)
+	ApproveCostRun(ctx context.Context, dB db.DB, id int64, reviewedBy string) (*models.CostRun, error)
+	RejectCostRun(ctx context.Context, dB db.DB, id int64, reviewedBy string) (*models.CostRun, error)
+}
+
+type costRunService struct {
+	store *domain.Store
+}
+
+func NewCostRunService(store *domain.Store) CostRunService {
+	return &costRunService{store: store}
+}
+
+// PreviewCostRun computes the proposed costs without saving anything.
+func (s *costRunService) PreviewCostRun(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.CostRunRequest,
+) (*models.CostRun, error) {
+	ctx, span := tracing.Start(ctx, "CostRunService.PreviewCostRun")
+	defer span.End()
+
+	run, err := newCostRun(form)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Proposals, err = s.computeProposals(ctx, dB, run)
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// CreateCostRun computes the proposed costs and saves them as a pending run for review. Only one run
+// may be pending at a time: while one is, creating another is a conflict, so the same proposals are
+// never queued, and approved, twice.
+func (s *costRunService) CreateCostRun(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.CostRunRequest,
+) (*models.CostRun, error) {
+	ctx, span := tracing.Start(ctx, "CostRunService.CreateCostRun")
+	defer span.End()
+
+	run, err := newCostRun(form)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		// the lock is held until commit, so two runs created at once cannot both find none pending
+		if err := s.store.CostRunDomain.LockCostRuns(ctx, ops); err != nil {
+			return err
+		}
+
+		pending, err := s.store.CostRunDomain.GetPendingCostRun(ctx, ops)
+		if err == nil {
+			return apperr.NewErrorWithType(
+				fmt.Errorf("cost run %d is still pending review; approve or reject it first", pending.ID),
+				apperr.Conflict,
+			)
+		}
+		if !apperr.IsNoRowsErr(err) {
+			return err
+		}
+
+		proposals, err := s.computeProposals(ctx, ops, run)
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.CostRunDomain.CreateCostRun(ctx, ops, run); err != nil {
+			return err
+		}
+
+		for _, proposal := range proposals {
+			proposal.RunID = run.ID
+			if err := s.store.CostProposalDomain.CreateCostProposal(ctx, ops, proposal); err != nil {
+				return err
+			}
+		}
+
+		run.Proposals = proposals
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (s *costRunService) GetCostRunByID(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) (*models.CostRun, error) {
+	ctx, span := tracing.Start(ctx, "CostRunService.GetCostRunByID")
+	defer span.End()
+
+	run, err := s.store.CostRunDomain.GetCostRunByID(ctx, dB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Proposals, err = s.store.CostProposalDomain.GetCostProposalsByRunID(ctx, dB, run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (s *costRunService) GetCostRuns(
+	ctx context.Context,
+	dB db.DB,
+	filter *models.Filter,
+) (*models.CostRunList, error) {
+	ctx, span := tracing.Start(ctx, "CostRunService.GetCostRuns")
+	defer span.End()
+
+	runs, err := s.store.CostRunDomain.GetCostRuns(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.CostRunDomain.GetCostRunsCount(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CostRunList{
+		Runs:       runs,
+		Pagination: models.NewPagination(count, filter.Page, filter.Per),
+	}, nil
+}
+
+// ApproveCostRun adds each proposed cost as a new procedure price version effective from the day
+// after approval, so claims already priced today keep their price and the old cost stays in history.
+func (s *costRunService) ApproveCostRun(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+	reviewedBy string,
+) (*models.CostRun, error) {
+	ctx, span := tracing.Start(ctx, "CostRunService.ApproveCostRun")
+	defer span.End()
+
+	var run *models.CostRun
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+		var err error
+		run, err = s.reviewCostRun(ctx, ops, id, reviewedBy, custom_types.CostRunStatusApproved)
+		if err != nil {
+			return err
+		}
+
+		effectiveFrom := today().AddDate(0, 0, 1)
+		for _, proposal := range run.Proposals {
+			procedure, err := s.store.ProcedureDomain.GetProcedureByID(ctx, ops, proposal.ProcedureID)
+			if err != nil {
+				return err
+			}
+
+			_, err = appendProcedureVersion(ctx, s.store, ops, procedure, proposal.ProposedCost, effectiveFrom)
+			if err != nil {
+				var appErr *apperr.Error
+				if errors.As(err, &appErr) && appErr.Type == apperr.BadRequest {
+					return apperr.NewBadRequest(fmt.Sprintf("procedure [%v]: %v", procedure.Code, appErr.Message))
+				}
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (s *costRunService) RejectCostRun(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+	reviewedBy string,
+) (*models.CostRun, error) {
+	ctx, span := tracing.Start(ctx, "CostRunService.RejectCostRun")
+	defer span.End()
+
+	var run *models.CostRun
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+		var err error
+		run, err = s.reviewCostRun(ctx, ops, id, reviewedBy, custom_types.CostRunStatusRejected)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (s *costRunService) reviewCostRun(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+	reviewedBy string,
+	status custom_types.CostRunStatus,
+) (*models.CostRun, error) {
+
+	run, err := s.store.CostRunDomain.GetCostRunByIDForUpdate(ctx, operations, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if run.Status != custom_types.CostRunStatusPending {
+		return nil, apperr.NewBadRequest(fmt.Sprintf("cost run is already %v", run.Status))
+	}
+
+	now := time.Now()
+	run.Status = status
+	run.ReviewedBy = reviewedBy
+	run.ReviewedAt = &now
+	if err := s.store.CostRunDomain.CreateCostRun(ctx, operations, run); err != nil {
+		return nil, err
+	}
+
+	run.Proposals, err = s.store.CostProposalDomain.GetCostProposalsByRunID(ctx, operations, run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// computeProposals samples approved, non-flagged claims in the run's window and proposes a new
+// cost for every procedure with enough samples whose cost would change. Only claims approved in
+// the procedure's own currency are sampled.
+func (s *costRunService) computeProposals(
+	ctx context.Context,
+	operations db.SQLOperations,
+	run *models.CostRun,
+) ([]*models.CostProposal, error) {
+
+	since := time.Now().AddDate(0, 0, -run.WindowDays)
+	amounts, err := s.store.ClaimDomain.GetApprovedClaimAmounts(ctx, operations, since)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make(map[string][]money.Money)
+	for _, amount := range amounts {
+		samples[amount.ProcedureCode] = append(samples[amount.ProcedureCode], amount.Amount)
+	}
+
+	codes := make([]string, 0, len(samples))
+	for code := range samples {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	proposals := make([]*models.CostProposal, 0)
+	for _, code := range codes {
+		if len(samples[code]) < run.MinSamples {
+			continue
+		}
+
+		procedure, err := s.store.ProcedureDomain.GetProcedureByCode(ctx, operations, code)
+		if err != nil {
+			if apperr.IsNoRowsErr(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		// the statistics run on minor units, so the proposed cost is rounded once
+		values := make([]int64, 0, len(samples[code]))
+		for _, amount := range samples[code] {
+			if amount.Currency == procedure.Currency {
+				values = append(values, amount.Amount)
+			}
+		}
+		if len(values) < run.MinSamples {
+			continue
+		}
+
+		var estimate int64
+		switch run.Method {
+		case custom_types.CostMethodTrimmedMean:
+			estimate = utils.TrimmedMean(values, CostTrimFraction)
+		default:
+			estimate = utils.Median(values)
+		}
+		proposedCost := money.New(estimate, procedure.AverageCost.Currency)
+
+		if proposedCost.Cmp(procedure.AverageCost) == 0 {
+			continue
+		}
+
+		proposals = append(proposals, &models.CostProposal{
+			ProcedureID:   procedure.ID,
+			ProcedureCode: procedure.Code,
+			CurrentCost:   procedure.AverageCost,
+			ProposedCost:  proposedCost,
+			Currency:      procedure.Currency,
+			SampleSize:    len(values),
+		})
+	}
+
+	return proposals, nil
+}
+
+func newCostRun(form *dtos.CostRunRequest) (*models.CostRun, error) {
+	run := &models.CostRun{
+		Method:     custom_types.CostMethodMedian,
+		WindowDays: DefaultCostWindowDays,
+		MinSamples: DefaultCostMinSamples,
+		Status:     custom_types.CostRunStatusPending,
+	}
+
+	if form.Method != "" {
+		run.Method = custom_types.CostMethod(form.Method)
+	}
+	if form.WindowDays != 0 {
+		run.WindowDays = form.WindowDays
+	}
+	if form.MinSamples != 0 {
+		run.MinSamples = form.MinSamples
+	}
+
+	if !run.Method.IsValid() {
+		return nil, apperr.NewBadRequest(fmt.Sprintf("invalid method [%v]", run.Method))
+	}
+	if run.WindowDays < 1 {
+		return nil, apperr.NewBadRequest("window_days must be at least 1")
+	}
+	if run.MinSamples < 1 {
+		return nil, apperr.NewBadRequest("min_samples must be at least 1")
+	}
+
+	return run, nil
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+type EligibilityService interface {
+	CheckEligibility(ctx context.Context, dB db.DB, memberID int64, form *dtos.EligibilityRequest) (*dtos.EligibilityResponse, error)
+}
+
+type eligibilityService struct {
+	store *domain.Store
+}
+
+func NewEligibilityService(store *domain.Store) EligibilityService {
+	return &eligibilityService{store: store}
+}
+
+// CheckEligibility runs the member, provider, procedure and benefit stages of the claims pipeline
+// read-only. Unlike a submission it does not stop at the first problem, so every blocking
+// reason is reported.
+func (s *eligibilityService) CheckEligibility(
+	ctx context.Context,
+	dB db.DB,
+	memberID int64,
+	form *dtos.EligibilityRequest,
+) (*dtos.EligibilityResponse, error) {
+	ctx, span := tracing.Start(ctx, "EligibilityService.CheckEligibility", tracing.MemberID(memberID))
+	defer span.End()
+
+	if form.Amount.IsNegative() {
+		return nil, apperr.NewBadRequest("amount cannot be negative")
+	}
+	if !form.Amount.FitsColumn() {
+		return nil, amountOutOfRange("amount")
+	}
+	currency := strings.ToUpper(strings.TrimSpace(form.Currency))
+	if currency != "" && !money.IsCurrency(currency) {
+		return nil, apperr.NewBadRequest("currency must be one of " + strings.Join(money.Currencies, ", "))
+	}
+
+	serviceDate := today()
+	if strings.TrimSpace(form.ServiceDate) != "" {
+		var err error
+		serviceDate, err = utils.ParseDate(strings.TrimSpace(form.ServiceDate))
+		if err != nil {
+			return nil, apperr.NewBadRequest("service_date: " + err.Error())
+		}
+	}
+
+	member, err := s.store.MemberDomain.GetMemberByID(ctx, dB, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.EligibilityResponse{
+		MemberID:         member.ID,
+		Active:           member.IsActive,
+		BenefitCurrency:  member.BenefitCurrency,
+		BenefitLimit:     member.BenefitLimit,
+		UsedAmount:       member.UsedAmount,
+		RemainingBenefit: member.BenefitLimit.Sub(member.UsedAmount),
+		BlockingReasons:  make([]string, 0),
+		Notes:            make([]string, 0),
+	}
+
+	// member eligibility
+	if !member.IsActive {
+		response.BlockingReasons = append(response.BlockingReasons, "Member is not active")
+	}
+
+	// provider, when given
+	var provider *models.Provider
+	if form.ProviderID != 0 {
+		provider, err = s.store.ProviderDomain.GetProviderByID(ctx, dB, form.ProviderID)
+		if err != nil {
+			if !apperr.IsNoRowsErr(err) {
+				return nil, err
+			}
+			provider = nil
+			response.BlockingReasons = append(response.BlockingReasons, "Provider not found")
+		} else if provider.Status != custom_types.ProviderStatusActive {
+			response.BlockingReasons = append(response.BlockingReasons, "Provider is suspended")
+		} else if !provider.IsAccredited(serviceDate) {
+			response.BlockingReasons = append(response.BlockingReasons, "Provider accreditation has expired")
+		}
+	}
+
+	// procedure and its price on the service date
+	var version *models.ProcedureVersion
+	var tariff *models.ProviderTariff
+	procedureCode := strings.TrimSpace(form.ProcedureCode)
+	if procedureCode != "" {
+		response.ProcedureCode = procedureCode
+
+		version, err = s.store.ProcedureVersionDomain.GetProcedureVersionOn(ctx, dB, procedureCode, serviceDate)
+		if err != nil {
+			if !apperr.IsNoRowsErr(err) {
+				return nil, err
+			}
+			version = nil
+
+			_, err := s.store.ProcedureDomain.GetProcedureByCode(ctx, dB, procedureCode)
+			if err != nil && !apperr.IsNoRowsErr(err) {
+				return nil, err
+			}
+			if err != nil {
+				response.BlockingReasons = append(response.BlockingReasons, "Invalid or unknown procedure code")
+			} else {
+				response.BlockingReasons = append(response.BlockingReasons, "Procedure has no price in effect on the service date")
+			}
+		}
+
+		if version != nil && provider != nil {
+			tariff, err = tariffPriceOn(ctx, s.store, dB, provider.ID, procedureCode, serviceDate)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// benefit
+	if !response.RemainingBenefit.IsPositive() {
+		response.BlockingReasons = append(response.BlockingReasons, benefitExhaustedReason)
+	}
+
+	// the expected price and amount in the benefit currency at the rates on the service date
+	priced := false
+	if version != nil {
+		expectedPrice := version.AverageCost
+		if tariff != nil {
+			expectedPrice = tariff.AgreedPrice
+		}
+		if currency == "" {
+			currency = member.BenefitCurrency
+		}
+
+		priceRate, err := exchangeRateOn(ctx, s.store, dB, expectedPrice.Currency, member.BenefitCurrency, serviceDate)
+		if err != nil {
+			return nil, err
+		}
+		rate, err := exchangeRateOn(ctx, s.store, dB, currency, member.BenefitCurrency, serviceDate)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !priceRate.IsValid():
+			response.BlockingReasons = append(response.BlockingReasons, noExchangeRateReason(expectedPrice.Currency, member.BenefitCurrency))
+		case !form.Amount.IsZero() && !rate.IsValid():
+			response.BlockingReasons = append(response.BlockingReasons, noExchangeRateReason(currency, member.BenefitCurrency))
+		default:
+			convertedPrice, err := expectedPrice.Convert(priceRate, member.BenefitCurrency)
+			if err != nil || !convertedPrice.FitsColumn() {
+				response.BlockingReasons = append(response.BlockingReasons, convertedOutOfRangeReason(member.BenefitCurrency))
+				break
+			}
+			amount := convertedPrice
+			if !form.Amount.IsZero() {
+				response.ExchangeRate = rate
+				amount, err = money.New(form.Amount.Amount, currency).Convert(response.ExchangeRate, member.BenefitCurrency)
+				if err != nil || !amount.FitsColumn() {
+					response.BlockingReasons = append(response.BlockingReasons, convertedOutOfRangeReason(member.BenefitCurrency))
+					break
+				}
+			}
+			priced = true
+			response.ExpectedPrice = convertedPrice
+			response.Amount = amount
+		}
+	}
+
+	response.Eligible = len(response.BlockingReasons) == 0
+	if !priced {
+		if version != nil {
+			response.ProjectedStatus = "REJECTED"
+		}
+		return response, nil
+	}
+
+	response.FraudFlag = response.Amount.GreaterThan(response.ExpectedPrice.Mul(FraudAmountMultiplier))
+
+	if !response.Eligible {
+		response.ProjectedStatus = "REJECTED"
+		return response, nil
+	}
+
+	var tariffPrice *money.Money
+	if tariff != nil {
+		tariffPrice = &response.ExpectedPrice
+	}
+
+	approvedAmount, status, reason := projectApprovedAmount(response.Amount, tariffPrice, response.RemainingBenefit)
+	response.ProjectedApprovedAmount = approvedAmount
+	response.ProjectedStatus = string(status)
+	if reason != "" {
+		response.Notes = append(response.Notes, reason)
+	}
+	if response.FraudFlag {
+		response.Notes = append(response.Notes, "Amount is more than the fraud threshold and would be flagged for review.")
+	}
+
+	return response, nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+type ExchangeRateService interface {
+	GetExchangeRates(ctx context.Context, dB db.DB, filter *models.Filter) (*models.ExchangeRateList, error)
+	ImportExchangeRates(ctx context.Context, dB db.DB, reader io.Reader, options *dtos.ImportOptions) (*dtos.ImportResult, error)
+}
+
+type exchangeRateService struct {
+	store *domain.Store
+}
+
+func NewExchangeRateService(store *domain.Store) ExchangeRateService {
+	return &exchangeRateService{store: store}
+}
+
+func (s *exchangeRateService) GetExchangeRates(
+	ctx context.Context,
+	dB db.DB,
+	filter *models.Filter,
+) (*models.ExchangeRateList, error) {
+	ctx, span := tracing.Start(ctx, "ExchangeRateService.GetExchangeRates")
+	defer span.End()
+
+	rates, err := s.store.ExchangeRateDomain.GetExchangeRates(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.ExchangeRateDomain.GetExchangeRatesCount(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ExchangeRateList{
+		ExchangeRates: rates,
+		Pagination:    models.NewPagination(count, filter.Page, filter.Per),
+	}, nil
+}
+
+// ImportExchangeRates loads rates from a CSV file with the columns base_currency, quote_currency,
+// rate and effective_from. A row for a pair and date that already has a rate replaces it.
+func (s *exchangeRateService) ImportExchangeRates(
+	ctx context.Context,
+	dB db.DB,
+	reader io.Reader,
+	options *dtos.ImportOptions,
+) (*dtos.ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "ExchangeRateService.ImportExchangeRates")
+	defer span.End()
+
+	requiredColumns := []string{"base_currency", "quote_currency", "rate", "effective_from"}
+	return runCSVImport(ctx, dB, reader, options, requiredColumns, s.importExchangeRateRow)
+}
+
+func (s *exchangeRateService) importExchangeRateRow(
+	ctx context.Context,
+	ops db.SQLOperations,
+	row *utils.CSVRow,
+) (bool, error) {
+
+	base := strings.ToUpper(row.Get("base_currency"))
+	if !money.IsCurrency(base) {
+		return false, apperr.NewBadRequest(fmt.Sprintf("invalid base_currency [%v]", row.Get("base_currency")))
+	}
+
+	quote := strings.ToUpper(row.Get("quote_currency"))
+	if !money.IsCurrency(quote) {
+		return false, apperr.NewBadRequest(fmt.Sprintf("invalid quote_currency [%v]", row.Get("quote_currency")))
+	}
+	if base == quote {
+		return false, apperr.NewBadRequest("base_currency and quote_currency must differ")
+	}
+
+	rate, err := money.ParseRate(row.Get("rate"))
+	if err != nil {
+		return false, apperr.NewBadRequest(fmt.Sprintf("invalid rate [%v]", row.Get("rate")))
+	}
+	if !rate.FitsColumn() {
+		return false, apperr.NewBadRequest(fmt.Sprintf("rate [%v] cannot have more than %d digits before the decimal point", row.Get("rate"), money.RateIntegerDigits))
+	}
+
+	// claims in the opposite direction use the inverse, so it must not round away either
+	rate = rate.Rounded()
+	if !rate.IsValid() || !rate.Inverse().Rounded().IsValid() {
+		return false, apperr.NewBadRequest(fmt.Sprintf("rate [%v] or its inverse rounds to zero at %d decimal places", row.Get("rate"), money.RateDecimals))
+	}
+
+	effectiveFrom, err := utils.ParseDate(row.Get("effective_from"))
+	if err != nil {
+		return false, apperr.NewBadRequest(err.Error())
+	}
+
+	exchangeRate, err := s.store.ExchangeRateDomain.GetExchangeRate(ctx, ops, base, quote, effectiveFrom)
+	if err != nil && !apperr.IsNoRowsErr(err) {
+		return false, err
+	}
+
+	created := err != nil
+	if created {
+		exchangeRate = &models.ExchangeRate{
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+			EffectiveFrom: effectiveFrom,
+		}
+	}
+	exchangeRate.Rate = rate
+
+	return created, s.store.ExchangeRateDomain.CreateExchangeRate(ctx, ops, exchangeRate)
+}
+
+func noExchangeRateReason(from, to string) string {
+	return fmt.Sprintf("No exchange rate from %s to %s on the service date", from, to)
+}
+
+func convertedOutOfRangeReason(to string) string {
+	return fmt.Sprintf("Amount is too large once converted to %s", to)
+}
+
+// amountOutOfRange is the error for an amount the DECIMAL(14, 2) amount columns cannot hold.
+func amountOutOfRange(field string) error {
+	return apperr.NewBadRequest(fmt.Sprintf("%s cannot exceed %v", field, money.FromMinor(money.MaxAmount)))
+}
+
+// exchangeRateOn returns the rate converting from one currency to another on the given date: the
+// pair's own rate if one is in effect, otherwise the inverse of the opposite pair's rate. The rate
+// is rounded as it is stored, so a conversion can be reproduced. It returns the zero Rate when
+// neither has a rate in effect, or when the inverse rounds to zero.
+func exchangeRateOn(
+	ctx context.Context,
+	store *domain.Store,
+	operations db.SQLOperations,
+	from, to string,
+	on time.Time,
+) (money.Rate, error) {
+
+	if from == to {
+		return money.OneRate(), nil
+	}
+
+	rate, err := store.ExchangeRateDomain.GetExchangeRateOn(ctx, operations, from, to, on)
+	if err == nil {
+		return rate.Rate.Rounded(), nil
+	}
+	if !apperr.IsNoRowsErr(err) {
+		return money.Rate{}, err
+	}
+
+	rate, err = store.ExchangeRateDomain.GetExchangeRateOn(ctx, operations, to, from, on)
+	if err == nil {
+		return rate.Rate.Inverse().Rounded(), nil
+	}
+	if !apperr.IsNoRowsErr(err) {
+		return money.Rate{}, err
+	}
+
+	return money.Rate{}, nil
+}
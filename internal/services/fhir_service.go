@@ -0,0 +1,457 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+)
+
+const (
+	// FHIRClaimIdentifierSystem namespaces our claim IDs in ClaimResponse.identifier.
+	FHIRClaimIdentifierSystem = "urn:ginja:claim-id"
+
+	fhirAdjudicationSystem = "http://terminology.hl7.org/CodeSystem/adjudication"
+	fhirBenefitTypeSystem  = "http://terminology.hl7.org/CodeSystem/benefit-type"
+)
+
+// FHIRService maps FHIR R4 resources onto the claims and eligibility services.
+type FHIRService interface {
+	SubmitClaim(ctx context.Context, dB db.DB, resource *dtos.FHIRClaim) (*dtos.FHIRClaimResponse, error)
+	CheckEligibility(ctx context.Context, dB db.DB, resource *dtos.FHIRCoverageEligibilityRequest) (*dtos.FHIRCoverageEligibilityResponse, error)
+}
+
+type fhirService struct {
+	store              *domain.Store
+	claimService       ClaimService
+	eligibilityService EligibilityService
+}
+
+func NewFHIRService(
+	store *domain.Store,
+	claimService ClaimService,
+	eligibilityService EligibilityService,
+) FHIRService {
+	return &fhirService{
+		store:              store,
+		claimService:       claimService,
+		eligibilityService: eligibilityService,
+	}
+}
+
+// SubmitClaim adjudicates a single-item FHIR Claim through the claims pipeline. The patient is
+// "Patient/<member id>" or an identifier holding the membership number, and the provider is
+// "Organization/<provider id>" or an identifier holding the licence number.
+func (s *fhirService) SubmitClaim(
+	ctx context.Context,
+	dB db.DB,
+	resource *dtos.FHIRClaim,
+) (*dtos.FHIRClaimResponse, error) {
+	ctx, span := tracing.Start(ctx, "FHIRService.SubmitClaim")
+	defer span.End()
+
+	if resource.ResourceType != "Claim" {
+		return nil, apperr.NewBadRequest("resourceType must be Claim")
+	}
+	if len(resource.Item) != 1 {
+		return nil, apperr.NewBadRequest("Claim must have exactly one item")
+	}
+	item := resource.Item[0]
+
+	form := &dtos.ClaimSubmissionForm{}
+
+	var err error
+	form.MemberID, form.MembershipNumber, err = fhirPatient(resource.Patient)
+	if err != nil {
+		return nil, err
+	}
+
+	form.ProviderID, err = s.fhirProvider(ctx, dB, resource.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	form.ProcedureCode = fhirCode(item.ProductOrService)
+	if form.ProcedureCode == "" {
+		return nil, apperr.NewBadRequest("item.productOrService must carry a procedure code")
+	}
+
+	form.DiagnosisCode = fhirPrincipalDiagnosis(resource.Diagnosis)
+	if form.DiagnosisCode == "" {
+		return nil, apperr.NewBadRequest("diagnosis must carry a diagnosis code")
+	}
+
+	var requested *dtos.FHIRMoney
+	switch {
+	case resource.Total != nil:
+		requested = resource.Total
+	case item.Net != nil:
+		requested = item.Net
+	case item.UnitPrice != nil:
+		requested = item.UnitPrice
+	}
+	if requested != nil {
+		form.RequestedAmount = requested.Value
+		form.Currency = requested.Currency
+	}
+	if !form.RequestedAmount.IsPositive() {
+		return nil, apperr.NewBadRequest("Claim total or item.net must be greater than 0")
+	}
+
+	switch {
+	case item.ServicedDate != "":
+		form.ServiceDate = fhirDate(item.ServicedDate)
+	case item.ServicedPeriod != nil && item.ServicedPeriod.Start != "":
+		form.ServiceDate = fhirDate(item.ServicedPeriod.Start)
+	case resource.BillablePeriod != nil && resource.BillablePeriod.Start != "":
+		form.ServiceDate = fhirDate(resource.BillablePeriod.Start)
+	default:
+		return nil, apperr.NewBadRequest("item.servicedDate or billablePeriod.start is required")
+	}
+
+	// an institutional claim's billable period is the stay
+	if fhirCode(resource.Type) == "institutional" && resource.BillablePeriod != nil {
+		form.AdmissionDate = fhirDate(resource.BillablePeriod.Start)
+		form.DischargeDate = fhirDate(resource.BillablePeriod.End)
+	}
+
+	use := resource.Use
+	switch use {
+	case "", "claim":
+		use = "claim"
+	case "preauthorization", "predetermination":
+		form.DryRun = true
+	default:
+		return nil, apperr.NewBadRequest(fmt.Sprintf("unsupported Claim use [%v]", resource.Use))
+	}
+
+	result, err := s.claimService.SubmitClaim(ctx, dB, form)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.claimResponse(resource, use, item, form, result), nil
+}
+
+func (s *fhirService) claimResponse(
+	resource *dtos.FHIRClaim,
+	use string,
+	item dtos.FHIRClaimItem,
+	form *dtos.ClaimSubmissionForm,
+	result *dtos.ClaimSubmissionResponse,
+) *dtos.FHIRClaimResponse {
+
+	response := &dtos.FHIRClaimResponse{
+		ResourceType: "ClaimResponse",
+		Status:       "active",
+		Type:         resource.Type,
+		Use:          use,
+		Patient:      resource.Patient,
+		Created:      time.Now().UTC().Format(time.RFC3339),
+		Insurer:      &dtos.FHIRReference{Display: "Ginja AI"},
+		Outcome:      fhirClaimOutcome(result.Status),
+		Disposition:  result.RejectionReason,
+	}
+
+	if result.ClaimID != 0 {
+		response.ID = strconv.FormatInt(result.ClaimID, 10)
+		response.Identifier = []dtos.FHIRIdentifier{{
+			System: FHIRClaimIdentifierSystem,
+			Value:  response.ID,
+		}}
+	}
+	if result.MemberID != 0 {
+		response.Patient = &dtos.FHIRReference{Reference: fmt.Sprintf("Patient/%d", result.MemberID)}
+	}
+	if resource.ID != "" {
+		response.Request = &dtos.FHIRReference{Reference: "Claim/" + resource.ID}
+	}
+	if strings.EqualFold(result.Status, "PARTIAL") {
+		response.Disposition = strings.TrimSpace(fmt.Sprintf(
+			"Claim partially approved for %v %v. %v",
+			result.ApprovedAmount, fhirMoney(result.ApprovedAmount).Currency, result.RejectionReason,
+		))
+	}
+	if response.Disposition == "" {
+		response.Disposition = "Claim " + strings.ToLower(result.Status)
+	}
+
+	submitted := fhirAdjudication("submitted", form.RequestedAmount)
+	benefit := fhirAdjudication("benefit", result.ApprovedAmount)
+	if result.RejectionReason != "" {
+		benefit.Reason = &dtos.FHIRCodeableConcept{Text: result.RejectionReason}
+	}
+
+	sequence := item.Sequence
+	if sequence == 0 {
+		sequence = 1
+	}
+	response.Item = []dtos.FHIRClaimResponseItem{{
+		ItemSequence: sequence,
+		Adjudication: []dtos.FHIRAdjudication{submitted, benefit},
+	}}
+	response.Total = []dtos.FHIRAdjudication{
+		fhirAdjudication("submitted", form.RequestedAmount),
+		fhirAdjudication("benefit", result.ApprovedAmount),
+	}
+
+	if result.FraudFlag {
+		response.ProcessNote = append(response.ProcessNote, dtos.FHIRClaimResponseNote{
+			Type: "display",
+			Text: "Claim flagged for fraud review",
+		})
+	}
+	if result.DryRun {
+		response.ProcessNote = append(response.ProcessNote, dtos.FHIRClaimResponseNote{
+			Type: "display",
+			Text: "Not stored: " + use + " only",
+		})
+	}
+
+	return response
+}
+
+// CheckEligibility answers a CoverageEligibilityRequest from the member's benefit and, when an
+// item is given, the projected adjudication of that procedure.
+func (s *fhirService) CheckEligibility(
+	ctx context.Context,
+	dB db.DB,
+	resource *dtos.FHIRCoverageEligibilityRequest,
+) (*dtos.FHIRCoverageEligibilityResponse, error) {
+	ctx, span := tracing.Start(ctx, "FHIRService.CheckEligibility")
+	defer span.End()
+
+	if resource.ResourceType != "CoverageEligibilityRequest" {
+		return nil, apperr.NewBadRequest("resourceType must be CoverageEligibilityRequest")
+	}
+	if len(resource.Item) > 1 {
+		return nil, apperr.NewBadRequest("CoverageEligibilityRequest can have at most one item")
+	}
+
+	memberID, membershipNumber, err := fhirPatient(resource.Patient)
+	if err != nil {
+		return nil, err
+	}
+	if membershipNumber != "" {
+		member, err := s.store.MemberDomain.GetMemberByMembershipNumber(ctx, dB, membershipNumber)
+		if err != nil {
+			return nil, err
+		}
+		memberID = member.ID
+	}
+
+	form := &dtos.EligibilityRequest{
+		ServiceDate: fhirDate(resource.ServicedDate),
+	}
+	if resource.Provider != nil {
+		form.ProviderID, err = s.fhirProvider(ctx, dB, resource.Provider)
+		if err != nil {
+			return nil, err
+		}
+		if form.ProviderID == 0 {
+			return nil, apperr.NewBadRequest("provider not found")
+		}
+	}
+
+	var item *dtos.FHIRCoverageEligibilityItem
+	if len(resource.Item) == 1 {
+		item = &resource.Item[0]
+		form.ProcedureCode = fhirCode(item.ProductOrService)
+		if item.UnitPrice != nil {
+			form.Amount = item.UnitPrice.Value
+			form.Currency = item.UnitPrice.Currency
+		}
+	}
+
+	eligibility, err := s.eligibilityService.CheckEligibility(ctx, dB, memberID, form)
+	if err != nil {
+		return nil, err
+	}
+
+	purpose := resource.Purpose
+	if len(purpose) == 0 {
+		purpose = []string{"validation"}
+	}
+
+	response := &dtos.FHIRCoverageEligibilityResponse{
+		ResourceType: "CoverageEligibilityResponse",
+		Status:       "active",
+		Purpose:      purpose,
+		Patient:      &dtos.FHIRReference{Reference: fmt.Sprintf("Patient/%d", eligibility.MemberID)},
+		ServicedDate: resource.ServicedDate,
+		Created:      time.Now().UTC().Format(time.RFC3339),
+		Outcome:      "complete",
+		Disposition:  "Eligible",
+		Insurer:      &dtos.FHIRReference{Display: "Ginja AI"},
+	}
+	if resource.ID != "" {
+		response.Request = &dtos.FHIRReference{Reference: "CoverageEligibilityRequest/" + resource.ID}
+	}
+	if !eligibility.Eligible {
+		response.Disposition = "Not eligible: " + strings.Join(eligibility.BlockingReasons, "; ")
+	}
+
+	benefits := dtos.FHIREligibilityBenefits{
+		Excluded: !eligibility.Eligible,
+		Benefit: []dtos.FHIREligibilityBenefit{{
+			Type: dtos.FHIRCodeableConcept{
+				Coding: []dtos.FHIRCoding{{System: fhirBenefitTypeSystem, Code: "benefit"}},
+			},
+			AllowedMoney: fhirMoney(eligibility.BenefitLimit),
+			UsedMoney:    fhirMoney(eligibility.UsedAmount),
+		}},
+	}
+	if item != nil {
+		benefits.ProductOrService = item.ProductOrService
+	}
+	if eligibility.ProjectedStatus != "" {
+		benefits.Description = fmt.Sprintf(
+			"Projected %v, %v %v approved",
+			strings.ToLower(eligibility.ProjectedStatus),
+			eligibility.ProjectedApprovedAmount,
+			eligibility.BenefitCurrency,
+		)
+	}
+
+	response.Insurance = []dtos.FHIREligibilityInsurance{{
+		Coverage: dtos.FHIRReference{Display: fmt.Sprintf("Member %d benefit", eligibility.MemberID)},
+		Inforce:  eligibility.Active,
+		Item:     []dtos.FHIREligibilityBenefits{benefits},
+	}}
+
+	for _, reason := range eligibility.BlockingReasons {
+		response.Error = append(response.Error, dtos.FHIREligibilityError{
+			Code: dtos.FHIRCodeableConcept{Text: reason},
+		})
+	}
+
+	return response, nil
+}
+
+// fhirProvider resolves "Organization/<id>" or a licence number identifier to a provider ID. An
+// unknown licence number gives 0, which the claims pipeline rejects as "Provider not found".
+func (s *fhirService) fhirProvider(
+	ctx context.Context,
+	dB db.DB,
+	reference *dtos.FHIRReference,
+) (int64, error) {
+
+	if reference == nil {
+		return 0, apperr.NewBadRequest("provider is required")
+	}
+	if reference.Reference != "" {
+		return fhirReferenceID(reference.Reference, "Organization")
+	}
+	if reference.Identifier != nil && strings.TrimSpace(reference.Identifier.Value) != "" {
+		provider, err := s.store.ProviderDomain.GetProviderByLicenceNumber(ctx, dB, strings.TrimSpace(reference.Identifier.Value))
+		if err == nil {
+			return provider.ID, nil
+		}
+		if apperr.IsNoRowsErr(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return 0, apperr.NewBadRequest("provider needs a reference or an identifier")
+}
+
+// fhirPatient reads the member ID from "Patient/<id>", or the membership number from an identifier.
+func fhirPatient(reference *dtos.FHIRReference) (int64, string, error) {
+	if reference == nil {
+		return 0, "", apperr.NewBadRequest("patient is required")
+	}
+	if reference.Reference != "" {
+		id, err := fhirReferenceID(reference.Reference, "Patient")
+		return id, "", err
+	}
+	if reference.Identifier != nil && strings.TrimSpace(reference.Identifier.Value) != "" {
+		return 0, strings.TrimSpace(reference.Identifier.Value), nil
+	}
+	return 0, "", apperr.NewBadRequest("patient needs a reference or an identifier")
+}
+
+// fhirReferenceID parses the ID from a relative or absolute reference such as "Patient/12".
+func fhirReferenceID(reference string, resourceType string) (int64, error) {
+	parts := strings.Split(strings.TrimRight(reference, "/"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] != resourceType {
+		return 0, apperr.NewBadRequest(fmt.Sprintf("reference [%v] must point to a %v", reference, resourceType))
+	}
+
+	id, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, apperr.NewBadRequest(fmt.Sprintf("invalid %v id in reference [%v]", resourceType, reference))
+	}
+	return id, nil
+}
+
+// fhirCode returns the first coded value of a concept, falling back to its text.
+func fhirCode(concept *dtos.FHIRCodeableConcept) string {
+	if concept == nil {
+		return ""
+	}
+	for _, coding := range concept.Coding {
+		if code := strings.TrimSpace(coding.Code); code != "" {
+			return code
+		}
+	}
+	return strings.TrimSpace(concept.Text)
+}
+
+// fhirPrincipalDiagnosis prefers the diagnosis with sequence 1, otherwise the first one.
+func fhirPrincipalDiagnosis(diagnoses []dtos.FHIRClaimDiagnosis) string {
+	for _, diagnosis := range diagnoses {
+		if diagnosis.Sequence == 1 {
+			return fhirCode(diagnosis.DiagnosisCodeableConcept)
+		}
+	}
+	if len(diagnoses) > 0 {
+		return fhirCode(diagnoses[0].DiagnosisCodeableConcept)
+	}
+	return ""
+}
+
+// fhirDate keeps the date part of a FHIR date or dateTime.
+func fhirDate(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > 10 {
+		return value[:10]
+	}
+	return value
+}
+
+// fhirClaimOutcome maps a claim status to a ClaimResponse outcome. Rejected and partially approved
+// claims are completed adjudications, explained in the disposition and the benefit adjudication; in
+// FHIR "partial" would mean processing finished with errors and "error" that it could not be done.
+func fhirClaimOutcome(status string) string {
+	switch strings.ToUpper(status) {
+	case "APPROVED", "PARTIAL", "REJECTED":
+		return "complete"
+	default:
+		return "queued"
+	}
+}
+
+func fhirAdjudication(category string, amount money.Money) dtos.FHIRAdjudication {
+	return dtos.FHIRAdjudication{
+		Category: dtos.FHIRCodeableConcept{
+			Coding: []dtos.FHIRCoding{{System: fhirAdjudicationSystem, Code: category}},
+		},
+		Amount: fhirMoney(amount),
+	}
+}
+
+func fhirMoney(amount money.Money) *dtos.FHIRMoney {
+	currency := amount.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	return &dtos.FHIRMoney{Value: amount, Currency: currency}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/health"
+	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+)
+
+type HealthService interface {
+	Readiness(ctx context.Context, dB db.DB) *dtos.Readiness
+	Status(ctx context.Context, dB db.DB) *dtos.ServiceStatus
+}
+
+type healthService struct {
+	store *domain.Store
+	state *health.State
+}
+
+func NewHealthService(
+	store *domain.Store,
+	state *health.State,
+) HealthService {
+	return &healthService{
+		store: store,
+		state: state,
+	}
+}
+
+// Readiness checks that the server is not shutting down, that the database answers and that
+// every migration built into the binary has been applied. Failures are described without
+// internal detail, since the probe is public; the causes are logged.
+func (s *healthService) Readiness(
+	ctx context.Context,
+	dB db.DB,
+) *dtos.Readiness {
+	ctx, span := tracing.Start(ctx, "HealthService.Readiness")
+	defer span.End()
+
+	readiness, _ := s.readiness(ctx, dB)
+	return readiness
+}
+
+func (s *healthService) Status(
+	ctx context.Context,
+	dB db.DB,
+) *dtos.ServiceStatus {
+	ctx, span := tracing.Start(ctx, "HealthService.Status")
+	defer span.End()
+
+	readiness, pending := s.readiness(ctx, dB)
+	uptime := s.state.Uptime()
+
+	sqlStats := dB.Stats()
+	pgxStats := dB.PoolStat()
+
+	return &dtos.ServiceStatus{
+		Version:           health.Version,
+		GoVersion:         runtime.Version(),
+		StartedAt:         s.state.StartedAt(),
+		Uptime:            uptime.Truncate(time.Second).String(),
+		UptimeSeconds:     int64(uptime.Seconds()),
+		Ready:             readiness.Ready,
+		Checks:            readiness.Checks,
+		PendingMigrations: pending,
+		DatabasePools: []*dtos.DatabasePoolStatus{
+			{
+				Pool:            "sql",
+				OpenConnections: sqlStats.OpenConnections,
+				InUse:           sqlStats.InUse,
+				Idle:            sqlStats.Idle,
+				MaxConnections:  sqlStats.MaxOpenConnections,
+				WaitCount:       sqlStats.WaitCount,
+				WaitDuration:    sqlStats.WaitDuration.String(),
+			},
+			{
+				Pool:            "pgx",
+				OpenConnections: int(pgxStats.TotalConns()),
+				InUse:           int(pgxStats.AcquiredConns()),
+				Idle:            int(pgxStats.IdleConns()),
+				MaxConnections:  int(pgxStats.MaxConns()),
+				WaitCount:       pgxStats.EmptyAcquireCount(),
+				WaitDuration:    pgxStats.EmptyAcquireWaitTime().String(),
+			},
+		},
+	}
+}
+
+// readiness runs the readiness checks and also returns the versions of the migrations not yet
+// applied.
+func (s *healthService) readiness(
+	ctx context.Context,
+	dB db.DB,
+) (*dtos.Readiness, []int64) {
+
+	shutdown := &dtos.HealthCheck{Name: "shutdown", Healthy: !s.state.ShuttingDown()}
+	if !shutdown.Healthy {
+		shutdown.Message = "server is shutting down"
+	}
+
+	database := &dtos.HealthCheck{Name: "database", Healthy: true}
+	migrations := &dtos.HealthCheck{Name: "migrations", Healthy: false}
+	pending := make([]int64, 0)
+
+	if err := dB.Ping(); err != nil {
+		logger.FromContext(ctx).Errorf("readiness database ping failed: %v", err)
+		database.Healthy = false
+		database.Message = "database is unreachable"
+		migrations.Message = "not checked, database is unreachable"
+	} else {
+		pending, migrations.Message = s.pendingMigrations(ctx, dB)
+		migrations.Healthy = migrations.Message == ""
+	}
+
+	readiness := &dtos.Readiness{
+		Ready:  shutdown.Healthy && database.Healthy && migrations.Healthy,
+		Checks: []*dtos.HealthCheck{shutdown, database, migrations},
+	}
+	return readiness, pending
+}
+
+// pendingMigrations returns the embedded migrations the database has not applied, and a message
+// when the database is not up to date.
+func (s *healthService) pendingMigrations(
+	ctx context.Context,
+	dB db.DB,
+) ([]int64, string) {
+
+	pending := make([]int64, 0)
+
+	versions, err := db.MigrationVersions()
+	if err != nil {
+		logger.FromContext(ctx).Errorf("readiness could not list embedded migrations: %v", err)
+		return pending, "migrations could not be listed"
+	}
+
+	applied, err := s.store.MigrationDomain.GetAppliedMigrationVersions(ctx, dB)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("readiness could not read applied migrations: %v", err)
+		return pending, "applied migrations could not be read"
+	}
+
+	appliedVersions := make(map[int64]bool, len(applied))
+	for _, version := range applied {
+		appliedVersions[version] = true
+	}
+	for _, version := range versions {
+		if !appliedVersions[version] {
+			pending = append(pending, version)
+		}
+	}
+
+	if len(pending) > 0 {
+		return pending, fmt.Sprintf("%d migrations not applied", len(pending))
+	}
+	return pending, ""
+}
@@ -0,0 +1,462 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+// errImportRejected rolls back an import transaction for dry runs and rejected all-or-nothing imports.
+var errImportRejected = errors.New("import rejected")
+
+// rowImporter saves a single CSV row and reports whether it created a new record.
+type rowImporter func(ctx context.Context, operations db.SQLOperations, row *utils.CSVRow) (bool, error)
+
+type ImportService interface {
+	ImportProcedures(ctx context.Context, dB db.DB, reader io.Reader, options *dtos.ImportOptions) (*dtos.ImportResult, error)
+	ImportProviders(ctx context.Context, dB db.DB, reader io.Reader, options *dtos.ImportOptions) (*dtos.ImportResult, error)
+	ImportMembers(ctx context.Context, dB db.DB, reader io.Reader, options *dtos.ImportOptions) (*dtos.ImportResult, error)
+}
+
+type importService struct {
+	store *domain.Store
+}
+
+func NewImportService(store *domain.Store) ImportService {
+	return &importService{store: store}
+}
+
+// ImportProcedures upserts procedures by code. A changed average_cost is added as a new price
+// version effective from the row's effective_from, or today when it is blank.
+func (s *importService) ImportProcedures(
+	ctx context.Context,
+	dB db.DB,
+	reader io.Reader,
+	options *dtos.ImportOptions,
+) (*dtos.ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportProcedures")
+	defer span.End()
+
+	return runCSVImport(ctx, dB, reader, options, []string{"code", "average_cost"}, s.importProcedureRow)
+}
+
+// ImportProviders upserts providers by name and location.
+func (s *importService) ImportProviders(
+	ctx context.Context,
+	dB db.DB,
+	reader io.Reader,
+	options *dtos.ImportOptions,
+) (*dtos.ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportProviders")
+	defer span.End()
+
+	return runCSVImport(ctx, dB, reader, options, []string{"name"}, s.importProviderRow)
+}
+
+// ImportMembers upserts members by membership number, or by full name for rows without one. Names
+// shared by more than one member are reported as row errors rather than guessed.
+func (s *importService) ImportMembers(
+	ctx context.Context,
+	dB db.DB,
+	reader io.Reader,
+	options *dtos.ImportOptions,
+) (*dtos.ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportMembers")
+	defer span.End()
+
+	return runCSVImport(ctx, dB, reader, options, []string{"full_name"}, s.importMemberRow)
+}
+
+func (s *importService) importProcedureRow(
+	ctx context.Context,
+	operations db.SQLOperations,
+	row *utils.CSVRow,
+) (bool, error) {
+
+	code := row.Get("code")
+	if code == "" {
+		return false, apperr.NewBadRequest("code is required")
+	}
+
+	averageCost, err := csvMoney(row, "average_cost")
+	if err != nil {
+		return false, err
+	}
+	if averageCost == nil || !averageCost.IsPositive() {
+		return false, apperr.NewBadRequest("average_cost must be greater than zero")
+	}
+	if !averageCost.FitsColumn() {
+		return false, amountOutOfRange("average_cost")
+	}
+
+	effectiveFrom := today()
+	if row.Get("effective_from") != "" {
+		effectiveFrom, err = utils.ParseDate(row.Get("effective_from"))
+		if err != nil {
+			return false, apperr.NewBadRequest(err.Error())
+		}
+	}
+
+	procedure, err := s.store.ProcedureDomain.GetProcedureByCode(ctx, operations, code)
+	if err != nil && !apperr.IsNoRowsErr(err) {
+		return false, err
+	}
+
+	currency := strings.ToUpper(row.Get("currency"))
+	if err == nil && currency != "" && currency != procedure.Currency {
+		return false, apperr.NewBadRequest(fmt.Sprintf("procedure [%v] is priced in %v", code, procedure.Currency))
+	}
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	if !money.IsCurrency(currency) {
+		return false, apperr.NewBadRequest(fmt.Sprintf("invalid currency [%v]", currency))
+	}
+
+	if err != nil {
+		procedure = &models.Procedure{
+			Code:        code,
+			Description: row.Get("description"),
+			AverageCost: money.New(averageCost.Amount, currency),
+			Currency:    currency,
+		}
+		if err := s.store.ProcedureDomain.CreateProcedure(ctx, operations, procedure); err != nil {
+			return false, err
+		}
+
+		_, err := appendProcedureVersion(ctx, s.store, operations, procedure, *averageCost, effectiveFrom)
+		return true, err
+	}
+
+	if row.Get("description") != "" {
+		procedure.Description = row.Get("description")
+	}
+	if err := s.store.ProcedureDomain.CreateProcedure(ctx, operations, procedure); err != nil {
+		return false, err
+	}
+
+	latest, err := s.store.ProcedureVersionDomain.GetLatestProcedureVersion(ctx, operations, procedure.ID)
+	if err != nil && !apperr.IsNoRowsErr(err) {
+		return false, err
+	}
+	if err == nil && latest.AverageCost.Amount == averageCost.Amount {
+		return false, nil
+	}
+
+	_, err = appendProcedureVersion(ctx, s.store, operations, procedure, *averageCost, effectiveFrom)
+	return false, err
+}
+
+func (s *importService) importProviderRow(
+	ctx context.Context,
+	operations db.SQLOperations,
+	row *utils.CSVRow,
+) (bool, error) {
+
+	name := row.Get("name")
+	if name == "" {
+		return false, apperr.NewBadRequest("name is required")
+	}
+	location := row.Get("location")
+
+	provider, err := s.store.ProviderDomain.GetProviderByNameAndLocation(ctx, operations, name, location)
+	if err != nil && !apperr.IsNoRowsErr(err) {
+		return false, err
+	}
+
+	created := err != nil
+	if created {
+		provider = &models.Provider{
+			Name:        name,
+			Location:    location,
+			NetworkTier: custom_types.ProviderNetworkTierInNetwork,
+			Status:      custom_types.ProviderStatusActive,
+		}
+	}
+
+	if value := row.Get("licence_number"); value != "" {
+		provider.LicenceNumber = value
+	}
+	if value := row.Get("accreditation_expiry"); value != "" {
+		provider.AccreditationExpiry, err = parseOptionalDate(value)
+		if err != nil {
+			return false, err
+		}
+	}
+	if value := row.Get("network_tier"); value != "" {
+		provider.NetworkTier = custom_types.ProviderNetworkTier(value)
+	}
+	if value := row.Get("status"); value != "" {
+		provider.Status = custom_types.ProviderStatus(value)
+	}
+
+	validator := &providerService{store: s.store}
+	if err := validator.validateProvider(ctx, operations, provider); err != nil {
+		return false, err
+	}
+
+	return created, s.store.ProviderDomain.CreateProvider(ctx, operations, provider)
+}
+
+func (s *importService) importMemberRow(
+	ctx context.Context,
+	operations db.SQLOperations,
+	row *utils.CSVRow,
+) (bool, error) {
+
+	fullName := row.Get("full_name")
+	if fullName == "" {
+		return false, apperr.NewBadRequest("full_name is required")
+	}
+
+	member, err := s.findImportedMember(ctx, operations, fullName, row.Get("membership_number"))
+	if err != nil {
+		return false, err
+	}
+	if member != nil {
+		// read again under a row lock, so the used amount written back is current
+		member, err = s.store.MemberDomain.GetMemberByIDForUpdate(ctx, operations, member.ID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	created := member == nil
+	if created {
+		member = &models.Member{
+			IsActive:        true,
+			BenefitCurrency: money.DefaultCurrency,
+		}
+	}
+	member.FullName = fullName
+
+	if value := strings.ToUpper(row.Get("benefit_currency")); value != "" && value != member.BenefitCurrency {
+		if !member.UsedAmount.IsZero() {
+			return false, apperr.NewBadRequest("benefit_currency cannot change once some of the benefit is used")
+		}
+		member.BenefitCurrency = value
+	}
+
+	if value := row.Get("membership_number"); value != "" {
+		member.MembershipNumber = value
+	}
+	if value := row.Get("national_id"); value != "" {
+		member.NationalID = value
+	}
+	if value := row.Get("card_number"); value != "" {
+		member.CardNumber = value
+	}
+
+	isActive, err := csvBool(row, "is_active")
+	if err != nil {
+		return false, err
+	}
+	if isActive != nil {
+		member.IsActive = *isActive
+	}
+
+	benefitLimit, err := csvMoney(row, "benefit_limit")
+	if err != nil {
+		return false, err
+	}
+	if benefitLimit != nil {
+		member.BenefitLimit = *benefitLimit
+	}
+
+	usedAmount, err := csvMoney(row, "used_amount")
+	if err != nil {
+		return false, err
+	}
+	if usedAmount != nil {
+		member.UsedAmount = *usedAmount
+	}
+
+	if err := validateMember(member); err != nil {
+		return false, err
+	}
+
+	validator := &memberService{store: s.store}
+	if err := validator.validateMemberIdentifiers(ctx, operations, member); err != nil {
+		return false, err
+	}
+
+	return created, s.store.MemberDomain.CreateMember(ctx, operations, member)
+}
+
+// findImportedMember matches a row by membership number when it has one, otherwise by full name.
+// It returns nil when the row is a new member.
+func (s *importService) findImportedMember(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fullName string,
+	membershipNumber string,
+) (*models.Member, error) {
+
+	if membershipNumber != "" {
+		member, err := s.store.MemberDomain.GetMemberByMembershipNumber(ctx, operations, membershipNumber)
+		if err == nil {
+			return member, nil
+		}
+		if apperr.IsNoRowsErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	members, err := s.store.MemberDomain.GetMembersByFullName(ctx, operations, fullName)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) > 1 {
+		return nil, apperr.NewBadRequest(fmt.Sprintf("full_name [%v] matches %d members", fullName, len(members)))
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members[0], nil
+}
+
+// runCSVImport applies every row in one transaction. Each row runs in its own savepoint so a
+// failed row leaves nothing behind when the rest of the file is kept.
+func runCSVImport(
+	ctx context.Context,
+	dB db.DB,
+	reader io.Reader,
+	options *dtos.ImportOptions,
+	requiredColumns []string,
+	importRow rowImporter,
+) (*dtos.ImportResult, error) {
+
+	rows, err := utils.ReadCSV(reader, requiredColumns...)
+	if err != nil {
+		return nil, apperr.NewBadRequest(err.Error())
+	}
+
+	result := &dtos.ImportResult{
+		DryRun: options.DryRun,
+		Errors: make([]dtos.ImportRowError, 0),
+	}
+
+	err = dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		for _, row := range rows {
+			created, err := importRowInSavepoint(ctx, ops, row, importRow)
+			if err != nil {
+				message, ok := importRowErrorMessage(err)
+				if !ok {
+					return err
+				}
+				result.Errors = append(result.Errors, dtos.ImportRowError{
+					Row:   row.Line,
+					Error: message,
+				})
+				continue
+			}
+
+			result.Imported++
+			if created {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		}
+
+		if options.DryRun || options.AllOrNothing && len(result.Errors) > 0 {
+			return errImportRejected
+		}
+		return nil
+	})
+	if errors.Is(err, errImportRejected) {
+		if !options.DryRun {
+			result.Imported, result.Created, result.Updated = 0, 0, 0
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func importRowInSavepoint(
+	ctx context.Context,
+	operations db.SQLOperations,
+	row *utils.CSVRow,
+	importRow rowImporter,
+) (bool, error) {
+
+	if _, err := operations.ExecContext(ctx, "SAVEPOINT import_row"); err != nil {
+		return false, apperr.NewDatabaseError(err).LogErrorMessage("import savepoint error: %v", err)
+	}
+
+	created, err := importRow(ctx, operations, row)
+	if err != nil {
+		if _, rollbackErr := operations.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_row"); rollbackErr != nil {
+			return false, apperr.NewDatabaseError(rollbackErr).LogErrorMessage("import rollback to savepoint error: %v", rollbackErr)
+		}
+		return false, err
+	}
+
+	if _, err := operations.ExecContext(ctx, "RELEASE SAVEPOINT import_row"); err != nil {
+		return false, apperr.NewDatabaseError(err).LogErrorMessage("import release savepoint error: %v", err)
+	}
+
+	return created, nil
+}
+
+// importRowErrorMessage reports validation failures as row errors; anything else aborts the import.
+func importRowErrorMessage(err error) (string, bool) {
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) {
+		return "", false
+	}
+
+	switch appErr.Type {
+	case apperr.BadRequest, apperr.NotFound, apperr.Conflict:
+		return appErr.Message, true
+	}
+
+	return "", false
+}
+
+func csvMoney(row *utils.CSVRow, column string) (*money.Money, error) {
+	value := row.Get(column)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := money.Parse(value)
+	if err != nil {
+		return nil, apperr.NewBadRequest(fmt.Sprintf("invalid %v [%v]", column, value))
+	}
+
+	return &parsed, nil
+}
+
+func csvBool(row *utils.CSVRow, column string) (*bool, error) {
+	value := row.Get(column)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, apperr.NewBadRequest(fmt.Sprintf("invalid %v [%v]", column, value))
+	}
+
+	return &parsed, nil
+}
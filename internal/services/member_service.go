@@ -2,16 +2,25 @@ package services
 
 import (
 	"context"
+	"strings"
 
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
 	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
 )
 
 type MemberService interface {
 	CreateMember(ctx context.Context, dB db.DB, form *dtos.Member) (*models.Member, error)
-	}
+	GetMemberByID(ctx context.Context, dB db.DB, id int64) (*models.Member, error)
+	LookupMember(ctx context.Context, dB db.DB, form *dtos.MemberLookupRequest) (*models.Member, error)
+	GetMembers(ctx context.Context, dB db.DB, filter *models.Filter) (*models.MemberList, error)
+	UpdateMember(ctx context.Context, dB db.DB, id int64, form *dtos.UpdateMemberRequest) (*models.Member, error)
+	DeactivateMember(ctx context.Context, dB db.DB, id int64) (*models.Member, error)
+}
 
 type memberService struct {
 	store *domain.Store
@@ -26,17 +35,247 @@ func (s *memberService) CreateMember(
 	dB db.DB,
 	form *dtos.Member,
 ) (*models.Member, error) {
+	ctx, span := tracing.Start(ctx, "MemberService.CreateMember")
+	defer span.End()
 
 	member := &models.Member{
-		FullName:     form.FullName,
-		IsActive:     form.IsActive,
-		BenefitLimit: form.BenefitLimit,
-		UsedAmount:   form.UsedAmount,
+		FullName:         strings.TrimSpace(form.FullName),
+		MembershipNumber: strings.TrimSpace(form.MembershipNumber),
+		NationalID:       strings.TrimSpace(form.NationalID),
+		CardNumber:       strings.TrimSpace(form.CardNumber),
+		IsActive:         form.IsActive,
+		BenefitLimit:     form.BenefitLimit,
+		UsedAmount:       form.UsedAmount,
+		BenefitCurrency:  strings.ToUpper(strings.TrimSpace(form.BenefitCurrency)),
+	}
+	if member.BenefitCurrency == "" {
+		member.BenefitCurrency = money.DefaultCurrency
 	}
+	if err := validateMember(member); err != nil {
+		return nil, err
+	}
+	if err := s.validateMemberIdentifiers(ctx, dB, member); err != nil {
+		return nil, err
+	}
+
 	err := s.store.MemberDomain.CreateMember(ctx, dB, member)
 	if err != nil {
 		return nil, err
 	}
-	
+
+	return member, nil
+}
+
+func (s *memberService) GetMemberByID(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) (*models.Member, error) {
+	ctx, span := tracing.Start(ctx, "MemberService.GetMemberByID", tracing.MemberID(id))
+	defer span.End()
+
+	return s.store.MemberDomain.GetMemberByID(ctx, dB, id)
+}
+
+// LookupMember finds a member by membership number, national ID or card number. Exactly one
+// identifier must be given.
+func (s *memberService) LookupMember(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.MemberLookupRequest,
+) (*models.Member, error) {
+	ctx, span := tracing.Start(ctx, "MemberService.LookupMember")
+	defer span.End()
+
+	membershipNumber := strings.TrimSpace(form.MembershipNumber)
+	nationalID := strings.TrimSpace(form.NationalID)
+	cardNumber := strings.TrimSpace(form.CardNumber)
+
+	given := 0
+	for _, value := range []string{membershipNumber, nationalID, cardNumber} {
+		if value != "" {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, apperr.NewBadRequest("give exactly one of membership_number, national_id or card_number")
+	}
+
+	switch {
+	case membershipNumber != "":
+		return s.store.MemberDomain.GetMemberByMembershipNumber(ctx, dB, membershipNumber)
+	case nationalID != "":
+		return s.store.MemberDomain.GetMemberByNationalID(ctx, dB, nationalID)
+	default:
+		return s.store.MemberDomain.GetMemberByCardNumber(ctx, dB, cardNumber)
+	}
+}
+
+func (s *memberService) GetMembers(
+	ctx context.Context,
+	dB db.DB,
+	filter *models.Filter,
+) (*models.MemberList, error) {
+	ctx, span := tracing.Start(ctx, "MemberService.GetMembers")
+	defer span.End()
+
+	members, err := s.store.MemberDomain.GetMembers(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.MemberDomain.GetMembersCount(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MemberList{
+		Members:    members,
+		Pagination: models.NewPagination(count, filter.Page, filter.Per),
+	}, nil
+}
+
+func (s *memberService) UpdateMember(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+	form *dtos.UpdateMemberRequest,
+) (*models.Member, error) {
+	ctx, span := tracing.Start(ctx, "MemberService.UpdateMember", tracing.MemberID(id))
+	defer span.End()
+
+	// the row stays locked until the update commits, so claims approved meanwhile keep their spend
+	var member *models.Member
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+		var err error
+		member, err = s.store.MemberDomain.GetMemberByIDForUpdate(ctx, ops, id)
+		if err != nil {
+			return err
+		}
+		usedAmount := member.UsedAmount
+
+		if form.FullName != nil {
+			member.FullName = strings.TrimSpace(*form.FullName)
+		}
+		if form.MembershipNumber != nil {
+			member.MembershipNumber = strings.TrimSpace(*form.MembershipNumber)
+		}
+		if form.NationalID != nil {
+			member.NationalID = strings.TrimSpace(*form.NationalID)
+		}
+		if form.CardNumber != nil {
+			member.CardNumber = strings.TrimSpace(*form.CardNumber)
+		}
+		if form.IsActive != nil {
+			member.IsActive = *form.IsActive
+		}
+		if form.BenefitLimit != nil {
+			member.BenefitLimit = *form.BenefitLimit
+		}
+		if form.UsedAmount != nil {
+			member.UsedAmount = *form.UsedAmount
+		}
+		if form.BenefitCurrency != nil {
+			currency := strings.ToUpper(strings.TrimSpace(*form.BenefitCurrency))
+			if currency != member.BenefitCurrency && !usedAmount.IsZero() {
+				return apperr.NewBadRequest("benefit_currency cannot change once some of the benefit is used")
+			}
+			member.BenefitCurrency = currency
+		}
+
+		if err := validateMember(member); err != nil {
+			return err
+		}
+		if err := s.validateMemberIdentifiers(ctx, ops, member); err != nil {
+			return err
+		}
+
+		return s.store.MemberDomain.CreateMember(ctx, ops, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return member, nil
 }
+
+// DeactivateMember keeps the member and their claim history but stops new claims being approved.
+func (s *memberService) DeactivateMember(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) (*models.Member, error) {
+	ctx, span := tracing.Start(ctx, "MemberService.DeactivateMember", tracing.MemberID(id))
+	defer span.End()
+
+	var member *models.Member
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+		var err error
+		member, err = s.store.MemberDomain.GetMemberByIDForUpdate(ctx, ops, id)
+		if err != nil {
+			return err
+		}
+
+		member.IsActive = false
+		return s.store.MemberDomain.CreateMember(ctx, ops, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func validateMember(member *models.Member) error {
+	if member.FullName == "" {
+		return apperr.NewBadRequest("full_name is required")
+	}
+	if !money.IsCurrency(member.BenefitCurrency) {
+		return apperr.NewBadRequest("benefit_currency must be one of " + strings.Join(money.Currencies, ", "))
+	}
+	member.BenefitLimit.Currency = member.BenefitCurrency
+	member.UsedAmount.Currency = member.BenefitCurrency
+
+	if member.BenefitLimit.IsNegative() {
+		return apperr.NewBadRequest("benefit_limit cannot be negative")
+	}
+	if member.UsedAmount.IsNegative() {
+		return apperr.NewBadRequest("used_amount cannot be negative")
+	}
+	if !member.BenefitLimit.FitsColumn() {
+		return amountOutOfRange("benefit_limit")
+	}
+	if member.UsedAmount.GreaterThan(member.BenefitLimit) {
+		return apperr.NewBadRequest("used_amount cannot be above benefit_limit")
+	}
+	return nil
+}
+
+// validateMemberIdentifiers rejects identifiers that already belong to another member.
+func (s *memberService) validateMemberIdentifiers(
+	ctx context.Context,
+	operations db.SQLOperations,
+	member *models.Member,
+) error {
+
+	if member.MembershipNumber != "" {
+		existing, err := s.store.MemberDomain.GetMemberByMembershipNumber(ctx, operations, member.MembershipNumber)
+		if err == nil && existing.ID != member.ID {
+			return apperr.NewConflict("membership_number", member.MembershipNumber)
+		}
+	}
+	if member.NationalID != "" {
+		existing, err := s.store.MemberDomain.GetMemberByNationalID(ctx, operations, member.NationalID)
+		if err == nil && existing.ID != member.ID {
+			return apperr.NewConflict("national_id", member.NationalID)
+		}
+	}
+	if member.CardNumber != "" {
+		existing, err := s.store.MemberDomain.GetMemberByCardNumber(ctx, operations, member.CardNumber)
+		if err == nil && existing.ID != member.ID {
+			return apperr.NewConflict("card_number", member.CardNumber)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+const (
+	// MFAIssuer is the account issuer shown in authenticator apps.
+	MFAIssuer = "Ginja AI"
+	// RecoveryCodeCount is the number of recovery codes issued on enrolment.
+	RecoveryCodeCount = 10
+)
+
+type MFAService interface {
+	Enroll(ctx context.Context, dB db.DB, username string) (*dtos.MFAEnrollmentResponse, error)
+	Confirm(ctx context.Context, dB db.DB, username, code string) (*dtos.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, dB db.DB, username, code, ipAddress string) error
+	VerifyChallenge(ctx context.Context, dB db.DB, username, code, ipAddress string) (*models.User, error)
+}
+
+type mfaService struct {
+	store *domain.Store
+}
+
+func NewMFAService(store *domain.Store) MFAService {
+	return &mfaService{
+		store: store,
+	}
+}
+
+func (s *mfaService) Enroll(
+	ctx context.Context,
+	dB db.DB,
+	username string,
+) (*dtos.MFAEnrollmentResponse, error) {
+	ctx, span := tracing.Start(ctx, "MFAService.Enroll")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByUsername(ctx, dB, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, apperr.NewConflict("mfa", user.Username)
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, apperr.NewInternal("failed to generate mfa secret")
+	}
+
+	user.MFASecret = secret
+	user.MFALastStep = 0
+	if err := s.store.UserDomain.CreateUser(ctx, dB, user); err != nil {
+		return nil, err
+	}
+
+	return &dtos.MFAEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPAuthURI(MFAIssuer, user.Username, secret),
+	}, nil
+}
+
+func (s *mfaService) Confirm(
+	ctx context.Context,
+	dB db.DB,
+	username,
+	code string,
+) (*dtos.RecoveryCodesResponse, error) {
+	ctx, span := tracing.Start(ctx, "MFAService.Confirm")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByUsername(ctx, dB, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, apperr.NewConflict("mfa", user.Username)
+	}
+
+	if user.MFASecret == "" {
+		return nil, apperr.NewBadRequest("mfa enrolment has not been started")
+	}
+
+	step, ok := utils.ValidateTOTP(user.MFASecret, code, time.Now())
+	if !ok {
+		return nil, apperr.NewBadRequest("invalid mfa code")
+	}
+
+	var recoveryCodes []string
+	err = dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		user.MFAEnabled = true
+		user.MFALastStep = step
+		if err := s.store.UserDomain.CreateUser(ctx, ops, user); err != nil {
+			return err
+		}
+
+		var err error
+		recoveryCodes, err = s.issueRecoveryCodes(ctx, ops, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// Disable turns MFA off once a code is confirmed. A wrong code counts towards the login lockout,
+// so a stolen access token cannot be used to guess codes.
+func (s *mfaService) Disable(
+	ctx context.Context,
+	dB db.DB,
+	username,
+	code,
+	ipAddress string,
+) error {
+	ctx, span := tracing.Start(ctx, "MFAService.Disable")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByUsername(ctx, dB, username)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFAEnabled {
+		return apperr.NewBadRequest("mfa is not enabled")
+	}
+
+	if user.IsLocked() {
+		return apperr.NewAccountLocked(*user.LockedUntil)
+	}
+
+	ok, err := s.verifyCode(ctx, dB, user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return registerFailedLogin(ctx, s.store, dB, user, ipAddress, "invalid mfa code")
+	}
+
+	return dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		user.MFAEnabled = false
+		user.MFASecret = ""
+		user.MFALastStep = 0
+		if err := s.store.UserDomain.CreateUser(ctx, ops, user); err != nil {
+			return err
+		}
+
+		return s.store.RecoveryCodeDomain.DeleteRecoveryCodesByUserID(ctx, ops, user.ID)
+	})
+}
+
+// VerifyChallenge completes a two-step login for a user whose password was already checked.
+func (s *mfaService) VerifyChallenge(
+	ctx context.Context,
+	dB db.DB,
+	username,
+	code,
+	ipAddress string,
+) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "MFAService.VerifyChallenge")
+	defer span.End()
+
+	if err := checkLoginThrottle(ctx, s.store, dB, ipAddress); err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.UserDomain.GetUserByUsername(ctx, dB, username)
+	if err != nil || user == nil {
+		return nil, apperr.NewAuthorization("invalid mfa challenge")
+	}
+
+	if !user.IsActive {
+		return nil, apperr.NewAuthorization("account is inactive")
+	}
+
+	if user.IsLocked() {
+		return nil, apperr.NewAccountLocked(*user.LockedUntil)
+	}
+
+	if !user.MFAEnabled {
+		return nil, apperr.NewAuthorization("invalid mfa challenge")
+	}
+
+	ok, err := s.verifyCode(ctx, dB, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, registerFailedLogin(ctx, s.store, dB, user, ipAddress, "invalid mfa code")
+	}
+
+	if err := registerSuccessfulLogin(ctx, s.store, dB, user, ipAddress); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// verifyCode accepts a TOTP code that has not been used before, or an unused recovery code.
+func (s *mfaService) verifyCode(
+	ctx context.Context,
+	dB db.DB,
+	user *models.User,
+	code string,
+) (bool, error) {
+
+	// a step at or before the last one used is refused here; UseMFAStep refuses it again in the
+	// database, where concurrent logins cannot both pass
+	step, ok := utils.ValidateTOTP(user.MFASecret, code, time.Now())
+	if ok && step > user.MFALastStep {
+		used, err := s.store.UserDomain.UseMFAStep(ctx, dB, user, step)
+		if err != nil || used {
+			return used, err
+		}
+	}
+
+	return s.store.RecoveryCodeDomain.UseRecoveryCode(ctx, dB, user.ID, utils.HashToken(normalizeRecoveryCode(code)))
+}
+
+func (s *mfaService) issueRecoveryCodes(
+	ctx context.Context,
+	ops db.SQLOperations,
+	userID int64,
+) ([]string, error) {
+
+	if err := s.store.RecoveryCodeDomain.DeleteRecoveryCodesByUserID(ctx, ops, userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		token, err := utils.GenerateRandomToken(5)
+		if err != nil {
+			return nil, apperr.NewInternal("failed to generate recovery codes")
+		}
+
+		recoveryCode := &models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(token),
+		}
+		if err := s.store.RecoveryCodeDomain.CreateRecoveryCode(ctx, ops, recoveryCode); err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, token[:5]+"-"+token[5:])
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+// mfaStepUserDomain keeps the last used TOTP step the way the users row does.
+type mfaStepUserDomain struct {
+	domain.UserDomain
+	lastStep int64
+	calls    int
+}
+
+func (d *mfaStepUserDomain) UseMFAStep(_ context.Context, _ db.SQLOperations, user *models.User, step int64) (bool, error) {
+	d.calls++
+	if step <= d.lastStep {
+		return false, nil
+	}
+	d.lastStep = step
+	user.MFALastStep = step
+	return true, nil
+}
+
+// noRecoveryCodes matches no recovery code.
+type noRecoveryCodes struct {
+	domain.RecoveryCodeDomain
+}
+
+func (noRecoveryCodes) UseRecoveryCode(context.Context, db.SQLOperations, int64, string) (bool, error) {
+	return false, nil
+}
+
+func newMFATestService(lastStep int64) (*mfaService, *mfaStepUserDomain, *models.User) {
+	users := &mfaStepUserDomain{lastStep: lastStep}
+	service := &mfaService{store: &domain.Store{UserDomain: users, RecoveryCodeDomain: noRecoveryCodes{}}}
+	user := &models.User{MFASecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", MFALastStep: lastStep}
+	return service, users, user
+}
+
+func totpCodeAt(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestVerifyCodeAcceptsATOTPCodeOnce(t *testing.T) {
+	current := time.Now().Unix() / 30
+	service, users, user := newMFATestService(current - 1)
+	code := totpCodeAt(t, user.MFASecret, current)
+
+	ok, err := service.verifyCode(context.Background(), nil, user, code)
+	if err != nil || !ok {
+		t.Fatalf("first use of the code: ok = %v, err = %v; want accepted", ok, err)
+	}
+	if user.MFALastStep <= current-1 {
+		t.Errorf("mfa_last_step = %d, want it moved past %d", user.MFALastStep, current-1)
+	}
+
+	ok, err = service.verifyCode(context.Background(), nil, user, code)
+	if err != nil || ok {
+		t.Errorf("second use of the code: ok = %v, err = %v; want rejected", ok, err)
+	}
+	if users.lastStep != user.MFALastStep {
+		t.Errorf("stored step %d and user step %d differ", users.lastStep, user.MFALastStep)
+	}
+}
+
+// A code for the last used step, or one before it, is refused without touching the stored step.
+func TestVerifyCodeRejectsStepsAtOrBeforeTheLastUsed(t *testing.T) {
+	current := time.Now().Unix() / 30
+
+	for _, step := range []int64{current, current - 1} {
+		service, users, user := newMFATestService(current)
+		ok, err := service.verifyCode(context.Background(), nil, user, totpCodeAt(t, user.MFASecret, step))
+		if err != nil || ok {
+			t.Errorf("code for step %d with last step %d: ok = %v, err = %v; want rejected", step, current, ok, err)
+		}
+		if users.calls != 0 || user.MFALastStep != current {
+			t.Errorf("code for step %d used the step: calls = %d, mfa_last_step = %d", step, users.calls, user.MFALastStep)
+		}
+	}
+}
+
+// Two logins with the same code race past the in-memory check; the stored step lets one through.
+func TestVerifyCodeConcurrentUseOfTheSameStep(t *testing.T) {
+	current := time.Now().Unix() / 30
+	service, _, first := newMFATestService(current - 1)
+	second := *first
+	code := totpCodeAt(t, first.MFASecret, current)
+
+	firstOK, _ := service.verifyCode(context.Background(), nil, first, code)
+	secondOK, _ := service.verifyCode(context.Background(), nil, &second, code)
+	if firstOK == secondOK {
+		t.Errorf("accepted = %v and %v, want exactly one", firstOK, secondOK)
+	}
+}
@@ -0,0 +1,421 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/null"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+// RemittanceCSVHeader is the header row of a remittance export.
+var RemittanceCSVHeader = []string{
+	"claim_id",
+	"member_id",
+	"procedure_code",
+	"service_date",
+	"status",
+	"currency",
+	"requested_amount",
+	"exchange_rate",
+	"payment_currency",
+	"converted_amount",
+	"approved_amount",
+	"adjustment_amount",
+	"adjustment_reason",
+}
+
+type PaymentService interface {
+	CreatePaymentBatches(ctx context.Context, dB db.DB, form *dtos.PaymentBatchRequest) ([]*models.PaymentBatch, error)
+	GetPaymentBatchByID(ctx context.Context, dB db.DB, id int64) (*models.PaymentBatch, error)
+	GetPaymentBatches(ctx context.Context, dB db.DB, filter *models.Filter) (*models.PaymentBatchList, error)
+	ApprovePaymentBatch(ctx context.Context, dB db.DB, id int64, approvedBy string) (*models.PaymentBatch, error)
+	PayPaymentBatch(ctx context.Context, dB db.DB, id int64, form *dtos.PaymentBatchPaymentForm) (*models.PaymentBatch, error)
+	DeletePaymentBatch(ctx context.Context, dB db.DB, id int64) error
+	GetProviderStatement(ctx context.Context, dB db.DB, providerID int64, from, to time.Time) (*models.ProviderStatement, error)
+}
+
+type paymentService struct {
+	store *domain.Store
+}
+
+func NewPaymentService(store *domain.Store) PaymentService {
+	return &paymentService{store: store}
+}
+
+// CreatePaymentBatches drafts one batch per provider and payment currency holding that provider's
+// payable claims. Claims that are flagged for fraud or missing a required document are left out
+// until resolved.
+func (s *paymentService) CreatePaymentBatches(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.PaymentBatchRequest,
+) ([]*models.PaymentBatch, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.CreatePaymentBatches")
+	defer span.End()
+
+	submittedTo := today()
+	if strings.TrimSpace(form.SubmittedTo) != "" {
+		var err error
+		submittedTo, err = utils.ParseDate(strings.TrimSpace(form.SubmittedTo))
+		if err != nil {
+			return nil, apperr.NewBadRequest(fmt.Sprintf("submitted_to: %v", err))
+		}
+	}
+
+	batches := make([]*models.PaymentBatch, 0)
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+		if form.ProviderID != 0 {
+			if _, err := s.store.ProviderDomain.GetProviderByID(ctx, ops, form.ProviderID); err != nil {
+				return err
+			}
+		}
+
+		payees, err := s.store.ClaimDomain.GetPayableProviders(ctx, ops, submittedTo)
+		if err != nil {
+			return err
+		}
+
+		for _, payee := range payees {
+			if form.ProviderID != 0 && payee.ProviderID != form.ProviderID {
+				continue
+			}
+
+			batch := &models.PaymentBatch{
+				ProviderID:  payee.ProviderID,
+				Status:      custom_types.PaymentBatchStatusDraft,
+				TotalAmount: money.New(0, payee.Currency),
+				Currency:    payee.Currency,
+			}
+			if err := s.store.PaymentBatchDomain.CreatePaymentBatch(ctx, ops, batch); err != nil {
+				return err
+			}
+
+			count, total, err := s.store.ClaimDomain.AssignPayableClaimsToPaymentBatch(ctx, ops, batch.ID, payee, submittedTo)
+			if err != nil {
+				return err
+			}
+
+			if count == 0 {
+				if err := s.store.PaymentBatchDomain.DeletePaymentBatch(ctx, ops, batch.ID); err != nil {
+					return err
+				}
+				continue
+			}
+
+			batch.ClaimCount = count
+			batch.TotalAmount = total
+			if err := s.store.PaymentBatchDomain.CreatePaymentBatch(ctx, ops, batch); err != nil {
+				return err
+			}
+
+			batches = append(batches, batch)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+func (s *paymentService) GetPaymentBatchByID(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) (*models.PaymentBatch, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.GetPaymentBatchByID")
+	defer span.End()
+
+	return s.getPaymentBatch(ctx, dB, id)
+}
+
+func (s *paymentService) GetPaymentBatches(
+	ctx context.Context,
+	dB db.DB,
+	filter *models.Filter,
+) (*models.PaymentBatchList, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.GetPaymentBatches")
+	defer span.End()
+
+	if status := custom_types.PaymentBatchStatus(null.ValueFromNull(filter.Status)); status != "" && !status.IsValid() {
+		return nil, apperr.NewBadRequest(fmt.Sprintf("invalid status [%v]", status))
+	}
+
+	batches, err := s.store.PaymentBatchDomain.GetPaymentBatches(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.PaymentBatchDomain.GetPaymentBatchesCount(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PaymentBatchList{
+		Batches:    batches,
+		Pagination: models.NewPagination(count, filter.Page, filter.Per),
+	}, nil
+}
+
+func (s *paymentService) ApprovePaymentBatch(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+	approvedBy string,
+) (*models.PaymentBatch, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.ApprovePaymentBatch")
+	defer span.End()
+
+	var batch *models.PaymentBatch
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+		var err error
+		batch, err = s.store.PaymentBatchDomain.GetPaymentBatchByID(ctx, ops, id)
+		if err != nil {
+			return err
+		}
+
+		if batch.Status != custom_types.PaymentBatchStatusDraft {
+			return apperr.NewBadRequest(fmt.Sprintf("payment batch is already %v", batch.Status))
+		}
+		if err := s.checkBatchDocuments(ctx, ops, batch.ID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		batch.Status = custom_types.PaymentBatchStatusApproved
+		batch.ApprovedBy = approvedBy
+		batch.ApprovedAt = &now
+		return s.store.PaymentBatchDomain.CreatePaymentBatch(ctx, ops, batch)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getPaymentBatch(ctx, dB, id)
+}
+
+// PayPaymentBatch records the payment for an approved batch and marks each of its claims paid.
+// A payment reference can only be used for one batch.
+func (s *paymentService) PayPaymentBatch(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+	form *dtos.PaymentBatchPaymentForm,
+) (*models.PaymentBatch, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.PayPaymentBatch")
+	defer span.End()
+
+	reference := strings.TrimSpace(form.PaymentReference)
+	if reference == "" {
+		return nil, apperr.NewBadRequest("payment_reference is required")
+	}
+
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+		batch, err := s.store.PaymentBatchDomain.GetPaymentBatchByID(ctx, ops, id)
+		if err != nil {
+			return err
+		}
+
+		if batch.Status != custom_types.PaymentBatchStatusApproved {
+			return apperr.NewBadRequest(fmt.Sprintf("only approved payment batches can be paid; batch is %v", batch.Status))
+		}
+		if err := s.checkBatchDocuments(ctx, ops, batch.ID); err != nil {
+			return err
+		}
+
+		existing, err := s.store.PaymentBatchDomain.GetPaymentBatchByReference(ctx, ops, reference)
+		if err == nil && existing.ID != batch.ID {
+			return apperr.NewConflict("payment reference", reference)
+		}
+		if err != nil && !apperr.IsNoRowsErr(err) {
+			return err
+		}
+
+		now := time.Now()
+		batch.Status = custom_types.PaymentBatchStatusPaid
+		batch.PaymentReference = reference
+		batch.PaidAt = &now
+		if err := s.store.PaymentBatchDomain.CreatePaymentBatch(ctx, ops, batch); err != nil {
+			return err
+		}
+
+		return s.store.ClaimDomain.MarkPaymentBatchClaimsPaid(ctx, ops, batch.ID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getPaymentBatch(ctx, dB, id)
+}
+
+// checkBatchDocuments refuses to approve or pay a batch while any of its claims lacks a document
+// its procedure requires. Claims missing one are never drafted into a batch, but a requirement can
+// be added to the procedure afterwards.
+func (s *paymentService) checkBatchDocuments(
+	ctx context.Context,
+	ops db.SQLOperations,
+	batchID int64,
+) error {
+
+	claimIDs, err := s.store.ClaimDomain.GetPaymentBatchClaimsMissingDocuments(ctx, ops, batchID)
+	if err != nil {
+		return err
+	}
+	if len(claimIDs) > 0 {
+		return apperr.NewBadRequest(fmt.Sprintf("claims %v are missing required documents; upload them first", claimIDs))
+	}
+
+	return nil
+}
+
+// DeletePaymentBatch discards a draft batch; its claims become payable again.
+func (s *paymentService) DeletePaymentBatch(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) error {
+	ctx, span := tracing.Start(ctx, "PaymentService.DeletePaymentBatch")
+	defer span.End()
+
+	return dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+		batch, err := s.store.PaymentBatchDomain.GetPaymentBatchByID(ctx, ops, id)
+		if err != nil {
+			return err
+		}
+
+		if batch.Status != custom_types.PaymentBatchStatusDraft {
+			return apperr.NewBadRequest(fmt.Sprintf("only draft payment batches can be deleted; batch is %v", batch.Status))
+		}
+
+		return s.store.PaymentBatchDomain.DeletePaymentBatch(ctx, ops, batch.ID)
+	})
+}
+
+// GetProviderStatement lists the provider's claims submitted from from to to inclusive. Outstanding
+// is the approved amount not yet paid, including claims held back for fraud review or documents.
+func (s *paymentService) GetProviderStatement(
+	ctx context.Context,
+	dB db.DB,
+	providerID int64,
+	from, to time.Time,
+) (*models.ProviderStatement, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.GetProviderStatement")
+	defer span.End()
+
+	if to.Before(from) {
+		return nil, apperr.NewBadRequest("from must not be after to")
+	}
+
+	provider, err := s.store.ProviderDomain.GetProviderByID(ctx, dB, providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.store.ClaimDomain.GetProviderStatementLines(ctx, dB, providerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &models.ProviderStatement{
+		Provider:       provider,
+		From:           utils.FormatDate(from),
+		To:             utils.FormatDate(to),
+		Claims:         withAdjustments(lines),
+		TotalRequested: money.Totals{},
+		TotalApproved:  money.Totals{},
+		TotalPaid:      money.Totals{},
+		Outstanding:    money.Totals{},
+	}
+	for _, line := range statement.Claims {
+		statement.TotalRequested.Add(line.ConvertedAmount)
+		statement.TotalApproved.Add(line.ApprovedAmount)
+		statement.Outstanding.Add(line.ApprovedAmount)
+		if line.PaidAt != nil {
+			statement.TotalPaid.Add(line.ApprovedAmount)
+			statement.Outstanding.Add(line.ApprovedAmount.Neg())
+		}
+	}
+
+	return statement, nil
+}
+
+func (s *paymentService) getPaymentBatch(
+	ctx context.Context,
+	operations db.SQLOperations,
+	id int64,
+) (*models.PaymentBatch, error) {
+
+	batch, err := s.store.PaymentBatchDomain.GetPaymentBatchByID(ctx, operations, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.store.ClaimDomain.GetRemittanceLinesByPaymentBatchID(ctx, operations, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	batch.Claims = withAdjustments(lines)
+
+	return batch, nil
+}
+
+// WriteRemittanceCSV writes one row per claim in the batch with its approved amount and adjustment.
+func WriteRemittanceCSV(w io.Writer, batch *models.PaymentBatch) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(RemittanceCSVHeader); err != nil {
+		return err
+	}
+
+	for _, line := range batch.Claims {
+		err := writer.Write([]string{
+			strconv.FormatInt(line.ClaimID, 10),
+			strconv.FormatInt(line.MemberID, 10),
+			line.ProcedureCode,
+			utils.FormatDate(line.ServiceDate),
+			string(line.Status),
+			line.Currency,
+			line.RequestedAmount.String(),
+			line.ExchangeRate.String(),
+			line.PaymentCurrency,
+			line.ConvertedAmount.String(),
+			line.ApprovedAmount.String(),
+			line.AdjustmentAmount.String(),
+			line.AdjustmentReason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// withAdjustments fills in the part of each converted requested amount that was not approved.
+// Fully approved claims carry no adjustment reason.
+func withAdjustments(lines []*models.RemittanceLine) []*models.RemittanceLine {
+	for _, line := range lines {
+		line.AdjustmentAmount = line.ConvertedAmount.Sub(line.ApprovedAmount)
+		if !line.AdjustmentAmount.IsPositive() {
+			line.AdjustmentAmount = money.New(0, line.PaymentCurrency)
+			line.AdjustmentReason = ""
+		}
+	}
+	return lines
+}
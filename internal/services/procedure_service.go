@@ -2,16 +2,24 @@ package services
 
 import (
 	"context"
+	"strings"
+	"time"
 
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
 	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 )
 
 type (
 	ProcedureService interface {
 		CreateProcedure(ctx context.Context, dB db.DB, form *dtos.Procedure) (*models.Procedure, error)
+		AddProcedurePrice(ctx context.Context, dB db.DB, code string, form *dtos.ProcedurePrice) (*models.ProcedureVersion, error)
+		GetProcedureHistory(ctx context.Context, dB db.DB, code string) (*models.ProcedureHistory, error)
 	}
 
 	procedureService struct {
@@ -29,13 +37,55 @@ func (s *procedureService) CreateProcedure(
 	dB db.DB, 
 	form *dtos.Procedure,
 ) (*models.Procedure, error) {
+	ctx, span := tracing.Start(ctx, "ProcedureService.CreateProcedure")
+	defer span.End()
 
 	procedure := &models.Procedure{
-		Code:        form.Code,
+		Code:        strings.TrimSpace(form.Code),
 		Description: form.Description,
 		AverageCost: form.AverageCost,
+		Currency:    strings.ToUpper(strings.TrimSpace(form.Currency)),
 	}
-	err := s.store.ProcedureDomain.CreateProcedure(ctx, dB, procedure)
+	if procedure.Code == "" {
+		return nil, apperr.NewBadRequest("code is required")
+	}
+	if procedure.Currency == "" {
+		procedure.Currency = money.DefaultCurrency
+	}
+	if !money.IsCurrency(procedure.Currency) {
+		return nil, apperr.NewBadRequest("currency must be one of " + strings.Join(money.Currencies, ", "))
+	}
+	procedure.AverageCost.Currency = procedure.Currency
+	if !procedure.AverageCost.IsPositive() {
+		return nil, apperr.NewBadRequest("average_cost must be greater than zero")
+	}
+	if !procedure.AverageCost.FitsColumn() {
+		return nil, amountOutOfRange("average_cost")
+	}
+
+	effectiveFrom := today()
+	if strings.TrimSpace(form.EffectiveFrom) != "" {
+		var err error
+		effectiveFrom, err = utils.ParseDate(strings.TrimSpace(form.EffectiveFrom))
+		if err != nil {
+			return nil, apperr.NewBadRequest(err.Error())
+		}
+	}
+
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		if err := s.store.ProcedureDomain.CreateProcedure(ctx, ops, procedure); err != nil {
+			return err
+		}
+
+		return s.store.ProcedureVersionDomain.CreateProcedureVersion(ctx, ops, &models.ProcedureVersion{
+			ProcedureID:   procedure.ID,
+			ProcedureCode: procedure.Code,
+			AverageCost:   procedure.AverageCost,
+			Currency:      procedure.Currency,
+			EffectiveFrom: effectiveFrom,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -43,3 +93,114 @@ func (s *procedureService) CreateProcedure(
 	return procedure, nil
 }
 
+// AddProcedurePrice appends a new price version. The previous version is closed the day before the
+// new one takes effect, so prices already used by claims are never changed.
+func (s *procedureService) AddProcedurePrice(
+	ctx context.Context,
+	dB db.DB,
+	code string,
+	form *dtos.ProcedurePrice,
+) (*models.ProcedureVersion, error) {
+	ctx, span := tracing.Start(ctx, "ProcedureService.AddProcedurePrice")
+	defer span.End()
+
+	if !form.AverageCost.IsPositive() {
+		return nil, apperr.NewBadRequest("average_cost must be greater than zero")
+	}
+	if !form.AverageCost.FitsColumn() {
+		return nil, amountOutOfRange("average_cost")
+	}
+
+	effectiveFrom, err := utils.ParseDate(strings.TrimSpace(form.EffectiveFrom))
+	if err != nil {
+		return nil, apperr.NewBadRequest(err.Error())
+	}
+
+	procedure, err := s.store.ProcedureDomain.GetProcedureByCode(ctx, dB, code)
+	if err != nil {
+		return nil, err
+	}
+
+	var version *models.ProcedureVersion
+	err = dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+		var err error
+		version, err = appendProcedureVersion(ctx, s.store, ops, procedure, form.AverageCost, effectiveFrom)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+func (s *procedureService) GetProcedureHistory(
+	ctx context.Context,
+	dB db.DB,
+	code string,
+) (*models.ProcedureHistory, error) {
+	ctx, span := tracing.Start(ctx, "ProcedureService.GetProcedureHistory")
+	defer span.End()
+
+	procedure, err := s.store.ProcedureDomain.GetProcedureByCode(ctx, dB, code)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.store.ProcedureVersionDomain.GetProcedureVersions(ctx, dB, procedure.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProcedureHistory{
+		Procedure: procedure,
+		Versions:  versions,
+	}, nil
+}
+
+// appendProcedureVersion adds a price version effective from the given date and closes the
+// procedure's latest version the day before. The cost is in the procedure's currency.
+func appendProcedureVersion(
+	ctx context.Context,
+	store *domain.Store,
+	operations db.SQLOperations,
+	procedure *models.Procedure,
+	averageCost money.Money,
+	effectiveFrom time.Time,
+) (*models.ProcedureVersion, error) {
+
+	latest, err := store.ProcedureVersionDomain.GetLatestProcedureVersion(ctx, operations, procedure.ID)
+	if err != nil && !apperr.IsNoRowsErr(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		if !effectiveFrom.After(latest.EffectiveFrom) {
+			return nil, apperr.NewBadRequest("effective_from must be after the current version's effective_from")
+		}
+
+		effectiveTo := effectiveFrom.AddDate(0, 0, -1)
+		latest.EffectiveTo = &effectiveTo
+		if err := store.ProcedureVersionDomain.CreateProcedureVersion(ctx, operations, latest); err != nil {
+			return nil, err
+		}
+	}
+
+	version := &models.ProcedureVersion{
+		ProcedureID:   procedure.ID,
+		ProcedureCode: procedure.Code,
+		AverageCost:   money.New(averageCost.Amount, procedure.Currency),
+		Currency:      procedure.Currency,
+		EffectiveFrom: effectiveFrom,
+	}
+	if err := store.ProcedureVersionDomain.CreateProcedureVersion(ctx, operations, version); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// today returns the current date at midnight UTC, matching dates parsed by utils.ParseDate.
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
@@ -2,16 +2,27 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
 	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 )
 
 type (
 	ProviderService interface {
 		CreateProvider(ctx context.Context, dB db.DB, form *dtos.Provider) (*models.Provider, error)
+		GetProviderByID(ctx context.Context, dB db.DB, id int64) (*models.Provider, error)
+		GetProviders(ctx context.Context, dB db.DB, filter *models.Filter) (*models.ProviderList, error)
+		UpdateProvider(ctx context.Context, dB db.DB, id int64, form *dtos.UpdateProviderRequest) (*models.Provider, error)
+		DeleteProvider(ctx context.Context, dB db.DB, id int64) error
 	}
 
 	providerService struct {
@@ -23,17 +34,39 @@ func NewProviderService(store *domain.Store) ProviderService {
 	return &providerService{store: store}
 }
 func (s *providerService) CreateProvider(
-ctx context.Context, 
-dB db.DB, 
+ctx context.Context,
+dB db.DB,
 form *dtos.Provider,
 ) (*models.Provider, error) {
+	ctx, span := tracing.Start(ctx, "ProviderService.CreateProvider")
+	defer span.End()
 
 	provider := &models.Provider{
-		Name:     form.Name,
-		Location: form.Location,
+		Name:          strings.TrimSpace(form.Name),
+		Location:      form.Location,
+		LicenceNumber: strings.TrimSpace(form.LicenceNumber),
+		NetworkTier:   custom_types.ProviderNetworkTierInNetwork,
+		Status:        custom_types.ProviderStatusActive,
 	}
 
-	err := s.store.ProviderDomain.CreateProvider(ctx, dB, provider)
+	if form.NetworkTier != "" {
+		provider.NetworkTier = custom_types.ProviderNetworkTier(form.NetworkTier)
+	}
+	if form.Status != "" {
+		provider.Status = custom_types.ProviderStatus(form.Status)
+	}
+
+	accreditationExpiry, err := parseOptionalDate(form.AccreditationExpiry)
+	if err != nil {
+		return nil, err
+	}
+	provider.AccreditationExpiry = accreditationExpiry
+
+	if err := s.validateProvider(ctx, dB, provider); err != nil {
+		return nil, err
+	}
+
+	err = s.store.ProviderDomain.CreateProvider(ctx, dB, provider)
 	if err != nil {
 		return nil, err
 	}
@@ -41,3 +74,149 @@ form *dtos.Provider,
 	return provider, nil
 }
 
+func (s *providerService) GetProviderByID(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) (*models.Provider, error) {
+	ctx, span := tracing.Start(ctx, "ProviderService.GetProviderByID")
+	defer span.End()
+
+	return s.store.ProviderDomain.GetProviderByID(ctx, dB, id)
+}
+
+func (s *providerService) GetProviders(
+	ctx context.Context,
+	dB db.DB,
+	filter *models.Filter,
+) (*models.ProviderList, error) {
+	ctx, span := tracing.Start(ctx, "ProviderService.GetProviders")
+	defer span.End()
+
+	providers, err := s.store.ProviderDomain.GetProviders(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.ProviderDomain.GetProvidersCount(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProviderList{
+		Providers:  providers,
+		Pagination: models.NewPagination(count, filter.Page, filter.Per),
+	}, nil
+}
+
+func (s *providerService) UpdateProvider(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+	form *dtos.UpdateProviderRequest,
+) (*models.Provider, error) {
+	ctx, span := tracing.Start(ctx, "ProviderService.UpdateProvider")
+	defer span.End()
+
+	provider, err := s.store.ProviderDomain.GetProviderByID(ctx, dB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.Name != nil {
+		provider.Name = strings.TrimSpace(*form.Name)
+	}
+	if form.Location != nil {
+		provider.Location = *form.Location
+	}
+	if form.LicenceNumber != nil {
+		provider.LicenceNumber = strings.TrimSpace(*form.LicenceNumber)
+	}
+	if form.AccreditationExpiry != nil {
+		provider.AccreditationExpiry, err = parseOptionalDate(*form.AccreditationExpiry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if form.NetworkTier != nil {
+		provider.NetworkTier = custom_types.ProviderNetworkTier(*form.NetworkTier)
+	}
+	if form.Status != nil {
+		provider.Status = custom_types.ProviderStatus(*form.Status)
+	}
+
+	if err := s.validateProvider(ctx, dB, provider); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.ProviderDomain.CreateProvider(ctx, dB, provider); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// DeleteProvider only removes providers without claims; providers with history should be suspended.
+func (s *providerService) DeleteProvider(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) error {
+	ctx, span := tracing.Start(ctx, "ProviderService.DeleteProvider")
+	defer span.End()
+
+	provider, err := s.store.ProviderDomain.GetProviderByID(ctx, dB, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.store.ClaimDomain.GetClaimByProviderID(ctx, dB, fmt.Sprint(provider.ID))
+	if err == nil {
+		return apperr.NewBadRequest("provider has claims and cannot be deleted; suspend it instead")
+	}
+	if !apperr.IsNoRowsErr(err) {
+		return err
+	}
+
+	return s.store.ProviderDomain.DeleteProvider(ctx, dB, provider.ID)
+}
+
+func (s *providerService) validateProvider(
+	ctx context.Context,
+	operations db.SQLOperations,
+	provider *models.Provider,
+) error {
+
+	if provider.Name == "" {
+		return apperr.NewBadRequest("name is required")
+	}
+	if !provider.NetworkTier.IsValid() {
+		return apperr.NewBadRequest(fmt.Sprintf("invalid network_tier [%v]", provider.NetworkTier))
+	}
+	if !provider.Status.IsValid() {
+		return apperr.NewBadRequest(fmt.Sprintf("invalid status [%v]", provider.Status))
+	}
+
+	if provider.LicenceNumber != "" {
+		existing, err := s.store.ProviderDomain.GetProviderByLicenceNumber(ctx, operations, provider.LicenceNumber)
+		if err == nil && existing.ID != provider.ID {
+			return apperr.NewConflict("licence_number", provider.LicenceNumber)
+		}
+	}
+
+	return nil
+}
+
+func parseOptionalDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := utils.ParseDate(value)
+	if err != nil {
+		return nil, apperr.NewBadRequest(err.Error())
+	}
+
+	return &date, nil
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+)
+
+type TariffService interface {
+	CreateTariff(ctx context.Context, dB db.DB, form *dtos.ProviderTariff) (*models.ProviderTariff, error)
+	GetTariffByID(ctx context.Context, dB db.DB, id int64) (*models.ProviderTariff, error)
+	GetTariffs(ctx context.Context, dB db.DB, filter *models.Filter) (*models.ProviderTariffList, error)
+	UpdateTariff(ctx context.Context, dB db.DB, id int64, form *dtos.UpdateProviderTariffRequest) (*models.ProviderTariff, error)
+	DeleteTariff(ctx context.Context, dB db.DB, id int64) error
+	ImportTariffs(ctx context.Context, dB db.DB, reader io.Reader, options *dtos.ImportOptions) (*dtos.ImportResult, error)
+}
+
+type tariffService struct {
+	store *domain.Store
+}
+
+func NewTariffService(store *domain.Store) TariffService {
+	return &tariffService{store: store}
+}
+
+func (s *tariffService) CreateTariff(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.ProviderTariff,
+) (*models.ProviderTariff, error) {
+	ctx, span := tracing.Start(ctx, "TariffService.CreateTariff")
+	defer span.End()
+
+	tariff, err := tariffFromForm(form)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateTariff(ctx, dB, tariff); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.ProviderTariffDomain.CreateProviderTariff(ctx, dB, tariff); err != nil {
+		return nil, err
+	}
+
+	return tariff, nil
+}
+
+func (s *tariffService) GetTariffByID(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) (*models.ProviderTariff, error) {
+	ctx, span := tracing.Start(ctx, "TariffService.GetTariffByID")
+	defer span.End()
+
+	return s.store.ProviderTariffDomain.GetProviderTariffByID(ctx, dB, id)
+}
+
+func (s *tariffService) GetTariffs(
+	ctx context.Context,
+	dB db.DB,
+	filter *models.Filter,
+) (*models.ProviderTariffList, error) {
+	ctx, span := tracing.Start(ctx, "TariffService.GetTariffs")
+	defer span.End()
+
+	tariffs, err := s.store.ProviderTariffDomain.GetProviderTariffs(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.ProviderTariffDomain.GetProviderTariffsCount(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProviderTariffList{
+		Tariffs:    tariffs,
+		Pagination: models.NewPagination(count, filter.Page, filter.Per),
+	}, nil
+}
+
+func (s *tariffService) UpdateTariff(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+	form *dtos.UpdateProviderTariffRequest,
+) (*models.ProviderTariff, error) {
+	ctx, span := tracing.Start(ctx, "TariffService.UpdateTariff")
+	defer span.End()
+
+	tariff, err := s.store.ProviderTariffDomain.GetProviderTariffByID(ctx, dB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.AgreedPrice != nil {
+		tariff.AgreedPrice = *form.AgreedPrice
+	}
+	if form.Currency != nil {
+		tariff.Currency = strings.ToUpper(strings.TrimSpace(*form.Currency))
+	}
+	if form.EffectiveFrom != nil {
+		tariff.EffectiveFrom, err = utils.ParseDate(strings.TrimSpace(*form.EffectiveFrom))
+		if err != nil {
+			return nil, apperr.NewBadRequest(err.Error())
+		}
+	}
+	if form.EffectiveTo != nil {
+		tariff.EffectiveTo, err = parseOptionalDate(*form.EffectiveTo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.validateTariff(ctx, dB, tariff); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.ProviderTariffDomain.CreateProviderTariff(ctx, dB, tariff); err != nil {
+		return nil, err
+	}
+
+	return tariff, nil
+}
+
+func (s *tariffService) DeleteTariff(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) error {
+	ctx, span := tracing.Start(ctx, "TariffService.DeleteTariff")
+	defer span.End()
+
+	tariff, err := s.store.ProviderTariffDomain.GetProviderTariffByID(ctx, dB, id)
+	if err != nil {
+		return err
+	}
+
+	return s.store.ProviderTariffDomain.DeleteProviderTariff(ctx, dB, tariff.ID)
+}
+
+// ImportTariffs loads tariffs from a CSV file with the columns provider_id, procedure_code,
+// agreed_price, effective_from and effective_to, and optionally currency.
+func (s *tariffService) ImportTariffs(
+	ctx context.Context,
+	dB db.DB,
+	reader io.Reader,
+	options *dtos.ImportOptions,
+) (*dtos.ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "TariffService.ImportTariffs")
+	defer span.End()
+
+	requiredColumns := []string{"provider_id", "procedure_code", "agreed_price", "effective_from"}
+	return runCSVImport(ctx, dB, reader, options, requiredColumns, s.importTariffRow)
+}
+
+func (s *tariffService) importTariffRow(
+	ctx context.Context,
+	ops db.SQLOperations,
+	row *utils.CSVRow,
+) (bool, error) {
+
+	providerID, err := strconv.ParseInt(row.Get("provider_id"), 10, 64)
+	if err != nil {
+		return false, apperr.NewBadRequest(fmt.Sprintf("invalid provider_id [%v]", row.Get("provider_id")))
+	}
+
+	agreedPrice, err := money.Parse(row.Get("agreed_price"))
+	if err != nil {
+		return false, apperr.NewBadRequest(fmt.Sprintf("invalid agreed_price [%v]", row.Get("agreed_price")))
+	}
+
+	tariff, err := tariffFromForm(&dtos.ProviderTariff{
+		ProviderID:    providerID,
+		ProcedureCode: row.Get("procedure_code"),
+		AgreedPrice:   agreedPrice,
+		Currency:      row.Get("currency"),
+		EffectiveFrom: row.Get("effective_from"),
+		EffectiveTo:   row.Get("effective_to"),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.validateTariff(ctx, ops, tariff); err != nil {
+		return false, err
+	}
+
+	return true, s.store.ProviderTariffDomain.CreateProviderTariff(ctx, ops, tariff)
+}
+
+func (s *tariffService) validateTariff(
+	ctx context.Context,
+	operations db.SQLOperations,
+	tariff *models.ProviderTariff,
+) error {
+
+	if !tariff.AgreedPrice.IsPositive() {
+		return apperr.NewBadRequest("agreed_price must be greater than zero")
+	}
+	if !tariff.AgreedPrice.FitsColumn() {
+		return amountOutOfRange("agreed_price")
+	}
+	if tariff.EffectiveTo != nil && tariff.EffectiveTo.Before(tariff.EffectiveFrom) {
+		return apperr.NewBadRequest("effective_to cannot be before effective_from")
+	}
+
+	if _, err := s.store.ProviderDomain.GetProviderByID(ctx, operations, tariff.ProviderID); err != nil {
+		if apperr.IsNoRowsErr(err) {
+			return apperr.NewBadRequest(fmt.Sprintf("provider [%v] not found", tariff.ProviderID))
+		}
+		return err
+	}
+
+	procedure, err := s.store.ProcedureDomain.GetProcedureByCode(ctx, operations, tariff.ProcedureCode)
+	if err != nil {
+		if apperr.IsNoRowsErr(err) {
+			return apperr.NewBadRequest(fmt.Sprintf("procedure [%v] not found", tariff.ProcedureCode))
+		}
+		return err
+	}
+
+	if tariff.Currency == "" {
+		tariff.Currency = procedure.Currency
+	}
+	if !money.IsCurrency(tariff.Currency) {
+		return apperr.NewBadRequest("currency must be one of " + strings.Join(money.Currencies, ", "))
+	}
+	tariff.AgreedPrice.Currency = tariff.Currency
+
+	overlapping, err := s.store.ProviderTariffDomain.GetOverlappingProviderTariffsCount(ctx, operations, tariff)
+	if err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return apperr.NewBadRequest(fmt.Sprintf(
+			"tariff for provider [%v] and procedure [%v] overlaps an existing tariff period",
+			tariff.ProviderID,
+			tariff.ProcedureCode,
+		))
+	}
+
+	return nil
+}
+
+func tariffFromForm(form *dtos.ProviderTariff) (*models.ProviderTariff, error) {
+	procedureCode := strings.TrimSpace(form.ProcedureCode)
+	if procedureCode == "" {
+		return nil, apperr.NewBadRequest("procedure_code is required")
+	}
+
+	effectiveFrom, err := utils.ParseDate(strings.TrimSpace(form.EffectiveFrom))
+	if err != nil {
+		return nil, apperr.NewBadRequest(err.Error())
+	}
+
+	effectiveTo, err := parseOptionalDate(form.EffectiveTo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProviderTariff{
+		ProviderID:    form.ProviderID,
+		ProcedureCode: procedureCode,
+		AgreedPrice:   form.AgreedPrice,
+		Currency:      strings.ToUpper(strings.TrimSpace(form.Currency)),
+		EffectiveFrom: effectiveFrom,
+		EffectiveTo:   effectiveTo,
+	}, nil
+}
+
+// tariffPriceOn returns the negotiated price for the provider and procedure on the given date, if any.
+func tariffPriceOn(
+	ctx context.Context,
+	store *domain.Store,
+	operations db.SQLOperations,
+	providerID int64,
+	procedureCode string,
+	on time.Time,
+) (*models.ProviderTariff, error) {
+
+	tariff, err := store.ProviderTariffDomain.GetActiveProviderTariff(ctx, operations, providerID, procedureCode, on)
+	if err != nil {
+		if apperr.IsNoRowsErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return tariff, nil
+}
@@ -2,29 +2,68 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/configs"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
 	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+	"github.com/Doris-Mwito5/ginja-ai/internal/mailer"
 	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
 	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 )
 
+const (
+	// MaxFailedLogins locks an account once this many consecutive logins have failed.
+	MaxFailedLogins = 5
+	// LoginLockoutBase is the first lockout duration; it doubles with every further failure.
+	LoginLockoutBase = time.Minute
+	// LoginLockoutMax caps the exponential lockout duration.
+	LoginLockoutMax = 24 * time.Hour
+	// MaxFailedLoginsPerIP throttles a client IP after this many failures within LoginIPWindow.
+	MaxFailedLoginsPerIP = 20
+	LoginIPWindow        = 15 * time.Minute
+
+	PasswordResetTokenDuration     = time.Hour
+	EmailVerificationTokenDuration = 48 * time.Hour
+)
+
 type UserService interface {
 	Register(ctx context.Context, dB db.DB, form *dtos.RegisterRequest) (*models.User, error)
+	CreateAdmin(ctx context.Context, dB db.DB, form *dtos.RegisterRequest) (*models.User, error)
 	GetUserByID(ctx context.Context, dB db.DB, id int64) (*models.User, error)
 	GetUserByUsername(ctx context.Context, dB db.DB, username string) (*models.User, error)
-	ValidateCredentials(ctx context.Context, dB db.DB, username, password string) (*models.User, error)
+	ValidateCredentials(ctx context.Context, dB db.DB, username, password, ipAddress string) (*models.User, error)
+	UnlockUser(ctx context.Context, dB db.DB, id int64) (*models.User, error)
+	ChangePassword(ctx context.Context, dB db.DB, username string, form *dtos.ChangePasswordRequest, ipAddress string) (*models.User, error)
+	ForgotPassword(ctx context.Context, dB db.DB, form *dtos.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, dB db.DB, form *dtos.ResetPasswordRequest) error
+	VerifyEmail(ctx context.Context, dB db.DB, form *dtos.VerifyEmailRequest) (*models.User, error)
+	ResendEmailVerification(ctx context.Context, dB db.DB, username string) error
+	GetUsers(ctx context.Context, dB db.DB, filter *models.Filter) (*models.UserList, error)
+	UpdateUser(ctx context.Context, dB db.DB, id int64, form *dtos.UpdateUserRequest) (*models.User, error)
+	SetUserActive(ctx context.Context, dB db.DB, id int64, active bool) (*models.User, error)
+	ForcePasswordReset(ctx context.Context, dB db.DB, id int64) error
 }
 
 type userService struct {
-	store *domain.Store
+	store  *domain.Store
+	mailer mailer.Mailer
 }
 
-func NewUserService(store *domain.Store) UserService {
+func NewUserService(
+	store *domain.Store,
+	mailer mailer.Mailer,
+) UserService {
 	return &userService{
-		store: store,
+		store:  store,
+		mailer: mailer,
 	}
 }
 
@@ -32,6 +71,8 @@ func (s *userService) Register(
 	ctx context.Context, 
 	dB db.DB, form *dtos.RegisterRequest,
 	) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Register")
+	defer span.End()
 	
 	if form.Username == "" || form.Email == "" {
 		return nil, apperr.NewBadRequest("username and email are required")
@@ -57,6 +98,77 @@ func (s *userService) Register(
 		Email:        form.Email,
 		PasswordHash: passwordHash,
 		IsActive:     true,
+		Role:         custom_types.UserRoleUser,
+	}
+	if err := s.store.UserDomain.CreateUser(ctx, dB, user); err != nil {
+		return nil, err
+	}
+
+	// the account exists either way; a failed mail can be retried through the resend endpoint
+	if err := s.sendEmailVerification(ctx, dB, user); err != nil {
+		logger.FromContext(ctx).Errorf("send email verification for user [%v] failed: %v", user.ID, err)
+	}
+
+	return user, nil
+}
+
+// CreateAdmin bootstraps an administrator: an existing user with the username is promoted,
+// activated and unlocked (its password is kept), otherwise a new active admin is created with a
+// verified email. It is only reachable from the CLI, since no admin exists to call the API.
+func (s *userService) CreateAdmin(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.RegisterRequest,
+) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateAdmin")
+	defer span.End()
+
+	form.Username = strings.TrimSpace(form.Username)
+	form.Email = strings.TrimSpace(form.Email)
+	if form.Username == "" {
+		return nil, apperr.NewBadRequest("username is required")
+	}
+
+	user, err := s.store.UserDomain.GetUserByUsername(ctx, dB, form.Username)
+	if err != nil && !apperr.IsNoRowsErr(err) {
+		return nil, err
+	}
+
+	if user != nil {
+		user.Role = custom_types.UserRoleAdmin
+		user.IsActive = true
+		user.FailedLoginCount = 0
+		user.LockedUntil = nil
+		if err := s.store.UserDomain.CreateUser(ctx, dB, user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	if form.Email == "" {
+		return nil, apperr.NewBadRequest("email is required for a new user")
+	}
+	if len(form.Password) < 8 {
+		return nil, apperr.NewBadRequest("password must be at least 8 characters")
+	}
+
+	existing, _ := s.store.UserDomain.GetUserByEmail(ctx, dB, form.Email)
+	if existing != nil {
+		return nil, apperr.NewConflict("email", form.Email)
+	}
+
+	passwordHash, err := utils.HashPassword(form.Password)
+	if err != nil {
+		return nil, apperr.NewInternal("failed to process password")
+	}
+
+	user = &models.User{
+		Username:      form.Username,
+		Email:         form.Email,
+		PasswordHash:  passwordHash,
+		IsActive:      true,
+		EmailVerified: true,
+		Role:          custom_types.UserRoleAdmin,
 	}
 	if err := s.store.UserDomain.CreateUser(ctx, dB, user); err != nil {
 		return nil, err
@@ -70,6 +182,8 @@ func (s *userService) GetUserByID(
 	dB db.DB, 
 	id int64,
 ) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
 
 	user, err := s.store.UserDomain.GetUserByID(ctx, dB, id)
 	if err != nil {
@@ -84,6 +198,8 @@ func (s *userService) GetUserByUsername(
 	dB db.DB,
 	username string,
 ) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByUsername")
+	defer span.End()
 
 	user, err := s.store.UserDomain.GetUserByUsername(ctx, dB, username)
 	if err != nil {
@@ -97,11 +213,21 @@ func (s *userService) ValidateCredentials(
 	ctx context.Context,
 	dB db.DB,
 	username,
-	password string,
+	password,
+	ipAddress string,
 ) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.ValidateCredentials")
+	defer span.End()
+
+	if err := checkLoginThrottle(ctx, s.store, dB, ipAddress); err != nil {
+		return nil, err
+	}
 
 	user, err := s.store.UserDomain.GetUserByUsername(ctx, dB, username)
 	if err != nil || user == nil {
+		if err := recordLoginAttempt(ctx, s.store, dB, username, ipAddress, false); err != nil {
+			return nil, err
+		}
 		return nil, apperr.NewAuthorization("invalid username or password")
 	}
 
@@ -109,9 +235,510 @@ func (s *userService) ValidateCredentials(
 		return nil, apperr.NewAuthorization("account is inactive")
 	}
 
+	if user.IsLocked() {
+		return nil, apperr.NewAccountLocked(*user.LockedUntil)
+	}
+
 	err = utils.ValidatePassword(password, user.PasswordHash)
 	if err != nil {
-		return nil, apperr.NewAuthorization("invalid username or password")
+		return nil, registerFailedLogin(ctx, s.store, dB, user, ipAddress, "invalid username or password")
+	}
+
+	// enrolled users only count as logged in once the second factor is verified,
+	// otherwise a correct password would keep resetting the code attempts
+	if user.MFAEnabled {
+		return user, nil
+	}
+
+	if err := registerSuccessfulLogin(ctx, s.store, dB, user, ipAddress); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) UnlockUser(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UnlockUser")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByID(ctx, dB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UserDomain.ResetFailedLogins(ctx, dB, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangePassword revokes every token issued so far, so the caller needs a new one. A wrong
+// current password counts towards the login lockout like a failed login.
+func (s *userService) ChangePassword(
+	ctx context.Context,
+	dB db.DB,
+	username string,
+	form *dtos.ChangePasswordRequest,
+	ipAddress string,
+) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByUsername(ctx, dB, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsLocked() {
+		return nil, apperr.NewAccountLocked(*user.LockedUntil)
 	}
+
+	if err := utils.ValidatePassword(form.CurrentPassword, user.PasswordHash); err != nil {
+		return nil, registerFailedLogin(ctx, s.store, dB, user, ipAddress, "current password is incorrect")
+	}
+
+	if form.CurrentPassword == form.NewPassword {
+		return nil, apperr.NewBadRequest("new password must be different from the current password")
+	}
+
+	passwordHash, err := utils.HashPassword(form.NewPassword)
+	if err != nil {
+		return nil, apperr.NewInternal("failed to process password")
+	}
+
+	user.PasswordHash = passwordHash
+	user.RevokeTokens()
+
+	if err := s.store.UserDomain.CreateUser(ctx, dB, user); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
+
+// ForgotPassword mails a reset token. It succeeds for unknown emails too so
+// the endpoint cannot be used to find out which addresses have accounts.
+func (s *userService) ForgotPassword(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.ForgotPasswordRequest,
+) error {
+	ctx, span := tracing.Start(ctx, "UserService.ForgotPassword")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByEmail(ctx, dB, form.Email)
+	if err != nil || user == nil || !user.IsActive {
+		return nil
+	}
+
+	// a failure is only logged: an error here would reveal that the address has an account
+	if err := s.sendPasswordReset(ctx, dB, user); err != nil {
+		logger.FromContext(ctx).Errorf("send password reset for user [%v] failed: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+func (s *userService) ResetPassword(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.ResetPasswordRequest,
+) error {
+	ctx, span := tracing.Start(ctx, "UserService.ResetPassword")
+	defer span.End()
+
+	passwordHash, err := utils.HashPassword(form.NewPassword)
+	if err != nil {
+		return apperr.NewInternal("failed to process password")
+	}
+
+	return dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		user, err := s.redeemUserToken(ctx, ops, custom_types.TokenPurposePasswordReset, form.Token)
+		if err != nil {
+			return err
+		}
+
+		user.PasswordHash = passwordHash
+		user.FailedLoginCount = 0
+		user.LockedUntil = nil
+		user.RevokeTokens()
+
+		return s.store.UserDomain.CreateUser(ctx, ops, user)
+	})
+}
+
+func (s *userService) VerifyEmail(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.VerifyEmailRequest,
+) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.VerifyEmail")
+	defer span.End()
+
+	var user *models.User
+	err := dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		var err error
+		user, err = s.redeemUserToken(ctx, ops, custom_types.TokenPurposeEmailVerification, form.Token)
+		if err != nil {
+			return err
+		}
+
+		user.EmailVerified = true
+
+		return s.store.UserDomain.CreateUser(ctx, ops, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) ResendEmailVerification(
+	ctx context.Context,
+	dB db.DB,
+	username string,
+) error {
+	ctx, span := tracing.Start(ctx, "UserService.ResendEmailVerification")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByUsername(ctx, dB, username)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified {
+		return apperr.NewBadRequest("email is already verified")
+	}
+
+	return s.sendEmailVerification(ctx, dB, user)
+}
+
+func (s *userService) GetUsers(
+	ctx context.Context,
+	dB db.DB,
+	filter *models.Filter,
+) (*models.UserList, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUsers")
+	defer span.End()
+
+	users, err := s.store.UserDomain.GetUsers(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.UserDomain.GetUsersCount(ctx, dB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserList{
+		Users:      users,
+		Pagination: models.NewPagination(count, filter.Page, filter.Per),
+	}, nil
+}
+
+// UpdateUser changes email and role. A new email has to be verified again.
+func (s *userService) UpdateUser(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+	form *dtos.UpdateUserRequest,
+) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUser")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByID(ctx, dB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	emailChanged := false
+	if form.Email != nil && *form.Email != user.Email {
+		existing, _ := s.store.UserDomain.GetUserByEmail(ctx, dB, *form.Email)
+		if existing != nil {
+			return nil, apperr.NewConflict("email", *form.Email)
+		}
+
+		user.Email = *form.Email
+		user.EmailVerified = false
+		emailChanged = true
+	}
+
+	if form.Role != nil {
+		role := custom_types.UserRole(*form.Role)
+		if !role.IsValid() {
+			return nil, apperr.NewBadRequest(fmt.Sprintf("invalid role [%v]", *form.Role))
+		}
+		user.Role = role
+	}
+
+	if err := s.store.UserDomain.CreateUser(ctx, dB, user); err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		if err := s.sendEmailVerification(ctx, dB, user); err != nil {
+			logger.FromContext(ctx).Errorf("send email verification for user [%v] failed: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+func (s *userService) SetUserActive(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+	active bool,
+) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetUserActive")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByID(ctx, dB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user.IsActive = active
+	if err := s.store.UserDomain.CreateUser(ctx, dB, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ForcePasswordReset replaces the password with an unusable one, revokes the user's tokens and
+// mails a reset token, so the user can only log in again after choosing a new password.
+func (s *userService) ForcePasswordReset(
+	ctx context.Context,
+	dB db.DB,
+	id int64,
+) error {
+	ctx, span := tracing.Start(ctx, "UserService.ForcePasswordReset")
+	defer span.End()
+
+	user, err := s.store.UserDomain.GetUserByID(ctx, dB, id)
+	if err != nil {
+		return err
+	}
+
+	unusable, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return apperr.NewInternal("failed to generate token")
+	}
+
+	passwordHash, err := utils.HashPassword(unusable)
+	if err != nil {
+		return apperr.NewInternal("failed to process password")
+	}
+
+	user.PasswordHash = passwordHash
+	user.RevokeTokens()
+	if err := s.store.UserDomain.CreateUser(ctx, dB, user); err != nil {
+		return err
+	}
+
+	return s.sendPasswordReset(ctx, dB, user)
+}
+
+func (s *userService) sendPasswordReset(
+	ctx context.Context,
+	dB db.DB,
+	user *models.User,
+) error {
+
+	token, err := s.issueUserToken(ctx, dB, user.ID, custom_types.TokenPurposePasswordReset, PasswordResetTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Ginja AI password",
+		Body: fmt.Sprintf(
+			"Hi %v,\n\nUse this token to reset your password within %v:\n\n%v\n\nOr open %v/reset-password?token=%v\n\nIf you did not ask for a reset you can ignore this email.",
+			user.Username,
+			PasswordResetTokenDuration,
+			token,
+			configs.Config.AppBaseURL,
+			token,
+		),
+	})
+}
+
+func (s *userService) sendEmailVerification(
+	ctx context.Context,
+	dB db.DB,
+	user *models.User,
+) error {
+
+	token, err := s.issueUserToken(ctx, dB, user.ID, custom_types.TokenPurposeEmailVerification, EmailVerificationTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Ginja AI email address",
+		Body: fmt.Sprintf(
+			"Hi %v,\n\nUse this token to verify your email address within %v:\n\n%v\n\nOr open %v/verify-email?token=%v",
+			user.Username,
+			EmailVerificationTokenDuration,
+			token,
+			configs.Config.AppBaseURL,
+			token,
+		),
+	})
+}
+
+// issueUserToken replaces any outstanding token of the same purpose and returns the plaintext token.
+func (s *userService) issueUserToken(
+	ctx context.Context,
+	dB db.DB,
+	userID int64,
+	purpose custom_types.TokenPurpose,
+	duration time.Duration,
+) (string, error) {
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", apperr.NewInternal("failed to generate token")
+	}
+
+	err = dB.InTransaction(ctx, func(ctx context.Context, ops db.SQLOperations) error {
+
+		if err := s.store.UserTokenDomain.DeleteUserTokensByUserID(ctx, ops, userID, purpose); err != nil {
+			return err
+		}
+
+		userToken := &models.UserToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: time.Now().Add(duration),
+		}
+
+		return s.store.UserTokenDomain.CreateUserToken(ctx, ops, userToken)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// redeemUserToken marks a usable token as used and returns its user.
+func (s *userService) redeemUserToken(
+	ctx context.Context,
+	ops db.SQLOperations,
+	purpose custom_types.TokenPurpose,
+	token string,
+) (*models.User, error) {
+
+	userToken, err := s.store.UserTokenDomain.GetUserTokenByHash(ctx, ops, purpose, utils.HashToken(token))
+	if err != nil || !userToken.IsUsable() {
+		return nil, apperr.NewBadRequest("invalid or expired token")
+	}
+
+	used, err := s.store.UserTokenDomain.UseUserToken(ctx, ops, userToken.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, apperr.NewBadRequest("invalid or expired token")
+	}
+
+	return s.store.UserDomain.GetUserByID(ctx, ops, userToken.UserID)
+}
+
+func recordLoginAttempt(
+	ctx context.Context,
+	store *domain.Store,
+	dB db.DB,
+	username,
+	ipAddress string,
+	succeeded bool,
+) error {
+
+	attempt := &models.LoginAttempt{
+		Username:  username,
+		IPAddress: ipAddress,
+		Succeeded: succeeded,
+	}
+
+	return store.LoginAttemptDomain.CreateLoginAttempt(ctx, dB, attempt)
+}
+
+// checkLoginThrottle refuses a login step from an address with MaxFailedLoginsPerIP failures
+// within LoginIPWindow.
+func checkLoginThrottle(
+	ctx context.Context,
+	store *domain.Store,
+	dB db.DB,
+	ipAddress string,
+) error {
+
+	failedFromIP, err := store.LoginAttemptDomain.GetFailedLoginAttemptsByIPCount(ctx, dB, ipAddress, time.Now().Add(-LoginIPWindow))
+	if err != nil {
+		return err
+	}
+	if failedFromIP >= MaxFailedLoginsPerIP {
+		return apperr.NewTooManyRequests("too many failed login attempts from this address, try again later")
+	}
+
+	return nil
+}
+
+// registerFailedLogin records a failed password or second factor check, locks the
+// account once MaxFailedLogins is reached and returns the error to hand back to the caller.
+func registerFailedLogin(
+	ctx context.Context,
+	store *domain.Store,
+	dB db.DB,
+	user *models.User,
+	ipAddress string,
+	reason string,
+) error {
+
+	if err := recordLoginAttempt(ctx, store, dB, user.Username, ipAddress, false); err != nil {
+		return err
+	}
+
+	err := store.UserDomain.IncrementFailedLogins(ctx, dB, user, MaxFailedLogins, LoginLockoutBase, LoginLockoutMax)
+	if err != nil {
+		return err
+	}
+
+	if user.IsLocked() {
+		return apperr.NewAccountLocked(*user.LockedUntil)
+	}
+
+	return apperr.NewAuthorization(reason)
+}
+
+func registerSuccessfulLogin(
+	ctx context.Context,
+	store *domain.Store,
+	dB db.DB,
+	user *models.User,
+	ipAddress string,
+) error {
+
+	if err := recordLoginAttempt(ctx, store, dB, user.Username, ipAddress, true); err != nil {
+		return err
+	}
+
+	if user.FailedLoginCount == 0 && user.LockedUntil == nil {
+		return nil
+	}
+
+	return store.UserDomain.ResetFailedLogins(ctx, dB, user)
+}
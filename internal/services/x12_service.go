@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/models"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/Doris-Mwito5/ginja-ai/internal/tracing"
+	"github.com/Doris-Mwito5/ginja-ai/internal/x12"
+)
+
+// X12PayerName is how we name ourselves in 835 remittance advice.
+const X12PayerName = "GINJA AI"
+
+// CARC adjustment reason codes used in the 835.
+const (
+	carcFeeSchedule     = "45"  // charge exceeds the fee schedule (tariff)
+	carcNonCovered      = "96"  // non-covered charge
+	carcBenefitMaximum  = "119" // benefit maximum reached
+	carcSubmissionError = "16"  // claim lacks information or has billing errors
+)
+
+type X12Service interface {
+	ProcessClaims(ctx context.Context, dB db.DB, data []byte, dryRun bool) (*dtos.X12Result, error)
+}
+
+type x12Service struct {
+	store        *domain.Store
+	claimService ClaimService
+}
+
+func NewX12Service(
+	store *domain.Store,
+	claimService ClaimService,
+) X12Service {
+	return &x12Service{
+		store:        store,
+		claimService: claimService,
+	}
+}
+
+// ProcessClaims adjudicates every claim in an 837P/837I interchange and answers with an 835 in
+// the same separators. Claims that cannot be mapped are denied in the 835 rather than failing the
+// file. The subscriber ID is the membership number and the billing provider ID the licence number.
+// Each payee gets one remittance per currency the claims were paid in. A claim the same sender
+// already filed under its CLM01 patient control number is not adjudicated again; the 835 reports
+// the saved decision, so a file can safely be sent again after a failure part way through. A
+// replacement claim (CLM05-3 = 7) of a rejected claim is adjudicated again.
+func (s *x12Service) ProcessClaims(
+	ctx context.Context,
+	dB db.DB,
+	data []byte,
+	dryRun bool,
+) (*dtos.X12Result, error) {
+	ctx, span := tracing.Start(ctx, "X12Service.ProcessClaims")
+	defer span.End()
+
+	interchange, err := x12.Parse(data)
+	if err != nil {
+		return nil, apperr.NewBadRequest(err.Error())
+	}
+
+	claims, err := x12.ReadClaims(interchange)
+	if err != nil {
+		return nil, apperr.NewBadRequest(err.Error())
+	}
+	if len(claims) == 0 {
+		return nil, apperr.NewBadRequest("interchange has no claims")
+	}
+
+	result := &dtos.X12Result{
+		DryRun: dryRun,
+		Claims: make([]*dtos.X12ClaimResult, 0, len(claims)),
+	}
+
+	remittances := make([]*x12.Remittance, 0)
+	remittanceByPayee := make(map[string]*x12.Remittance)
+
+	for _, claim := range claims {
+		claimResult, remittanceClaim, err := s.adjudicate(ctx, dB, interchange.SenderID, claim, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		result.Claims = append(result.Claims, claimResult)
+
+		payee := claim.BillingProviderID + "|" + claim.BillingProviderName + "|" + remittanceClaim.Charge.Currency
+		remittance, ok := remittanceByPayee[payee]
+		if !ok {
+			remittance = &x12.Remittance{
+				PayerName: X12PayerName,
+				PayerID:   interchange.ReceiverID,
+				PayeeName: claim.BillingProviderName,
+				PayeeID:   claim.BillingProviderID,
+				Currency:  remittanceClaim.Charge.Currency,
+			}
+			remittanceByPayee[payee] = remittance
+			remittances = append(remittances, remittance)
+		}
+		remittance.Claims = append(remittance.Claims, remittanceClaim)
+	}
+
+	now := time.Now()
+	header := &x12.Header{
+		SenderID:      interchange.ReceiverID,
+		ReceiverID:    interchange.SenderID,
+		ControlNumber: int(now.Unix() % 1000000000),
+		Date:          now,
+		Production:    interchange.Production,
+	}
+	result.Remittance = string(x12.Write835(header, interchange.Separators, remittances))
+
+	return result, nil
+}
+
+// adjudicate runs one claim through the pipeline. Validation problems deny the claim; any other
+// error aborts the file, keeping the claims saved before it.
+func (s *x12Service) adjudicate(
+	ctx context.Context,
+	dB db.DB,
+	senderID string,
+	claim *x12.Claim837,
+	dryRun bool,
+) (*dtos.X12ClaimResult, *x12.RemittanceClaim, error) {
+
+	claimResult := &dtos.X12ClaimResult{
+		PatientControlNumber: claim.PatientControlNumber,
+		Type:                 claim.Type,
+	}
+	currency := claim.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	remittanceClaim := &x12.RemittanceClaim{
+		PatientControlNumber: claim.PatientControlNumber,
+		SubscriberID:         claim.SubscriberID,
+		ServiceDate:          claim.ServiceDate,
+		Charge:               money.New(claim.TotalCharge.Amount, currency),
+	}
+
+	deny := func(message string) (*dtos.X12ClaimResult, *x12.RemittanceClaim, error) {
+		claimResult.Status = "REJECTED"
+		claimResult.Error = message
+		remittanceClaim.Status = x12.ClaimStatusDenied
+		remittanceClaim.AdjustmentReason = carcSubmissionError
+		return claimResult, remittanceClaim, nil
+	}
+
+	if len(claim.ServiceLines) != 1 {
+		return deny(fmt.Sprintf("claim has %d service lines; only single-procedure claims are supported", len(claim.ServiceLines)))
+	}
+	line := claim.ServiceLines[0]
+	remittanceClaim.ProcedureCode = line.ProcedureCode
+	if line.ServiceDate != "" {
+		remittanceClaim.ServiceDate = line.ServiceDate
+	}
+
+	if len(claim.DiagnosisCodes) == 0 {
+		return deny("claim has no principal diagnosis")
+	}
+
+	providerID, err := s.providerID(ctx, dB, claim.BillingProviderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	form := &dtos.ClaimSubmissionForm{
+		MembershipNumber: claim.SubscriberID,
+		ProviderID:       providerID,
+		ProcedureCode:    line.ProcedureCode,
+		DiagnosisCode:    claim.DiagnosisCodes[0],
+		RequestedAmount:  claim.TotalCharge,
+		Currency:         claim.Currency,
+		ServiceDate:      remittanceClaim.ServiceDate,
+		AdmissionDate:    claim.AdmissionDate,
+		DischargeDate:    claim.DischargeDate,
+		DryRun:           dryRun,
+
+		X12SenderID:          senderID,
+		PatientControlNumber: strings.TrimSpace(claim.PatientControlNumber),
+	}
+
+	previous, err := s.previousClaim(ctx, dB, form)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// an original sent again is answered from the saved decision. A replacement corrects a
+	// rejected claim and is adjudicated afresh; a claim that was accepted cannot be replaced or
+	// voided here, since reversing it would need its benefit and payment wound back.
+	var replaced *models.Claim
+	switch claim.FrequencyCode {
+	case x12.FrequencyVoid:
+		if previous == nil {
+			return deny("no claim was filed under this patient control number to void")
+		}
+		if previous.Status.IsRejected() {
+			return deny(fmt.Sprintf("claim [%v] was rejected, so there is nothing to void", previous.ID))
+		}
+		return deny(fmt.Sprintf("claim [%v] was already adjudicated and cannot be voided", previous.ID))
+	case x12.FrequencyReplacement:
+		if previous != nil && !previous.Status.IsRejected() {
+			return deny(fmt.Sprintf("claim [%v] was already adjudicated and cannot be replaced", previous.ID))
+		}
+		replaced, previous = previous, nil
+	}
+
+	if previous == nil {
+		submission, err := s.claimService.SubmitClaim(ctx, dB, form)
+		if err == nil {
+			charge, err := form.RequestedAmount.Convert(submission.ExchangeRate, submission.BenefitCurrency)
+			if err != nil {
+				return nil, nil, err
+			}
+			return remit(claimResult, remittanceClaim, submission, charge)
+		}
+		if appErr, ok := err.(*apperr.Error); ok && appErr.Type == apperr.BadRequest {
+			return deny(appErr.Message)
+		}
+
+		// the same file processed at the same time may have saved the claim first
+		previous, _ = s.previousClaim(ctx, dB, form)
+		if previous == nil || (replaced != nil && previous.ID == replaced.ID) {
+			return nil, nil, err
+		}
+	}
+
+	claimResult.Duplicate = true
+	submission := &dtos.ClaimSubmissionResponse{
+		ClaimID:         previous.ID,
+		MemberID:        previous.MemberID,
+		Status:          string(previous.Status),
+		FraudFlag:       previous.FraudFlag,
+		ApprovedAmount:  previous.ApprovedAmount,
+		BenefitCurrency: previous.BenefitCurrency,
+		ExchangeRate:    previous.ExchangeRate,
+		RejectionReason: previous.RejectionReason,
+	}
+	return remit(claimResult, remittanceClaim, submission, previous.ConvertedAmount)
+}
+
+// previousClaim finds the claim the sender already filed under the form's patient control
+// number, or nil when there is none. Once a rejected claim has been replaced, the replacement is
+// returned.
+func (s *x12Service) previousClaim(
+	ctx context.Context,
+	dB db.DB,
+	form *dtos.ClaimSubmissionForm,
+) (*models.Claim, error) {
+
+	if form.PatientControlNumber == "" {
+		return nil, nil
+	}
+
+	claim, err := s.store.ClaimDomain.GetClaimByPatientControlNumber(ctx, dB, form.X12SenderID, form.PatientControlNumber)
+	if err == nil {
+		return claim, nil
+	}
+	if apperr.IsNoRowsErr(err) {
+		return nil, nil
+	}
+	return nil, err
+}
+
+// remit fills in the claim's outcome and its 835 entry from the pipeline's decision. The charge is
+// reported in the currency the claim is paid in.
+func remit(
+	claimResult *dtos.X12ClaimResult,
+	remittanceClaim *x12.RemittanceClaim,
+	submission *dtos.ClaimSubmissionResponse,
+	charge money.Money,
+) (*dtos.X12ClaimResult, *x12.RemittanceClaim, error) {
+
+	claimResult.ClaimID = submission.ClaimID
+	claimResult.Status = submission.Status
+	claimResult.ApprovedAmount = submission.ApprovedAmount
+	claimResult.FraudFlag = submission.FraudFlag
+	claimResult.RejectionReason = submission.RejectionReason
+
+	if submission.ClaimID != 0 {
+		remittanceClaim.PayerClaimID = strconv.FormatInt(submission.ClaimID, 10)
+	}
+	remittanceClaim.Charge = charge
+	remittanceClaim.Paid = submission.ApprovedAmount
+
+	switch submission.Status {
+	case "REJECTED":
+		remittanceClaim.Status = x12.ClaimStatusDenied
+		remittanceClaim.AdjustmentReason = carcNonCovered
+	default:
+		remittanceClaim.Status = x12.ClaimStatusProcessed
+		remittanceClaim.AdjustmentReason = carcFeeSchedule
+		if strings.Contains(strings.ToLower(submission.RejectionReason), "benefit") {
+			remittanceClaim.AdjustmentReason = carcBenefitMaximum
+		}
+	}
+
+	return claimResult, remittanceClaim, nil
+}
+
+// providerID maps the billing provider ID to a provider by licence number. An unknown licence
+// gives 0, which the pipeline rejects as "Provider not found".
+func (s *x12Service) providerID(
+	ctx context.Context,
+	dB db.DB,
+	licenceNumber string,
+) (int64, error) {
+
+	licenceNumber = strings.TrimSpace(licenceNumber)
+	if licenceNumber == "" {
+		return 0, nil
+	}
+
+	provider, err := s.store.ProviderDomain.GetProviderByLicenceNumber(ctx, dB, licenceNumber)
+	if err == nil {
+		return provider.ID, nil
+	}
+	if apperr.IsNoRowsErr(err) {
+		return 0, nil
+	}
+	return 0, err
+}
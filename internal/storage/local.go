@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localStorage keeps objects as files under a root directory.
+type localStorage struct {
+	root string
+}
+
+func NewLocalStorage(root string) Storage {
+	return &localStorage{
+		root: root,
+	}
+}
+
+func (s *localStorage) Put(
+	ctx context.Context,
+	key string,
+	body io.Reader,
+	size int64,
+	contentType string,
+) error {
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create storage directory failed: %w", err)
+	}
+
+	// write to a temporary file first so a failed upload never leaves a partial object
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("create storage file failed: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		return fmt.Errorf("write storage file failed: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close storage file failed: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("store file [%v] failed: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *localStorage) Get(
+	ctx context.Context,
+	key string,
+) (io.ReadCloser, error) {
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open storage file [%v] failed: %w", key, err)
+	}
+
+	return file, nil
+}
+
+func (s *localStorage) Delete(
+	ctx context.Context,
+	key string,
+) error {
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete storage file [%v] failed: %w", key, err)
+	}
+
+	return nil
+}
+
+// path maps a key to a file under the root, refusing keys that would escape it.
+func (s *localStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if key == "" || strings.Contains(key, "..") || cleaned == "/" {
+		return "", fmt.Errorf("invalid storage key [%v]", key)
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
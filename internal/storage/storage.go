@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/configs"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// ErrNotFound is returned by Get when no object is stored under the key.
+var ErrNotFound = errors.New("storage object not found")
+
+// Storage keeps uploaded files such as claim attachments. Keys are slash-separated paths.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStorage builds the storage selected by STORAGE_DRIVER, defaulting to the local filesystem.
+func NewStorage() Storage {
+	switch configs.Config.StorageDriver {
+	case DriverS3:
+		return NewS3Storage(
+			configs.Config.S3Endpoint,
+			configs.Config.S3Region,
+			configs.Config.S3Bucket,
+			configs.Config.S3AccessKeyID,
+			configs.Config.S3SecretAccessKey,
+		)
+	default:
+		return NewLocalStorage(configs.Config.StorageLocalDir)
+	}
+}
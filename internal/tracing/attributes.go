@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	ClaimIDKey   = attribute.Key("claim.id")
+	MemberIDKey  = attribute.Key("member.id")
+	RequestIDKey = attribute.Key("request.id")
+)
+
+type attributesKey struct{}
+
+func ClaimID(id int64) attribute.KeyValue {
+	return ClaimIDKey.Int64(id)
+}
+
+func MemberID(id int64) attribute.KeyValue {
+	return MemberIDKey.Int64(id)
+}
+
+func RequestID(requestID string) attribute.KeyValue {
+	return RequestIDKey.String(requestID)
+}
+
+// MemberIDText is MemberID for a member ID taken from a path or query parameter. An empty or
+// non-numeric value is recorded as given.
+func MemberIDText(memberID string) attribute.KeyValue {
+	id, err := strconv.ParseInt(memberID, 10, 64)
+	if err != nil {
+		return MemberIDKey.String(memberID)
+	}
+	return MemberID(id)
+}
+
+// withAttributes returns a copy of ctx carrying attrs on top of the attributes it already
+// carries; a later value for the same key replaces the earlier one.
+func withAttributes(ctx context.Context, attrs []attribute.KeyValue) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+
+	inherited := attributesFromContext(ctx)
+	merged := make([]attribute.KeyValue, 0, len(inherited)+len(attrs))
+	for _, attr := range inherited {
+		if !hasKey(attrs, attr.Key) {
+			merged = append(merged, attr)
+		}
+	}
+	merged = append(merged, attrs...)
+
+	return context.WithValue(ctx, attributesKey{}, merged)
+}
+
+func attributesFromContext(ctx context.Context) []attribute.KeyValue {
+	attrs, _ := ctx.Value(attributesKey{}).([]attribute.KeyValue)
+	return attrs
+}
+
+func hasKey(attrs []attribute.KeyValue, key attribute.Key) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
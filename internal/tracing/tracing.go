@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	instrumentationName = "github.com/Doris-Mwito5/ginja-ai"
+)
+
+// Options configure the exporter spans are sent to.
+type Options struct {
+	ServiceName string
+	// Exporter is ExporterOTLP, ExporterStdout or ExporterNone (also when empty).
+	Exporter string
+	// OTLPEndpoint is the host:port of an OTLP/HTTP collector.
+	OTLPEndpoint string
+	OTLPInsecure bool
+	// SampleRatio is the fraction of new traces recorded; traces started upstream follow the
+	// caller's sampling decision.
+	SampleRatio float64
+}
+
+// Init installs the global tracer provider and the W3C trace context and baggage propagators.
+// With no exporter spans are still created, so trace IDs propagate, but nothing is recorded.
+// The returned function flushes and stops the exporter.
+func Init(ctx context.Context, options Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch options.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporterOptions := []otlptracehttp.Option{otlptracehttp.WithEndpoint(options.OTLPEndpoint)}
+		if options.OTLPInsecure {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, exporterOptions...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter [%v]", options.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(options.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the one in ctx. The attributes are set on the span and
+// carried in the returned context, so every span started below it, down to the SQL statements,
+// records them too.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx = withAttributes(ctx, attrs)
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attributesFromContext(ctx)...))
+}
+
+// SetAttributes sets attributes learned part way through a span, such as the ID of a claim once
+// it is saved, on the current span and on the spans started below it from the returned context.
+func SetAttributes(ctx context.Context, attrs ...attribute.KeyValue) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	return withAttributes(ctx, attrs)
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSVRow is a single CSV record keyed by lower-cased header name.
+type CSVRow struct {
+	Line   int
+	Values map[string]string
+}
+
+func (r *CSVRow) Get(column string) string {
+	return strings.TrimSpace(r.Values[column])
+}
+
+// ReadCSV reads a CSV file with a header row. Missing required columns are reported up front.
+func ReadCSV(reader io.Reader, requiredColumns ...string) ([]*CSVRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv file is empty")
+		}
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	present := make(map[string]bool, len(header))
+	for i, name := range header {
+		columns[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		present[columns[i]] = true
+	}
+
+	for _, column := range requiredColumns {
+		if !present[column] {
+			return nil, fmt.Errorf("csv is missing required column [%v]", column)
+		}
+	}
+
+	rows := make([]*CSVRow, 0)
+	line := 1
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("read csv line %d: %w", line, err)
+		}
+
+		values := make(map[string]string, len(columns))
+		for i, value := range record {
+			if i < len(columns) {
+				values[columns[i]] = value
+			}
+		}
+
+		rows = append(rows, &CSVRow{
+			Line:   line,
+			Values: values,
+		})
+	}
+
+	return rows, nil
+}
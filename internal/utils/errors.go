@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
 	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
@@ -13,41 +14,37 @@ func HandleError(
 	err interface{},
 ) {
 
-	if appErr, ok := err.(*apperr.Error); ok {
-		logErrorMessage(appErr)
-		c.JSON(appErr.Status(), appErr.JsonResponse())
-	} else {
-		unknownAppErr := apperr.New(errors.New("unexpected error"), apperr.UnexpextedError)
-		logger.Errorf("request failed with unknown error: [%+v]", err)
-		c.JSON(unknownAppErr.Status(), unknownAppErr.JsonResponse())
+	appErr, ok := err.(*apperr.Error)
+	if !ok {
+		logger.FromContext(c.Request.Context()).Errorf("request failed with unknown error: [%+v]", err)
+		appErr = apperr.New(errors.New("unexpected error"), apperr.UnexpextedError)
 	}
+
+	LogError(c, appErr)
+	c.JSON(appErr.Status(), appErr.JsonResponse())
 }
 
-func logErrorMessage(
+// LogError sets the request ID and client IP on the error and logs the messages recorded with
+// LogErrorMessage on the request's logger. Handlers that write their own error body call it
+// before responding.
+func LogError(
+	c *gin.Context,
 	appErr *apperr.Error,
 ) {
+	appErr.RequestID = logger.RequestIDFromContext(c.Request.Context())
+	appErr.IPAddress = c.ClientIP()
+
+	if len(appErr.LogMessages) == 0 {
+		return
+	}
+
+	requestLogger := logger.FromContext(c.Request.Context())
+	message := "request failed with status [%v] from IP [%v]: %v"
+	logMessages := strings.Join(appErr.LogMessages, "; ")
+
 	if appErr.Payload != nil {
-		for _, logMessage := range appErr.LogMessages {
-			logger.ErrorWithPayload(
-				"failed to make request-id: %s with err: [%v] from ip-address: %v and mac-address: %v;",
-				appErr.Payload,
-				appErr.RequestID,
-				logMessage,
-				appErr.IPAddress,
-				appErr.MACAddress,
-			)
-		}
-	} else {
-
-		for _, logMessage := range appErr.LogMessages {
-
-			logger.Errorf(
-				"failed to make request: [%s] with err: [%v] from IP [x] [%v] [x] and MAC [x] [%v] [x]",
-				appErr.RequestID,
-				logMessage,
-				appErr.IPAddress,
-				appErr.MACAddress,
-			)
-		}
+		requestLogger.ErrorWithPayload(message, appErr.Payload, appErr.Status(), appErr.IPAddress, logMessages)
+		return
 	}
+	requestLogger.Errorf(message, appErr.Status(), appErr.IPAddress, logMessages)
 }
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"math"
+	"math/big"
+	"sort"
+)
+
+// The statistics work on whole numbers, such as amounts in minor units, so a result is rounded
+// exactly once, half away from zero.
+
+// Median returns the middle value of the samples, or the mean of the two middle values.
+func Median(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := sortedCopy(values)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+
+	return mean(sorted[middle-1 : middle+1])
+}
+
+// TrimmedMean drops the given fraction of samples from each end before averaging.
+func TrimmedMean(values []int64, trim float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := sortedCopy(values)
+	cut := int(math.Floor(float64(len(sorted)) * trim))
+	if cut*2 >= len(sorted) {
+		return Median(sorted)
+	}
+
+	return mean(sorted[cut : len(sorted)-cut])
+}
+
+// mean sums in arbitrary precision, so no number of samples can overflow.
+func mean(values []int64) int64 {
+	sum := new(big.Int)
+	for _, value := range values {
+		sum.Add(sum, big.NewInt(value))
+	}
+
+	count := big.NewInt(int64(len(values)))
+	quotient, remainder := new(big.Int).QuoRem(sum, count, new(big.Int))
+
+	// round half away from zero: QuoRem truncates, and the remainder has the sign of the sum
+	remainder.Abs(remainder).Lsh(remainder, 1)
+	if remainder.Cmp(count) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(sum.Sign())))
+	}
+
+	return quotient.Int64()
+}
+
+func sortedCopy(values []int64) []int64 {
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
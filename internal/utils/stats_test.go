@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+)
+
+func TestMedian(t *testing.T) {
+	cases := []struct {
+		values []int64
+		want   int64
+	}{
+		{nil, 0},
+		{[]int64{150000}, 150000},
+		{[]int64{300, 100, 200}, 200},
+		{[]int64{100, 200}, 150},
+		{[]int64{100, 101}, 101},       // 100.5 rounds up
+		{[]int64{-100, -101}, -101},    // -100.5 rounds away from zero
+		{[]int64{1, 2, 2, 1000}, 2},    // the outlier does not move it
+		{[]int64{5, 1, 4, 2, 3, 6}, 4}, // 3.5
+	}
+	for _, c := range cases {
+		if got := Median(c.values); got != c.want {
+			t.Errorf("Median(%v) = %d, want %d", c.values, got, c.want)
+		}
+	}
+}
+
+func TestMedianLeavesItsInputAlone(t *testing.T) {
+	values := []int64{3, 1, 2}
+	Median(values)
+	TrimmedMean(values, 0.1)
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Errorf("values were reordered to %v", values)
+	}
+}
+
+func TestTrimmedMean(t *testing.T) {
+	cases := []struct {
+		values []int64
+		trim   float64
+		want   int64
+	}{
+		{nil, 0.1, 0},
+		{[]int64{100, 200, 400}, 0, 233}, // 233.33
+		{[]int64{100, 200, 350}, 0, 217}, // 216.67
+		{[]int64{1, 2}, 0, 2},            // 1.5 rounds up
+		{[]int64{-1, -2}, 0, -2},         // -1.5 rounds away from zero
+		{[]int64{1, 10, 10, 10, 10, 10, 10, 10, 10, 100000}, 0.1, 10},     // one dropped from each end
+		{[]int64{1, 10, 10, 10, 10, 10, 10, 10, 10, 100000}, 0.09, 10008}, // nothing dropped below 10%
+		{[]int64{1, 2, 9}, 0.5, 2},                                        // trimming everything falls back to the median
+	}
+	for _, c := range cases {
+		if got := TrimmedMean(c.values, c.trim); got != c.want {
+			t.Errorf("TrimmedMean(%v, %v) = %d, want %d", c.values, c.trim, got, c.want)
+		}
+	}
+}
+
+// Sums that overflow int64 must still average correctly.
+func TestTrimmedMeanDoesNotOverflow(t *testing.T) {
+	values := []int64{math.MaxInt64, math.MaxInt64, math.MaxInt64 - 1}
+	if got, want := TrimmedMean(values, 0), int64(math.MaxInt64); got != want {
+		t.Errorf("TrimmedMean = %d, want %d", got, want)
+	}
+}
+
+// Both statistics of any samples lie between the smallest and largest sample.
+func TestStatisticsStayWithinTheSamples(t *testing.T) {
+	property := func(values []int64, trimPercent uint8) bool {
+		if len(values) == 0 {
+			return true
+		}
+		low, high := values[0], values[0]
+		for _, value := range values {
+			low, high = min(low, value), max(high, value)
+		}
+
+		trim := float64(trimPercent%50) / 100
+		median, trimmed := Median(values), TrimmedMean(values, trim)
+		return low <= median && median <= high && low <= trimmed && trimmed <= high
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
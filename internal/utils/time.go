@@ -42,3 +42,12 @@ func FormatTime(timeToFormat time.Time) string {
 func FormatDateTime(timeToFormat time.Time) string {
 	return timeToFormat.In(time.UTC).Format(safaricomTimeLayout)
 }
+
+func ParseDate(dateString string) (time.Time, error) {
+	parsedDate, err := time.ParseInLocation(dateLayout, dateString, time.UTC)
+	if err != nil {
+		return parsedDate, errors.New("invalid date format, expected YYYY-MM-DD")
+	}
+
+	return parsedDate, nil
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the RFC 6238 SHA-1 test key "12345678901234567890" in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC 6238 appendix B SHA-1 vectors, cut to our six digits.
+func TestTOTPCodeRFC6238(t *testing.T) {
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, c := range cases {
+		got, err := TOTPCode(rfc6238Secret, c.unix/totpPeriod)
+		if err != nil {
+			t.Fatalf("TOTPCode at %d: %v", c.unix, err)
+		}
+		if got != c.want {
+			t.Errorf("TOTPCode at %d = %s, want %s", c.unix, got, c.want)
+		}
+	}
+}
+
+func TestTOTPCodeInvalidSecret(t *testing.T) {
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("TOTPCode accepted an invalid secret")
+	}
+}
+
+// Codes one step either side of now are accepted and report their own step; two steps away are not.
+func TestValidateTOTPSkewWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+
+	for offset := int64(-2); offset <= 2; offset++ {
+		code, err := TOTPCode(rfc6238Secret, current+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		step, ok := ValidateTOTP(rfc6238Secret, code, now)
+		inWindow := offset >= -totpSkew && offset <= totpSkew
+		if ok != inWindow {
+			t.Errorf("code for step %+d: accepted = %v, want %v", offset, ok, inWindow)
+		}
+		if ok && step != current+offset {
+			t.Errorf("code for step %+d matched step %d, want %d", offset, step, current+offset)
+		}
+	}
+}
+
+func TestValidateTOTPRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	for _, code := range []string{"", "05047", "0504711", "abcdef"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, code, now); ok {
+			t.Errorf("ValidateTOTP accepted %q", code)
+		}
+	}
+
+	// surrounding spaces are forgiven
+	if _, ok := ValidateTOTP(rfc6238Secret, " 050471 ", now); !ok {
+		t.Error("ValidateTOTP rejected a valid code with spaces around it")
+	}
+}
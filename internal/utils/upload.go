@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is how far a multipart body may exceed the largest file it carries, to make
+// room for the part headers, boundaries and other form fields.
+const multipartOverhead = 1 << 20
+
+// OpenFormFile opens the named multipart file field, rejecting files larger than maxSize bytes.
+// The request body is capped before the form is parsed, so an oversized upload is refused without
+// being read in full or spooled to disk.
+func OpenFormFile(
+	c *gin.Context,
+	field string,
+	maxSize int64,
+) (multipart.File, error) {
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile(field)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, apperr.NewPayloadTooLarge(maxSize, c.Request.ContentLength)
+		}
+		return nil, apperr.NewBadRequest("multipart form field [" + field + "] is required")
+	}
+
+	if fileHeader.Size > maxSize {
+		return nil, apperr.NewPayloadTooLarge(maxSize, fileHeader.Size)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, apperr.NewBadRequest("failed to read uploaded file")
+	}
+
+	return file, nil
+}
+
+// ImportStatusCode maps an import result to its HTTP status: 200 for a dry run, 400 when
+// nothing was saved because of row errors and 201 otherwise.
+func ImportStatusCode(result *dtos.ImportResult) int {
+	switch {
+	case result.DryRun:
+		return http.StatusOK
+	case len(result.Errors) > 0 && result.Imported == 0:
+		return http.StatusBadRequest
+	default:
+		return http.StatusCreated
+	}
+}
@@ -0,0 +1,263 @@
+package x12
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+const (
+	Type837P = "837P"
+	Type837I = "837I"
+)
+
+// Claim frequency codes (CLM05-3) that say whether a claim is new or corrects one filed before.
+const (
+	FrequencyOriginal    = "1"
+	FrequencyReplacement = "7"
+	FrequencyVoid        = "8"
+)
+
+// Claim837 is one CLM loop of an 837 with the provider and subscriber loops it sits under.
+type Claim837 struct {
+	Type                 string // 837P or 837I
+	PatientControlNumber string // CLM01, echoed back in the 835
+	FrequencyCode        string // CLM05-3: FrequencyOriginal, FrequencyReplacement or FrequencyVoid
+	BillingProviderName  string
+	BillingProviderID    string // NM109 of the billing provider
+	SubscriberID         string // NM109 of the subscriber
+	SubscriberName       string
+	TotalCharge          money.Money
+	Currency             string   // CUR02 of the billing provider loop, empty when not given
+	DiagnosisCodes       []string // principal first
+	ServiceDate          string   // YYYY-MM-DD
+	AdmissionDate        string   // YYYY-MM-DD, institutional only
+	DischargeDate        string   // YYYY-MM-DD, institutional only
+	ServiceLines         []*ServiceLine
+	Position             int // segment position of the CLM
+}
+
+type ServiceLine struct {
+	ProcedureCode string
+	Charge        money.Money
+	ServiceDate   string // YYYY-MM-DD
+}
+
+// ReadClaims collects the claims from every 837 transaction in the interchange.
+func ReadClaims(interchange *Interchange) ([]*Claim837, error) {
+	claims := make([]*Claim837, 0)
+
+	var (
+		transactionType string
+		providerName    string
+		providerID      string
+		currency        string
+		subscriberID    string
+		subscriberName  string
+		claim           *Claim837
+		line            *ServiceLine
+	)
+
+	for _, segment := range interchange.Segments {
+		switch segment.ID() {
+		case "ST":
+			if segment.Element(1) != "837" {
+				return nil, fmt.Errorf("segment %d: transaction set [%v] is not an 837", segment.Position, segment.Element(1))
+			}
+			transactionType = transactionTypeOf(segment.Element(3))
+			claim, line = nil, nil
+
+		case "HL":
+			switch segment.Element(3) {
+			case "20":
+				providerName, providerID, currency = "", "", ""
+				subscriberID, subscriberName = "", ""
+			case "22":
+				subscriberID, subscriberName = "", ""
+			}
+			claim, line = nil, nil
+
+		case "NM1":
+			switch segment.Element(1) {
+			case "85":
+				providerName = segment.Element(3)
+				providerID = segment.Element(9)
+			case "IL":
+				subscriberID = segment.Element(9)
+				subscriberName = strings.TrimSpace(segment.Element(4) + " " + segment.Element(3))
+			}
+
+		case "CUR":
+			if segment.Element(1) == "85" {
+				currency = strings.ToUpper(segment.Element(2))
+			}
+
+		case "CLM":
+			charge, err := parseAmount(segment.Element(2))
+			if err != nil {
+				return nil, fmt.Errorf("segment %d: CLM02: %v", segment.Position, err)
+			}
+			claimType := transactionType
+			if claimType == "" {
+				claimType = transactionTypeFromFacility(interchange.Component(segment.Element(5), 2))
+			}
+
+			frequencyCode := interchange.Component(segment.Element(5), 3)
+			if frequencyCode == "" {
+				frequencyCode = FrequencyOriginal
+			}
+
+			claim = &Claim837{
+				Type:                 claimType,
+				PatientControlNumber: segment.Element(1),
+				FrequencyCode:        frequencyCode,
+				BillingProviderName:  providerName,
+				BillingProviderID:    providerID,
+				SubscriberID:         subscriberID,
+				SubscriberName:       subscriberName,
+				TotalCharge:          charge,
+				Currency:             currency,
+				Position:             segment.Position,
+			}
+			line = nil
+			claims = append(claims, claim)
+
+		case "HI":
+			if claim == nil {
+				continue
+			}
+			for n := 1; n < len(segment.Elements); n++ {
+				qualifier := interchange.Component(segment.Element(n), 1)
+				code := interchange.Component(segment.Element(n), 2)
+				switch qualifier {
+				case "ABK", "BK":
+					claim.DiagnosisCodes = append([]string{code}, claim.DiagnosisCodes...)
+				case "ABF", "BF":
+					claim.DiagnosisCodes = append(claim.DiagnosisCodes, code)
+				}
+			}
+
+		case "LX":
+			if claim == nil {
+				continue
+			}
+			line = &ServiceLine{}
+			claim.ServiceLines = append(claim.ServiceLines, line)
+
+		case "SV1", "SV2":
+			if claim == nil {
+				continue
+			}
+			if line == nil {
+				line = &ServiceLine{}
+				claim.ServiceLines = append(claim.ServiceLines, line)
+			}
+
+			// SV1 (professional) leads with the procedure; SV2 (institutional) leads with the revenue code
+			procedure, charge := segment.Element(1), segment.Element(2)
+			if segment.ID() == "SV2" {
+				procedure, charge = segment.Element(2), segment.Element(3)
+			}
+
+			amount, err := parseAmount(charge)
+			if err != nil {
+				return nil, fmt.Errorf("segment %d: %v charge: %v", segment.Position, segment.ID(), err)
+			}
+			line.ProcedureCode = interchange.Component(procedure, 2)
+			line.Charge = amount
+
+		case "DTP":
+			if claim == nil {
+				continue
+			}
+			start, end, err := parseDatePeriod(segment.Element(2), segment.Element(3))
+			if err != nil {
+				return nil, fmt.Errorf("segment %d: DTP%v: %v", segment.Position, segment.Element(1), err)
+			}
+
+			switch segment.Element(1) {
+			case "472": // service date
+				if line != nil {
+					line.ServiceDate = start
+				} else {
+					claim.ServiceDate = start
+				}
+			case "434": // statement period
+				if claim.ServiceDate == "" {
+					claim.ServiceDate = start
+				}
+				if claim.Type == Type837I {
+					claim.DischargeDate = end
+				}
+			case "435": // admission
+				claim.AdmissionDate = start
+			}
+
+		case "SE":
+			claim, line = nil, nil
+		}
+	}
+
+	// a discharge only means something with an admission
+	for _, claim := range claims {
+		if claim.AdmissionDate == "" {
+			claim.DischargeDate = ""
+		}
+	}
+
+	return claims, nil
+}
+
+// transactionTypeOf reads the implementation guide from ST03 or GS08.
+func transactionTypeOf(version string) string {
+	switch {
+	case strings.Contains(version, "X222"):
+		return Type837P
+	case strings.Contains(version, "X223"):
+		return Type837I
+	default:
+		return ""
+	}
+}
+
+// transactionTypeFromFacility guesses from CLM05-2: institutional claims use facility code "A".
+func transactionTypeFromFacility(qualifier string) string {
+	if qualifier == "A" {
+		return Type837I
+	}
+	return Type837P
+}
+
+func parseAmount(value string) (money.Money, error) {
+	amount, err := money.Parse(value)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("invalid amount [%v]", value)
+	}
+	return amount, nil
+}
+
+// parseDatePeriod reads a D8 (CCYYMMDD), DT (CCYYMMDDHHMM) or RD8 (CCYYMMDD-CCYYMMDD) value as
+// YYYY-MM-DD dates. For a single date, end equals start.
+func parseDatePeriod(format string, value string) (string, string, error) {
+	switch format {
+	case "D8", "DT":
+		if len(value) < 8 {
+			return "", "", fmt.Errorf("invalid date [%v]", value)
+		}
+		date := isoDate(value[:8])
+		return date, date, nil
+	case "RD8":
+		parts := strings.Split(value, "-")
+		if len(parts) != 2 || len(parts[0]) != 8 || len(parts[1]) != 8 {
+			return "", "", fmt.Errorf("invalid date range [%v]", value)
+		}
+		return isoDate(parts[0]), isoDate(parts[1]), nil
+	default:
+		return "", "", fmt.Errorf("unsupported date format [%v]", format)
+	}
+}
+
+func isoDate(value string) string {
+	return value[:4] + "-" + value[4:6] + "-" + value[6:8]
+}
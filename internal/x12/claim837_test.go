@@ -0,0 +1,140 @@
+package x12
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+func readClaims(t *testing.T, data string) []*Claim837 {
+	t.Helper()
+
+	interchange, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	claims, err := ReadClaims(interchange)
+	if err != nil {
+		t.Fatalf("ReadClaims: %v", err)
+	}
+	return claims
+}
+
+func TestReadClaimsProfessional(t *testing.T) {
+	claims := readClaims(t, professional837)
+
+	want := []*Claim837{
+		{
+			Type:                 Type837P,
+			PatientControlNumber: "PCN-1",
+			FrequencyCode:        FrequencyOriginal,
+			BillingProviderName:  "NAIROBI HOSPITAL",
+			BillingProviderID:    "LIC-001",
+			SubscriberID:         "MEM-001",
+			SubscriberName:       "JANE MWANGI",
+			TotalCharge:          money.MustParse("1500.50"),
+			Currency:             "KES",
+			DiagnosisCodes:       []string{"J069", "R509"},
+			ServiceLines: []*ServiceLine{
+				{ProcedureCode: "99213", Charge: money.MustParse("1500.50"), ServiceDate: "2024-01-10"},
+			},
+			Position: 10,
+		},
+		{
+			Type:                 Type837P,
+			PatientControlNumber: "PCN-2",
+			FrequencyCode:        FrequencyOriginal,
+			BillingProviderName:  "NAIROBI HOSPITAL",
+			BillingProviderID:    "LIC-001",
+			SubscriberID:         "MEM-001",
+			SubscriberName:       "JANE MWANGI",
+			TotalCharge:          money.MustParse("200"),
+			Currency:             "KES",
+			DiagnosisCodes:       []string{"Z000", "R05"},
+			ServiceDate:          "2024-01-11",
+			ServiceLines: []*ServiceLine{
+				{ProcedureCode: "99214", Charge: money.MustParse("120")},
+				{ProcedureCode: "99215", Charge: money.MustParse("80"), ServiceDate: "2024-01-12"},
+			},
+			Position: 15,
+		},
+	}
+
+	if !reflect.DeepEqual(claims, want) {
+		for i := range claims {
+			t.Logf("claim %d: %+v", i, *claims[i])
+		}
+		t.Errorf("ReadClaims did not return the expected claims")
+	}
+}
+
+func TestReadClaimsInstitutional(t *testing.T) {
+	claims := readClaims(t, institutional837)
+	if len(claims) != 2 {
+		t.Fatalf("got %d claims, want 2", len(claims))
+	}
+
+	inpatient, outpatient := claims[0], claims[1]
+	if inpatient.Type != Type837I || inpatient.Currency != "" {
+		t.Errorf("inpatient type %q, currency %q; want 837I and no currency", inpatient.Type, inpatient.Currency)
+	}
+	if inpatient.ServiceDate != "2024-01-10" || inpatient.AdmissionDate != "2024-01-10" || inpatient.DischargeDate != "2024-01-12" {
+		t.Errorf("inpatient service %q, admission %q, discharge %q; want 2024-01-10, 2024-01-10, 2024-01-12",
+			inpatient.ServiceDate, inpatient.AdmissionDate, inpatient.DischargeDate)
+	}
+	if len(inpatient.ServiceLines) != 1 || inpatient.ServiceLines[0].ProcedureCode != "99284" ||
+		inpatient.ServiceLines[0].Charge != money.MustParse("900") {
+		t.Errorf("inpatient service lines = %+v, want one 99284 line of 900", inpatient.ServiceLines)
+	}
+
+	// a statement period without an admission is not a stay, so there is no discharge date
+	if outpatient.ServiceDate != "2024-01-13" || outpatient.AdmissionDate != "" || outpatient.DischargeDate != "" {
+		t.Errorf("outpatient service %q, admission %q, discharge %q; want 2024-01-13 and no stay",
+			outpatient.ServiceDate, outpatient.AdmissionDate, outpatient.DischargeDate)
+	}
+}
+
+func TestReadClaimsTypeFromFacilityCode(t *testing.T) {
+	// without an implementation guide in ST03 the CLM05-2 facility code decides the type
+	data := strings.ReplaceAll(institutional837, "*005010X223A2~", "*005010~")
+	data = strings.Replace(data, "13:A:1", "13:B:1", 1)
+
+	claims := readClaims(t, data)
+	if claims[0].Type != Type837I || claims[1].Type != Type837P {
+		t.Errorf("types = %q, %q; want 837I, 837P", claims[0].Type, claims[1].Type)
+	}
+}
+
+func TestReadClaimsFrequencyCode(t *testing.T) {
+	// a replacement and a claim without CLM05-3, which counts as an original
+	data := strings.Replace(professional837, "11:B:1", "11:B:7", 1)
+	data = strings.Replace(data, "CLM*PCN-2*200***11:B:1*", "CLM*PCN-2*200***11:B*", 1)
+
+	claims := readClaims(t, data)
+	if claims[0].FrequencyCode != FrequencyReplacement || claims[1].FrequencyCode != FrequencyOriginal {
+		t.Errorf("frequency codes = %q, %q; want %q, %q",
+			claims[0].FrequencyCode, claims[1].FrequencyCode, FrequencyReplacement, FrequencyOriginal)
+	}
+}
+
+func TestReadClaimsErrors(t *testing.T) {
+	cases := map[string]string{
+		"not an 837":        strings.Replace(professional837, "ST*837*", "ST*835*", 1),
+		"bad claim charge":  strings.Replace(professional837, "CLM*PCN-1*1500.50*", "CLM*PCN-1*15OO*", 1),
+		"bad line charge":   strings.Replace(professional837, "SV1*HC:99213*1500.50*", "SV1*HC:99213*abc*", 1),
+		"bad date":          strings.Replace(professional837, "DTP*472*D8*20240110", "DTP*472*D8*2024", 1),
+		"bad date range":    strings.Replace(institutional837, "RD8*20240110-20240112", "RD8*20240110", 1),
+		"unsupported dates": strings.Replace(professional837, "DTP*472*D8*", "DTP*472*D6*", 1),
+	}
+	for name, data := range cases {
+		interchange, err := Parse([]byte(data))
+		if err != nil {
+			t.Fatalf("%v: Parse: %v", name, err)
+		}
+		if _, err := ReadClaims(interchange); err == nil {
+			t.Errorf("%v: ReadClaims succeeded, want error", name)
+		}
+	}
+}
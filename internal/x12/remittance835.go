@@ -0,0 +1,151 @@
+package x12
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+const version835 = "005010X221A1"
+
+// CLP02 claim status codes.
+const (
+	ClaimStatusProcessed = "1"
+	ClaimStatusDenied    = "4"
+)
+
+// Remittance is one 835 transaction: the adjudicated claims for a single payee.
+type Remittance struct {
+	PayerName string
+	PayerID   string
+	PayeeName string
+	PayeeID   string
+	Currency  string // of every amount in the remittance, sent in a CUR segment when set
+	Claims    []*RemittanceClaim
+}
+
+type RemittanceClaim struct {
+	PatientControlNumber string // CLM01 from the 837
+	PayerClaimID         string // our claim ID, empty when nothing was stored
+	Status               string // ClaimStatusProcessed or ClaimStatusDenied
+	SubscriberID         string
+	ProcedureCode        string
+	ServiceDate          string // YYYY-MM-DD
+	Charge               money.Money
+	Paid                 money.Money
+	AdjustmentReason     string // CARC code for Charge - Paid, e.g. "45"
+}
+
+// Header identifies the 835 interchange; SenderID and ReceiverID are usually the 837's, swapped.
+type Header struct {
+	SenderID      string
+	ReceiverID    string
+	ControlNumber int
+	Date          time.Time
+	Production    bool
+}
+
+// Write835 renders the remittances as an 835 interchange with one transaction per payee.
+func Write835(
+	header *Header,
+	separators Separators,
+	remittances []*Remittance,
+) []byte {
+
+	w := &writer{separators: separators}
+	control := fmt.Sprintf("%09d", header.ControlNumber%1000000000)
+	groupControl := strconv.Itoa(header.ControlNumber % 1000000000)
+	date := header.Date.UTC()
+
+	usage := "T"
+	if header.Production {
+		usage = "P"
+	}
+
+	w.segment(
+		"ISA", "00", strings.Repeat(" ", 10), "00", strings.Repeat(" ", 10),
+		"ZZ", padRight(header.SenderID, 15),
+		"ZZ", padRight(header.ReceiverID, 15),
+		date.Format("060102"), date.Format("1504"),
+		string(separators.Repetition), "00501", control, "0", usage,
+		string(separators.Component),
+	)
+	w.segment("GS", "HP", header.SenderID, header.ReceiverID, date.Format("20060102"), date.Format("1504"), groupControl, "X", version835)
+
+	for index, remittance := range remittances {
+		w.count = 0
+		transactionControl := fmt.Sprintf("%04d", index+1)
+
+		var total money.Money
+		for _, claim := range remittance.Claims {
+			total = total.Add(claim.Paid)
+		}
+
+		w.segment("ST", "835", transactionControl, version835)
+		w.segment("BPR", "I", formatAmount(total), "C", "NON", "", "", "", "", "", "", "", "", "", "", "", date.Format("20060102"))
+		w.segment("TRN", "1", control+transactionControl, remittance.PayerID)
+		if remittance.Currency != "" {
+			w.segment("CUR", "PR", remittance.Currency)
+		}
+		w.segment("DTM", "405", date.Format("20060102"))
+		w.segment("N1", "PR", remittance.PayerName)
+		w.segment("N1", "PE", remittance.PayeeName, "XX", remittance.PayeeID)
+		w.segment("LX", "1")
+
+		for _, claim := range remittance.Claims {
+			adjustment := claim.Charge.Sub(claim.Paid)
+
+			w.segment(
+				"CLP",
+				claim.PatientControlNumber,
+				claim.Status,
+				formatAmount(claim.Charge),
+				formatAmount(claim.Paid),
+				"0",
+				"12",
+				claim.PayerClaimID,
+			)
+			if adjustment.IsPositive() && claim.AdjustmentReason != "" {
+				w.segment("CAS", "CO", claim.AdjustmentReason, formatAmount(adjustment))
+			}
+			if claim.SubscriberID != "" {
+				w.segment("NM1", "QC", "1", "", "", "", "", "", "MI", claim.SubscriberID)
+			}
+			if claim.ServiceDate != "" {
+				w.segment("DTM", "232", compactDate(claim.ServiceDate))
+			}
+			if claim.ProcedureCode != "" {
+				w.segment("SVC", w.composite("HC", claim.ProcedureCode), formatAmount(claim.Charge), formatAmount(claim.Paid))
+				if claim.ServiceDate != "" {
+					w.segment("DTM", "472", compactDate(claim.ServiceDate))
+				}
+			}
+		}
+
+		w.segment("SE", strconv.Itoa(w.count+1), transactionControl)
+	}
+
+	w.segment("GE", strconv.Itoa(len(remittances)), groupControl)
+	w.segment("IEA", "1", control)
+
+	return w.buffer.Bytes()
+}
+
+// formatAmount writes an X12 decimal without trailing zeros, e.g. 1500.5 or 200.
+func formatAmount(amount money.Money) string {
+	return strings.TrimSuffix(strings.TrimRight(amount.String(), "0"), ".")
+}
+
+func compactDate(isoDate string) string {
+	return strings.ReplaceAll(isoDate, "-", "")
+}
+
+func padRight(value string, width int) string {
+	if len(value) >= width {
+		return value[:width]
+	}
+	return value + strings.Repeat(" ", width-len(value))
+}
@@ -0,0 +1,162 @@
+package x12
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+)
+
+func testRemittances() []*Remittance {
+	return []*Remittance{
+		{
+			PayerName: "GINJA AI",
+			PayerID:   "GINJA",
+			PayeeName: "NAIROBI HOSPITAL",
+			PayeeID:   "LIC-001",
+			Currency:  "KES",
+			Claims: []*RemittanceClaim{
+				{
+					PatientControlNumber: "PCN-1",
+					PayerClaimID:         "101",
+					Status:               ClaimStatusProcessed,
+					SubscriberID:         "MEM-001",
+					ProcedureCode:        "99213",
+					ServiceDate:          "2024-01-10",
+					Charge:               money.MustParse("1500.50"),
+					Paid:                 money.MustParse("1200"),
+					AdjustmentReason:     "45",
+				},
+				{
+					PatientControlNumber: "PCN-2",
+					Status:               ClaimStatusDenied,
+					Charge:               money.MustParse("200"),
+					Paid:                 money.MustParse("0"),
+					AdjustmentReason:     "16",
+				},
+			},
+		},
+		{
+			PayerName: "GINJA AI",
+			PayerID:   "GINJA",
+			PayeeName: "AGA KHAN HOSPITAL",
+			PayeeID:   "LIC-002",
+			Claims: []*RemittanceClaim{
+				{
+					PatientControlNumber: "INP-1",
+					PayerClaimID:         "102",
+					Status:               ClaimStatusProcessed,
+					Charge:               money.MustParse("900"),
+					Paid:                 money.MustParse("900"),
+					AdjustmentReason:     "45",
+				},
+			},
+		},
+	}
+}
+
+func write835(t *testing.T, separators Separators) *Interchange {
+	t.Helper()
+
+	header := &Header{
+		SenderID:      "GINJA",
+		ReceiverID:    "HOSPITAL01",
+		ControlNumber: 1705320000,
+		Date:          time.Date(2024, 1, 15, 12, 30, 0, 0, time.UTC),
+	}
+	interchange, err := Parse(Write835(header, separators, testRemittances()))
+	if err != nil {
+		t.Fatalf("Parse(Write835): %v", err)
+	}
+	return interchange
+}
+
+// segmentsOf renders the segments with the default separators, one per line.
+func segmentsOf(interchange *Interchange, from, to int) string {
+	lines := make([]string, 0, to-from)
+	for _, segment := range interchange.Segments[from:to] {
+		lines = append(lines, strings.Join(segment.Elements, "*"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestWrite835(t *testing.T) {
+	interchange := write835(t, DefaultSeparators)
+
+	if interchange.SenderID != "GINJA" || interchange.ReceiverID != "HOSPITAL01" ||
+		interchange.ControlNumber != "705320000" || interchange.Production {
+		t.Errorf("envelope = %q -> %q, control %q, production %v; want GINJA -> HOSPITAL01, 705320000, test",
+			interchange.SenderID, interchange.ReceiverID, interchange.ControlNumber, interchange.Production)
+	}
+
+	want := `GS*HP*GINJA*HOSPITAL01*20240115*1230*705320000*X*005010X221A1
+ST*835*0001*005010X221A1
+BPR*I*1200*C*NON************20240115
+TRN*1*7053200000001*GINJA
+CUR*PR*KES
+DTM*405*20240115
+N1*PR*GINJA AI
+N1*PE*NAIROBI HOSPITAL*XX*LIC-001
+LX*1
+CLP*PCN-1*1*1500.5*1200*0*12*101
+CAS*CO*45*300.5
+NM1*QC*1******MI*MEM-001
+DTM*232*20240110
+SVC*HC:99213*1500.5*1200
+DTM*472*20240110
+CLP*PCN-2*4*200*0*0*12
+CAS*CO*16*200
+SE*17*0001
+ST*835*0002*005010X221A1
+BPR*I*900*C*NON************20240115
+TRN*1*7053200000002*GINJA
+DTM*405*20240115
+N1*PR*GINJA AI
+N1*PE*AGA KHAN HOSPITAL*XX*LIC-002
+LX*1
+CLP*INP-1*1*900*900*0*12*102
+SE*9*0002
+GE*2*705320000
+IEA*1*705320000`
+
+	if got := segmentsOf(interchange, 1, len(interchange.Segments)); got != want {
+		t.Errorf("Write835 segments:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+// Every SE01 must count the segments of its transaction, ST and SE included.
+func TestWrite835SegmentCounts(t *testing.T) {
+	interchange := write835(t, DefaultSeparators)
+
+	transactions, start := 0, 0
+	for i, segment := range interchange.Segments {
+		switch segment.ID() {
+		case "ST":
+			start = i
+		case "SE":
+			transactions++
+			if count := strconv.Itoa(i - start + 1); segment.Element(1) != count {
+				t.Errorf("SE%v counts %v segments, transaction has %v", segment.Element(2), segment.Element(1), count)
+			}
+		}
+	}
+	if transactions != 2 {
+		t.Errorf("got %d transactions, want one per payee", transactions)
+	}
+}
+
+func TestWrite835EchoesSeparators(t *testing.T) {
+	separators := Separators{Element: '|', Repetition: '!', Component: '>', Segment: '\r'}
+	interchange := write835(t, separators)
+
+	if interchange.Separators != separators {
+		t.Errorf("separators = %+v, want %+v", interchange.Separators, separators)
+	}
+	for _, segment := range interchange.Segments {
+		if segment.ID() == "SVC" && segment.Element(1) != "HC>99213" {
+			t.Errorf("SVC01 = %q, want HC>99213", segment.Element(1))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package x12 reads and writes ASC X12 005010 interchanges: 837P/837I claims in, 835 remittance
+// advice out.
+package x12
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// isaLength is the fixed length of the ISA segment, including its terminator.
+const isaLength = 106
+
+// Separators are the delimiters an interchange declares in its ISA header.
+type Separators struct {
+	Element    byte // ISA position 4
+	Repetition byte // ISA11
+	Component  byte // ISA16
+	Segment    byte // the character after ISA16
+}
+
+// DefaultSeparators are used when writing without an inbound interchange to echo.
+var DefaultSeparators = Separators{
+	Element:    '*',
+	Repetition: '^',
+	Component:  ':',
+	Segment:    '~',
+}
+
+// Segment is one X12 segment. Elements[0] is the segment ID, so Elements[n] is element n.
+type Segment struct {
+	Elements []string
+	Position int // 1-based position in the interchange, for error messages
+}
+
+func (s Segment) ID() string {
+	return s.Elements[0]
+}
+
+// Element returns element n (1-based), or "" when the segment is shorter.
+func (s Segment) Element(n int) string {
+	if n < len(s.Elements) {
+		return s.Elements[n]
+	}
+	return ""
+}
+
+// Interchange is a parsed ISA/IEA envelope.
+type Interchange struct {
+	Separators    Separators
+	SenderID      string
+	ReceiverID    string
+	ControlNumber string
+	Production    bool // ISA15 usage indicator is P rather than T
+	Segments      []Segment
+}
+
+// Component returns component n (1-based) of a composite element value.
+func (i *Interchange) Component(value string, n int) string {
+	components := strings.Split(value, string(i.Separators.Component))
+	if n-1 < len(components) {
+		return components[n-1]
+	}
+	return ""
+}
+
+// Parse splits an interchange into segments using the separators declared in its ISA header.
+func Parse(data []byte) (*Interchange, error) {
+	data = bytes.TrimLeft(data, "\ufeff \t\r\n")
+	if len(data) < isaLength || string(data[:3]) != "ISA" {
+		return nil, fmt.Errorf("interchange must start with a %d character ISA segment", isaLength)
+	}
+
+	separators := Separators{
+		Element:    data[3],
+		Repetition: data[82],
+		Component:  data[104],
+		Segment:    data[105],
+	}
+
+	isa := strings.Split(string(data[:isaLength-1]), string(separators.Element))
+	if len(isa) != 17 {
+		return nil, fmt.Errorf("ISA segment has %d elements, expected 16", len(isa)-1)
+	}
+
+	interchange := &Interchange{
+		Separators:    separators,
+		SenderID:      strings.TrimSpace(isa[6]),
+		ReceiverID:    strings.TrimSpace(isa[8]),
+		ControlNumber: strings.TrimSpace(isa[13]),
+		Production:    strings.TrimSpace(isa[15]) == "P",
+	}
+
+	for _, raw := range strings.Split(string(data), string(separators.Segment)) {
+		raw = strings.Trim(raw, " \t\r\n")
+		if raw == "" {
+			continue
+		}
+		interchange.Segments = append(interchange.Segments, Segment{
+			Elements: strings.Split(raw, string(separators.Element)),
+			Position: len(interchange.Segments) + 1,
+		})
+	}
+
+	last := interchange.Segments[len(interchange.Segments)-1]
+	if last.ID() != "IEA" {
+		return nil, fmt.Errorf("interchange must end with an IEA segment")
+	}
+	if strings.TrimSpace(last.Element(2)) != interchange.ControlNumber {
+		return nil, fmt.Errorf(
+			"IEA control number [%v] does not match ISA control number [%v]",
+			last.Element(2),
+			interchange.ControlNumber,
+		)
+	}
+
+	return interchange, nil
+}
+
+// writer builds an interchange with the given separators.
+type writer struct {
+	separators Separators
+	buffer     bytes.Buffer
+	count      int // segments since the last ST
+}
+
+func (w *writer) segment(elements ...string) {
+	for i := len(elements) - 1; i > 0 && elements[i] == ""; i-- {
+		elements = elements[:i]
+	}
+	w.buffer.WriteString(strings.Join(elements, string(w.separators.Element)))
+	w.buffer.WriteByte(w.separators.Segment)
+	w.buffer.WriteByte('\n')
+	w.count++
+}
+
+func (w *writer) composite(components ...string) string {
+	return strings.Join(components, string(w.separators.Component))
+}
@@ -0,0 +1,123 @@
+package x12
+
+import (
+	"strings"
+	"testing"
+)
+
+// professional837 is an 837P with two claims from one billing provider: the first with a single
+// service line, the second with two lines and a secondary diagnosis first in the HI segment.
+const professional837 = `ISA*00*          *00*          *ZZ*HOSPITAL01     *ZZ*GINJA          *240115*1200*^*00501*000000017*0*P*:~
+GS*HC*HOSPITAL01*GINJA*20240115*1200*17*X*005010X222A1~
+ST*837*0001*005010X222A1~
+BHT*0019*00*1*20240115*1200*CH~
+HL*1**20*1~
+NM1*85*2*NAIROBI HOSPITAL*****XX*LIC-001~
+CUR*85*kes~
+HL*2*1*22*0~
+NM1*IL*1*MWANGI*JANE****MI*MEM-001~
+CLM*PCN-1*1500.50***11:B:1*Y*A*Y*Y~
+HI*ABK:J069*ABF:R509~
+LX*1~
+SV1*HC:99213*1500.50*UN*1***1~
+DTP*472*D8*20240110~
+CLM*PCN-2*200***11:B:1*Y*A*Y*Y~
+HI*ABF:R05*ABK:Z000~
+DTP*472*D8*20240111~
+LX*1~
+SV1*HC:99214*120*UN*1***1~
+LX*2~
+SV1*HC:99215*80*UN*1***1~
+DTP*472*D8*20240112~
+SE*20*0001~
+GE*1*17~
+IEA*1*000000017~
+`
+
+// institutional837 is an 837I inpatient claim and an outpatient claim whose statement period
+// has no admission.
+const institutional837 = `ISA*00*          *00*          *ZZ*HOSPITAL02     *ZZ*GINJA          *240115*1200*^*00501*000000018*0*T*:~
+GS*HC*HOSPITAL02*GINJA*20240115*1200*18*X*005010X223A2~
+ST*837*0001*005010X223A2~
+HL*1**20*1~
+NM1*85*2*AGA KHAN HOSPITAL*****XX*LIC-002~
+HL*2*1*22*0~
+NM1*IL*1*OTIENO*PAUL****MI*MEM-002~
+CLM*INP-1*900***11:A:1**A*Y*Y~
+DTP*434*RD8*20240110-20240112~
+DTP*435*DT*202401100830~
+HI*ABK:A099~
+LX*1~
+SV2*0450*HC:99284*900*UN*1~
+CLM*OUT-1*300***13:A:1**A*Y*Y~
+DTP*434*RD8*20240113-20240114~
+HI*ABK:B349~
+LX*1~
+SV2*0510*HC:99203*300*UN*1~
+SE*17*0001~
+GE*1*18~
+IEA*1*000000018~
+`
+
+func TestParseReadsTheISAHeader(t *testing.T) {
+	interchange, err := Parse([]byte(professional837))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if interchange.Separators != DefaultSeparators {
+		t.Errorf("separators = %+v, want %+v", interchange.Separators, DefaultSeparators)
+	}
+	if interchange.SenderID != "HOSPITAL01" || interchange.ReceiverID != "GINJA" {
+		t.Errorf("sender, receiver = %q, %q; want HOSPITAL01, GINJA", interchange.SenderID, interchange.ReceiverID)
+	}
+	if interchange.ControlNumber != "000000017" || !interchange.Production {
+		t.Errorf("control number %q, production %v; want 000000017, true", interchange.ControlNumber, interchange.Production)
+	}
+	if len(interchange.Segments) != 25 {
+		t.Errorf("got %d segments, want 25", len(interchange.Segments))
+	}
+
+	clm := interchange.Segments[9]
+	if clm.ID() != "CLM" || clm.Position != 10 || clm.Element(1) != "PCN-1" || clm.Element(20) != "" {
+		t.Errorf("segment 10 = %+v, want the first CLM", clm)
+	}
+	if got := interchange.Component(clm.Element(5), 2); got != "B" {
+		t.Errorf("CLM05-2 = %q, want B", got)
+	}
+}
+
+func TestParseUsesTheDeclaredSeparators(t *testing.T) {
+	data := strings.NewReplacer("*", "|", ":", ">", "^", "!", "~", "\r\n").Replace(professional837)
+
+	interchange, err := Parse([]byte("\ufeff" + data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := Separators{Element: '|', Repetition: '!', Component: '>', Segment: '\r'}
+	if interchange.Separators != want {
+		t.Errorf("separators = %+v, want %+v", interchange.Separators, want)
+	}
+	if len(interchange.Segments) != 25 {
+		t.Errorf("got %d segments, want 25", len(interchange.Segments))
+	}
+	if got := interchange.Component(interchange.Segments[12].Element(1), 2); got != "99213" {
+		t.Errorf("SV101-2 = %q, want 99213", got)
+	}
+}
+
+func TestParseRejectsBrokenEnvelopes(t *testing.T) {
+	cases := map[string]string{
+		"empty":              "",
+		"no ISA":             strings.Replace(professional837, "ISA", "XXX", 1),
+		"short ISA":          strings.Replace(professional837, "*00*          *00*", "*00*     *00*", 1),
+		"missing IEA":        strings.Replace(professional837, "IEA*1*000000017~\n", "", 1),
+		"mismatched control": strings.Replace(professional837, "IEA*1*000000017", "IEA*1*000000099", 1),
+	}
+	for name, data := range cases {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%v: Parse succeeded, want error", name)
+		}
+	}
+}
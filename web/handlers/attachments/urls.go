@@ -0,0 +1,22 @@
+package attachments
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	protected *gin.RouterGroup,
+	admin *gin.RouterGroup,
+	dB db.DB,
+	attachmentService services.AttachmentService,
+	maxUploadSize int64,
+) {
+	protected.POST("/claims/:id/attachments", uploadAttachment(dB, attachmentService, maxUploadSize))
+	protected.GET("/claims/:id/attachments", listAttachments(dB, attachmentService))
+	protected.GET("/claims/:id/attachments/:attachmentID", downloadAttachment(dB, attachmentService))
+	protected.GET("/procedures/:code/required-documents", getDocumentRequirements(dB, attachmentService))
+
+	admin.PUT("/procedures/:code/required-documents", setDocumentRequirements(dB, attachmentService))
+}
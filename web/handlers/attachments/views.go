@@ -0,0 +1,162 @@
+package attachments
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	middleware "github.com/Doris-Mwito5/ginja-ai/internal/middleware"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func uploadAttachment(
+	dB db.DB,
+	attachmentService services.AttachmentService,
+	maxUploadSize int64,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		claimID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid claim id"))
+			return
+		}
+
+		file, err := utils.OpenFormFile(c, "file", maxUploadSize)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		defer file.Close()
+
+		fileHeader, _ := c.FormFile("file")
+
+		req := dtos.ClaimAttachmentUpload{
+			DocumentType: c.PostForm("document_type"),
+			FileName:     fileHeader.Filename,
+			File:         file,
+			UploadedBy:   middleware.GetAuthPayload(c).Username,
+		}
+
+		attachment, err := attachmentService.UploadAttachment(c.Request.Context(), dB, claimID, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, attachment)
+	}
+}
+
+func listAttachments(
+	dB db.DB,
+	attachmentService services.AttachmentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		claimID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid claim id"))
+			return
+		}
+
+		attachmentList, err := attachmentService.GetClaimAttachments(c.Request.Context(), dB, claimID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, attachmentList)
+	}
+}
+
+func downloadAttachment(
+	dB db.DB,
+	attachmentService services.AttachmentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		claimID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid claim id"))
+			return
+		}
+
+		attachmentID, err := strconv.ParseInt(c.Param("attachmentID"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid attachment id"))
+			return
+		}
+
+		attachment, body, err := attachmentService.DownloadAttachment(c.Request.Context(), dB, claimID, attachmentID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		defer body.Close()
+
+		c.DataFromReader(
+			http.StatusOK,
+			attachment.SizeBytes,
+			attachment.ContentType,
+			body,
+			map[string]string{
+				"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+				"ETag":                `"` + attachment.Checksum + `"`,
+			},
+		)
+	}
+}
+
+func getDocumentRequirements(
+	dB db.DB,
+	attachmentService services.AttachmentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		requirements, err := attachmentService.GetDocumentRequirements(
+			c.Request.Context(),
+			dB,
+			strings.TrimSpace(c.Param("code")),
+		)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, requirements)
+	}
+}
+
+func setDocumentRequirements(
+	dB db.DB,
+	attachmentService services.AttachmentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		var req dtos.DocumentRequirements
+		if err := c.BindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		requirements, err := attachmentService.SetDocumentRequirements(
+			c.Request.Context(),
+			dB,
+			strings.TrimSpace(c.Param("code")),
+			&req,
+		)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, requirements)
+	}
+}
@@ -0,0 +1,16 @@
+package auditlog
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	auditors *gin.RouterGroup,
+	dB db.DB,
+	auditService services.AuditService,
+) {
+	auditors.GET("/audit-log", listAuditEntries(dB, auditService))
+	auditors.GET("/audit-log/verify", verifyAuditLog(dB, auditService))
+}
@@ -0,0 +1,48 @@
+package auditlog
+
+import (
+	"net/http"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/ctxfilter"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func listAuditEntries(
+	dB db.DB,
+	auditService services.AuditService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		filter, err := ctxfilter.FilterFromContext(c)
+		if err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		entryList, err := auditService.GetAuditEntries(c.Request.Context(), dB, filter)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, entryList)
+	}
+}
+
+func verifyAuditLog(
+	dB db.DB,
+	auditService services.AuditService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		verification, err := auditService.VerifyAuditLog(c.Request.Context(), dB)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, verification)
+	}
+}
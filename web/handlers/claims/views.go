@@ -35,6 +35,11 @@ func createClaim(
 			return
 		}
 
+		if result.DryRun {
+			c.JSON(http.StatusOK, result)
+			return
+		}
+
 		c.JSON(http.StatusCreated, result)
 	}
 }
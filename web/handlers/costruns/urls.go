@@ -0,0 +1,19 @@
+package costruns
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	costRunService services.CostRunService,
+) {
+	r.GET("/cost-runs", listCostRuns(dB, costRunService))
+	r.GET("/cost-runs/:id", getCostRun(dB, costRunService))
+	r.POST("/cost-runs", createCostRun(dB, costRunService))
+	r.POST("/cost-runs/:id/approve", approveCostRun(dB, costRunService))
+	r.POST("/cost-runs/:id/reject", rejectCostRun(dB, costRunService))
+}
@@ -0,0 +1,142 @@
+package costruns
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/ctxfilter"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/middleware"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func createCostRun(
+	dB db.DB,
+	costRunService services.CostRunService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var req dtos.CostRunRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&req); err != nil {
+				utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+				return
+			}
+		}
+
+		dryRun, err := strconv.ParseBool(strings.TrimSpace(c.DefaultQuery("dry_run", "false")))
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid dry_run"))
+			return
+		}
+
+		if dryRun {
+			run, err := costRunService.PreviewCostRun(c.Request.Context(), dB, &req)
+			if err != nil {
+				utils.HandleError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, run)
+			return
+		}
+
+		run, err := costRunService.CreateCostRun(c.Request.Context(), dB, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, run)
+	}
+}
+
+func listCostRuns(
+	dB db.DB,
+	costRunService services.CostRunService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		filter, err := ctxfilter.FilterFromContext(c)
+		if err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		runList, err := costRunService.GetCostRuns(c.Request.Context(), dB, filter)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, runList)
+	}
+}
+
+func getCostRun(
+	dB db.DB,
+	costRunService services.CostRunService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		runID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid cost run id"))
+			return
+		}
+
+		run, err := costRunService.GetCostRunByID(c.Request.Context(), dB, runID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, run)
+	}
+}
+
+func approveCostRun(
+	dB db.DB,
+	costRunService services.CostRunService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		runID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid cost run id"))
+			return
+		}
+
+		payload := middleware.GetAuthPayload(c)
+		run, err := costRunService.ApproveCostRun(c.Request.Context(), dB, runID, payload.Username)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, run)
+	}
+}
+
+func rejectCostRun(
+	dB db.DB,
+	costRunService services.CostRunService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		runID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid cost run id"))
+			return
+		}
+
+		payload := middleware.GetAuthPayload(c)
+		run, err := costRunService.RejectCostRun(c.Request.Context(), dB, runID, payload.Username)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, run)
+	}
+}
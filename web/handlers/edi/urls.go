@@ -0,0 +1,15 @@
+package edi
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	x12Service services.X12Service,
+) {
+	r.POST("/x12/837", uploadClaims(dB, x12Service))
+}
@@ -0,0 +1,70 @@
+package edi
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxUploadSize is the largest 837 interchange accepted by the upload endpoint.
+const MaxUploadSize = 10 << 20
+
+// uploadClaims adjudicates an uploaded 837. The response is JSON with the 835 inside, or the raw
+// 835 with format=835.
+func uploadClaims(
+	dB db.DB,
+	x12Service services.X12Service,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		dryRun, err := strconv.ParseBool(strings.TrimSpace(c.DefaultQuery("dry_run", "false")))
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid dry_run"))
+			return
+		}
+
+		format := strings.TrimSpace(c.DefaultQuery("format", "json"))
+		if format != "json" && format != "835" {
+			utils.HandleError(c, apperr.NewBadRequest("format must be json or 835"))
+			return
+		}
+
+		file, err := utils.OpenFormFile(c, "file", MaxUploadSize)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("failed to read uploaded file"))
+			return
+		}
+
+		result, err := x12Service.ProcessClaims(c.Request.Context(), dB, data, dryRun)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		status := http.StatusCreated
+		if dryRun {
+			status = http.StatusOK
+		}
+
+		if format == "835" {
+			c.Data(status, "application/edi-x12", []byte(result.Remittance))
+			return
+		}
+
+		c.JSON(status, result)
+	}
+}
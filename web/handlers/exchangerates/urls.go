@@ -0,0 +1,18 @@
+package exchangerates
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	protected *gin.RouterGroup,
+	admin *gin.RouterGroup,
+	dB db.DB,
+	exchangeRateService services.ExchangeRateService,
+) {
+	protected.GET("/exchange-rates", listExchangeRates(dB, exchangeRateService))
+
+	admin.POST("/exchange-rates/upload", uploadExchangeRates(dB, exchangeRateService))
+}
@@ -0,0 +1,64 @@
+package exchangerates
+
+import (
+	"net/http"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/ctxfilter"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxUploadSize is the largest exchange rate CSV accepted by the upload endpoint.
+const MaxUploadSize = 10 << 20
+
+func listExchangeRates(
+	dB db.DB,
+	exchangeRateService services.ExchangeRateService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		filter, err := ctxfilter.FilterFromContext(c)
+		if err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		rateList, err := exchangeRateService.GetExchangeRates(c.Request.Context(), dB, filter)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, rateList)
+	}
+}
+
+func uploadExchangeRates(
+	dB db.DB,
+	exchangeRateService services.ExchangeRateService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		options, err := ctxfilter.ImportOptionsFromContext(c)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		file, err := utils.OpenFormFile(c, "file", MaxUploadSize)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		defer file.Close()
+
+		result, err := exchangeRateService.ImportExchangeRates(c.Request.Context(), dB, file, options)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(utils.ImportStatusCode(result), result)
+	}
+}
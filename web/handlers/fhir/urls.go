@@ -0,0 +1,16 @@
+package fhir
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	fhirService services.FHIRService,
+) {
+	r.POST("/fhir/Claim", submitClaim(dB, fhirService))
+	r.POST("/fhir/CoverageEligibilityRequest", checkEligibility(dB, fhirService))
+}
@@ -0,0 +1,114 @@
+package fhir
+
+import (
+	"net/http"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/logger"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const contentType = "application/fhir+json; charset=utf-8"
+
+func submitClaim(
+	dB db.DB,
+	fhirService services.FHIRService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		var req dtos.FHIRClaim
+		if err := c.ShouldBindJSON(&req); err != nil {
+			handleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		claimResponse, err := fhirService.SubmitClaim(c.Request.Context(), dB, &req)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		status := http.StatusCreated
+		if claimResponse.Use != "claim" {
+			status = http.StatusOK
+		}
+
+		c.Header("Content-Type", contentType)
+		c.JSON(status, claimResponse)
+	}
+}
+
+func checkEligibility(
+	dB db.DB,
+	fhirService services.FHIRService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		var req dtos.FHIRCoverageEligibilityRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			handleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		eligibilityResponse, err := fhirService.CheckEligibility(c.Request.Context(), dB, &req)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Header("Content-Type", contentType)
+		c.JSON(http.StatusOK, eligibilityResponse)
+	}
+}
+
+// handleError answers with an OperationOutcome instead of the usual error body, since FHIR
+// clients only understand FHIR resources.
+func handleError(c *gin.Context, err error) {
+	appErr, ok := err.(*apperr.Error)
+	if !ok {
+		logger.FromContext(c.Request.Context()).Errorf("fhir request failed with unknown error: [%+v]", err)
+		appErr = apperr.NewInternal("unexpected error")
+	}
+	utils.LogError(c, appErr)
+
+	severity := "error"
+	if appErr.Status() >= http.StatusInternalServerError {
+		severity = "fatal"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.JSON(appErr.Status(), dtos.FHIROperationOutcome{
+		ResourceType: "OperationOutcome",
+		Issue: []dtos.FHIROperationOutcomeIssue{{
+			Severity:    severity,
+			Code:        issueType(appErr.Type),
+			Diagnostics: appErr.Message,
+		}},
+	})
+}
+
+// issueType maps an error type to the FHIR IssueType value set.
+func issueType(errorType apperr.Type) string {
+	switch errorType {
+	case apperr.BadRequest, apperr.UnsupportedMediaType:
+		return "invalid"
+	case apperr.NotFound:
+		return "not-found"
+	case apperr.Conflict:
+		return "conflict"
+	case apperr.Authorization:
+		return "login"
+	case apperr.Permission:
+		return "forbidden"
+	case apperr.PayloadTooLarge:
+		return "too-long"
+	case apperr.TooManyRequests:
+		return "throttled"
+	default:
+		return "exception"
+	}
+}
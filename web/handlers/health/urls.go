@@ -0,0 +1,19 @@
+package health
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	probes *gin.RouterGroup,
+	admins *gin.RouterGroup,
+	dB db.DB,
+	healthService services.HealthService,
+) {
+	probes.GET("/healthz", liveness())
+	probes.GET("/readyz", readiness(dB, healthService))
+
+	admins.GET("/status", status(dB, healthService))
+}
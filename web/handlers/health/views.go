@@ -0,0 +1,41 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// liveness only shows the process is serving requests; it never touches the database, so a
+// database outage does not get the process restarted.
+func liveness() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+func readiness(
+	dB db.DB,
+	healthService services.HealthService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		result := healthService.Readiness(c.Request.Context(), dB)
+		if !result.Ready {
+			c.JSON(http.StatusServiceUnavailable, result)
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func status(
+	dB db.DB,
+	healthService services.HealthService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, healthService.Status(c.Request.Context(), dB))
+	}
+}
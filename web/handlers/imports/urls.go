@@ -0,0 +1,17 @@
+package imports
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	importService services.ImportService,
+) {
+	r.POST("/procedures/import", importCSV(dB, importService.ImportProcedures))
+	r.POST("/providers/import", importCSV(dB, importService.ImportProviders))
+	r.POST("/members/import", importCSV(dB, importService.ImportMembers))
+}
@@ -0,0 +1,45 @@
+package imports
+
+import (
+	"context"
+	"io"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/ctxfilter"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxUploadSize is the largest CSV accepted by the import endpoints.
+const MaxUploadSize = 20 << 20
+
+type importFunc func(ctx context.Context, dB db.DB, reader io.Reader, options *dtos.ImportOptions) (*dtos.ImportResult, error)
+
+func importCSV(
+	dB db.DB,
+	runImport importFunc,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		options, err := ctxfilter.ImportOptionsFromContext(c)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		file, err := utils.OpenFormFile(c, "file", MaxUploadSize)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		defer file.Close()
+
+		result, err := runImport(c.Request.Context(), dB, file, options)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(utils.ImportStatusCode(result), result)
+	}
+}
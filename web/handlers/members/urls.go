@@ -7,9 +7,18 @@ import (
 )
 
 func AddEndpoints(
-	r *gin.RouterGroup,
+	protected *gin.RouterGroup,
+	admin *gin.RouterGroup,
 	dB db.DB,
 	memberService services.MemberService,
+	eligibilityService services.EligibilityService,
 ) {
-	r.POST("/members", createMember(dB, memberService))
+	protected.GET("/members", listMembers(dB, memberService))
+	protected.GET("/members/lookup", lookupMember(dB, memberService))
+	protected.GET("/members/:id", getMember(dB, memberService))
+	protected.GET("/members/:id/eligibility", checkEligibility(dB, eligibilityService))
+
+	admin.POST("/members", createMember(dB, memberService))
+	admin.PATCH("/members/:id", updateMember(dB, memberService))
+	admin.POST("/members/:id/deactivate", deactivateMember(dB, memberService))
 }
@@ -2,10 +2,14 @@ package members
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/ctxfilter"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
 	"github.com/Doris-Mwito5/ginja-ai/internal/services"
 	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -33,3 +37,164 @@ func createMember(
 			c.JSON(http.StatusCreated, member)
 	}
 }
+
+
+func listMembers(
+	dB db.DB,
+	memberService services.MemberService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		filter, err := ctxfilter.FilterFromContext(c)
+		if err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		memberList, err := memberService.GetMembers(c.Request.Context(), dB, filter)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, memberList)
+	}
+}
+
+func getMember(
+	dB db.DB,
+	memberService services.MemberService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		memberID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid member id"))
+			return
+		}
+
+		member, err := memberService.GetMemberByID(c.Request.Context(), dB, memberID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, member)
+	}
+}
+
+func updateMember(
+	dB db.DB,
+	memberService services.MemberService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		memberID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid member id"))
+			return
+		}
+
+		var req dtos.UpdateMemberRequest
+		if err := c.BindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		member, err := memberService.UpdateMember(c.Request.Context(), dB, memberID, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, member)
+	}
+}
+
+func deactivateMember(
+	dB db.DB,
+	memberService services.MemberService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		memberID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid member id"))
+			return
+		}
+
+		member, err := memberService.DeactivateMember(c.Request.Context(), dB, memberID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, member)
+	}
+}
+
+func lookupMember(
+	dB db.DB,
+	memberService services.MemberService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		req := dtos.MemberLookupRequest{
+			MembershipNumber: c.Query("membership_number"),
+			NationalID:       c.Query("national_id"),
+			CardNumber:       c.Query("card_number"),
+		}
+
+		member, err := memberService.LookupMember(c.Request.Context(), dB, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, member)
+	}
+}
+
+func checkEligibility(
+	dB db.DB,
+	eligibilityService services.EligibilityService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		memberID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid member id"))
+			return
+		}
+
+		req := dtos.EligibilityRequest{
+			ProcedureCode: strings.TrimSpace(c.Query("procedure_code")),
+			ServiceDate:   strings.TrimSpace(c.Query("service_date")),
+			Currency:      strings.TrimSpace(c.Query("currency")),
+		}
+
+		if amount := strings.TrimSpace(c.Query("amount")); amount != "" {
+			req.Amount, err = money.Parse(amount)
+			if err != nil {
+				utils.HandleError(c, apperr.NewBadRequest("invalid amount"))
+				return
+			}
+		}
+
+		if providerID := strings.TrimSpace(c.Query("provider_id")); providerID != "" {
+			req.ProviderID, err = strconv.ParseInt(providerID, 10, 64)
+			if err != nil {
+				utils.HandleError(c, apperr.NewBadRequest("invalid provider_id"))
+				return
+			}
+		}
+
+		eligibility, err := eligibilityService.CheckEligibility(c.Request.Context(), dB, memberID, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, eligibility)
+	}
+}
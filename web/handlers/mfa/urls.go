@@ -0,0 +1,27 @@
+package mfa
+
+import (
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/jwt"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	public *gin.RouterGroup,
+	protected *gin.RouterGroup,
+	dB db.DB,
+	mfaService services.MFAService,
+	jwtMaker jwt.JWTToken,
+	tokenDuration time.Duration,
+) {
+	// Public Endpoints
+	public.POST("/login/mfa", verifyChallenge(dB, mfaService, jwtMaker, tokenDuration))
+
+	// Protected Endpoints
+	protected.POST("/mfa/enroll", enroll(dB, mfaService))
+	protected.POST("/mfa/confirm", confirm(dB, mfaService))
+	protected.POST("/mfa/disable", disable(dB, mfaService))
+}
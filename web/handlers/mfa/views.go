@@ -0,0 +1,122 @@
+package mfa
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/jwt"
+	"github.com/Doris-Mwito5/ginja-ai/internal/middleware"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func enroll(
+	dB db.DB,
+	mfaService services.MFAService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		payload := middleware.GetAuthPayload(c)
+
+		enrollment, err := mfaService.Enroll(c.Request.Context(), dB, payload.Username)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, enrollment)
+	}
+}
+
+func confirm(
+	dB db.DB,
+	mfaService services.MFAService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		var req dtos.MFACodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		payload := middleware.GetAuthPayload(c)
+
+		recoveryCodes, err := mfaService.Confirm(c.Request.Context(), dB, payload.Username, req.Code)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, recoveryCodes)
+	}
+}
+
+func disable(
+	dB db.DB,
+	mfaService services.MFAService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		var req dtos.MFACodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		payload := middleware.GetAuthPayload(c)
+
+		err := mfaService.Disable(c.Request.Context(), dB, payload.Username, req.Code, c.ClientIP())
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"mfa_enabled": false})
+	}
+}
+
+func verifyChallenge(
+	dB db.DB,
+	mfaService services.MFAService,
+	jwtMaker jwt.JWTToken,
+	tokenDuration time.Duration,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		var req dtos.MFALoginRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		challenge, err := jwtMaker.VerifyToken(req.MFAToken)
+		if err != nil || challenge.Scope != jwt.ScopeMFA {
+			utils.HandleError(c, apperr.NewAuthorization("invalid or expired mfa token"))
+			return
+		}
+
+		user, err := mfaService.VerifyChallenge(c.Request.Context(), dB, challenge.Username, req.Code, c.ClientIP())
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		accessToken, err := jwtMaker.CreateScopedToken(user.Username, user.Role.String(), jwt.LoginScope(user.EmailVerified), tokenDuration)
+		if err != nil {
+			utils.HandleError(c, apperr.NewInternal("failed to create token"))
+			return
+		}
+
+		expiresAt := time.Now().Add(tokenDuration).Format(time.RFC3339)
+		c.JSON(http.StatusOK, dtos.LoginResponse{
+			AccessToken: accessToken,
+			User:        user,
+			ExpiresAt:   expiresAt,
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package payments
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	paymentService services.PaymentService,
+) {
+	r.GET("/payment-batches", listPaymentBatches(dB, paymentService))
+	r.GET("/payment-batches/:id", getPaymentBatch(dB, paymentService))
+	r.GET("/payment-batches/:id/remittance", exportRemittance(dB, paymentService))
+	r.POST("/payment-batches", createPaymentBatches(dB, paymentService))
+	r.POST("/payment-batches/:id/approve", approvePaymentBatch(dB, paymentService))
+	r.POST("/payment-batches/:id/pay", payPaymentBatch(dB, paymentService))
+	r.DELETE("/payment-batches/:id", deletePaymentBatch(dB, paymentService))
+	r.GET("/providers/:id/statement", getProviderStatement(dB, paymentService))
+}
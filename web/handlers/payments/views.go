@@ -0,0 +1,234 @@
+package payments
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/ctxfilter"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/middleware"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func createPaymentBatches(
+	dB db.DB,
+	paymentService services.PaymentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var req dtos.PaymentBatchRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&req); err != nil {
+				utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+				return
+			}
+		}
+
+		batches, err := paymentService.CreatePaymentBatches(c.Request.Context(), dB, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"batches": batches})
+	}
+}
+
+func listPaymentBatches(
+	dB db.DB,
+	paymentService services.PaymentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		filter, err := ctxfilter.FilterFromContext(c)
+		if err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		batchList, err := paymentService.GetPaymentBatches(c.Request.Context(), dB, filter)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, batchList)
+	}
+}
+
+func getPaymentBatch(
+	dB db.DB,
+	paymentService services.PaymentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		batchID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid payment batch id"))
+			return
+		}
+
+		batch, err := paymentService.GetPaymentBatchByID(c.Request.Context(), dB, batchID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, batch)
+	}
+}
+
+// exportRemittance returns the batch's remittance as CSV, or as JSON with format=json.
+func exportRemittance(
+	dB db.DB,
+	paymentService services.PaymentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		batchID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid payment batch id"))
+			return
+		}
+
+		format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "csv")))
+		if format != "csv" && format != "json" {
+			utils.HandleError(c, apperr.NewBadRequest("format must be csv or json"))
+			return
+		}
+
+		batch, err := paymentService.GetPaymentBatchByID(c.Request.Context(), dB, batchID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		if format == "json" {
+			c.JSON(http.StatusOK, batch)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := services.WriteRemittanceCSV(&buf, batch); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.Internal))
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=remittance-%d.csv", batch.ID))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	}
+}
+
+func approvePaymentBatch(
+	dB db.DB,
+	paymentService services.PaymentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		batchID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid payment batch id"))
+			return
+		}
+
+		payload := middleware.GetAuthPayload(c)
+		batch, err := paymentService.ApprovePaymentBatch(c.Request.Context(), dB, batchID, payload.Username)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, batch)
+	}
+}
+
+func payPaymentBatch(
+	dB db.DB,
+	paymentService services.PaymentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		batchID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid payment batch id"))
+			return
+		}
+
+		var form dtos.PaymentBatchPaymentForm
+		if err := c.BindJSON(&form); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		batch, err := paymentService.PayPaymentBatch(c.Request.Context(), dB, batchID, &form)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, batch)
+	}
+}
+
+func deletePaymentBatch(
+	dB db.DB,
+	paymentService services.PaymentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		batchID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid payment batch id"))
+			return
+		}
+
+		if err := paymentService.DeletePaymentBatch(c.Request.Context(), dB, batchID); err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// getProviderStatement defaults to the current month up to today.
+func getProviderStatement(
+	dB db.DB,
+	paymentService services.PaymentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		providerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid provider id"))
+			return
+		}
+
+		now := time.Now().UTC()
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+		if value := strings.TrimSpace(c.Query("from")); value != "" {
+			from, err = utils.ParseDate(value)
+			if err != nil {
+				utils.HandleError(c, apperr.NewBadRequest(fmt.Sprintf("from: %v", err)))
+				return
+			}
+		}
+		if value := strings.TrimSpace(c.Query("to")); value != "" {
+			to, err = utils.ParseDate(value)
+			if err != nil {
+				utils.HandleError(c, apperr.NewBadRequest(fmt.Sprintf("to: %v", err)))
+				return
+			}
+		}
+
+		statement, err := paymentService.GetProviderStatement(c.Request.Context(), dB, providerID, from, to)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, statement)
+	}
+}
@@ -7,9 +7,13 @@ import (
 )
 
 func AddEndpoints(
-	r *gin.RouterGroup,
+	protected *gin.RouterGroup,
+	admin *gin.RouterGroup,
 	dB db.DB,
 	procedureService services.ProcedureService,
 ) {
-	r.POST("/procedures", createProcedure(dB, procedureService))
+	protected.POST("/procedures", createProcedure(dB, procedureService))
+	protected.GET("/procedures/:code/history", getProcedureHistory(dB, procedureService))
+
+	admin.POST("/procedures/:code/prices", addProcedurePrice(dB, procedureService))
 }
\ No newline at end of file
@@ -31,4 +31,40 @@ func createProcedure(
 
 		c.JSON(http.StatusCreated, procedure)
 	}
-}
\ No newline at end of file
+}
+
+func addProcedurePrice(
+	dB db.DB,
+	procedureService services.ProcedureService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var req dtos.ProcedurePrice
+		if err := c.BindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		version, err := procedureService.AddProcedurePrice(c.Request.Context(), dB, c.Param("code"), &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, version)
+	}
+}
+
+func getProcedureHistory(
+	dB db.DB,
+	procedureService services.ProcedureService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		history, err := procedureService.GetProcedureHistory(c.Request.Context(), dB, c.Param("code"))
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, history)
+	}
+}
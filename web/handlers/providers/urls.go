@@ -7,9 +7,15 @@ import (
 )
 
 func AddEndpoints(
-	r *gin.RouterGroup,
+	protected *gin.RouterGroup,
+	admin *gin.RouterGroup,
 	dB db.DB,
 	providerService services.ProviderService,
 ) {
-	r.POST("/providers", createProvider(dB, providerService))
+	protected.GET("/providers", listProviders(dB, providerService))
+	protected.GET("/providers/:id", getProvider(dB, providerService))
+
+	admin.POST("/providers", createProvider(dB, providerService))
+	admin.PATCH("/providers/:id", updateProvider(dB, providerService))
+	admin.DELETE("/providers/:id", deleteProvider(dB, providerService))
 }
@@ -2,8 +2,10 @@ package providers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/ctxfilter"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
 	"github.com/Doris-Mwito5/ginja-ai/internal/services"
@@ -32,3 +34,93 @@ func createProvider(
 		c.JSON(http.StatusCreated, provider)
 	}
 }
+
+func listProviders(
+	dB db.DB,
+	providerService services.ProviderService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		filter, err := ctxfilter.FilterFromContext(c)
+		if err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		providerList, err := providerService.GetProviders(c.Request.Context(), dB, filter)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, providerList)
+	}
+}
+
+func getProvider(
+	dB db.DB,
+	providerService services.ProviderService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		providerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid provider id"))
+			return
+		}
+
+		provider, err := providerService.GetProviderByID(c.Request.Context(), dB, providerID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, provider)
+	}
+}
+
+func updateProvider(
+	dB db.DB,
+	providerService services.ProviderService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		providerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid provider id"))
+			return
+		}
+
+		var req dtos.UpdateProviderRequest
+		if err := c.BindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		provider, err := providerService.UpdateProvider(c.Request.Context(), dB, providerID, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, provider)
+	}
+}
+
+func deleteProvider(
+	dB db.DB,
+	providerService services.ProviderService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		providerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid provider id"))
+			return
+		}
+
+		err = providerService.DeleteProvider(c.Request.Context(), dB, providerID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
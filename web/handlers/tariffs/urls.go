@@ -0,0 +1,22 @@
+package tariffs
+
+import (
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AddEndpoints(
+	protected *gin.RouterGroup,
+	admin *gin.RouterGroup,
+	dB db.DB,
+	tariffService services.TariffService,
+) {
+	protected.GET("/tariffs", listTariffs(dB, tariffService))
+	protected.GET("/tariffs/:id", getTariff(dB, tariffService))
+
+	admin.POST("/tariffs", createTariff(dB, tariffService))
+	admin.POST("/tariffs/upload", uploadTariffs(dB, tariffService))
+	admin.PATCH("/tariffs/:id", updateTariff(dB, tariffService))
+	admin.DELETE("/tariffs/:id", deleteTariff(dB, tariffService))
+}
@@ -0,0 +1,157 @@
+package tariffs
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/ctxfilter"
+	"github.com/Doris-Mwito5/ginja-ai/internal/db"
+	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
+	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxUploadSize is the largest tariff CSV accepted by the upload endpoint.
+const MaxUploadSize = 10 << 20
+
+func createTariff(
+	dB db.DB,
+	tariffService services.TariffService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var req dtos.ProviderTariff
+		err := c.BindJSON(&req)
+		if err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		tariff, err := tariffService.CreateTariff(c.Request.Context(), dB, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, tariff)
+	}
+}
+
+func listTariffs(
+	dB db.DB,
+	tariffService services.TariffService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		filter, err := ctxfilter.FilterFromContext(c)
+		if err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		tariffList, err := tariffService.GetTariffs(c.Request.Context(), dB, filter)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, tariffList)
+	}
+}
+
+func getTariff(
+	dB db.DB,
+	tariffService services.TariffService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		tariffID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid tariff id"))
+			return
+		}
+
+		tariff, err := tariffService.GetTariffByID(c.Request.Context(), dB, tariffID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, tariff)
+	}
+}
+
+func updateTariff(
+	dB db.DB,
+	tariffService services.TariffService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		tariffID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid tariff id"))
+			return
+		}
+
+		var req dtos.UpdateProviderTariffRequest
+		if err := c.BindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		tariff, err := tariffService.UpdateTariff(c.Request.Context(), dB, tariffID, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, tariff)
+	}
+}
+
+func deleteTariff(
+	dB db.DB,
+	tariffService services.TariffService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		tariffID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid tariff id"))
+			return
+		}
+
+		err = tariffService.DeleteTariff(c.Request.Context(), dB, tariffID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func uploadTariffs(
+	dB db.DB,
+	tariffService services.TariffService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		options, err := ctxfilter.ImportOptionsFromContext(c)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		file, err := utils.OpenFormFile(c, "file", MaxUploadSize)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		defer file.Close()
+
+		result, err := tariffService.ImportTariffs(c.Request.Context(), dB, file, options)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(utils.ImportStatusCode(result), result)
+	}
+}
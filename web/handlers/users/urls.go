@@ -8,11 +8,17 @@ import (
 	"time"
 )
 
-const DefaultTokenDuration = 24 * time.Hour
+const (
+	DefaultTokenDuration = 24 * time.Hour
+	// MFATokenDuration is how long a user has to enter the second factor after the password step.
+	MFATokenDuration = 5 * time.Minute
+)
 
 func AddEndpoints(
     public *gin.RouterGroup,    
+    account *gin.RouterGroup,
     protected *gin.RouterGroup, 
+    admin *gin.RouterGroup,
     dB db.DB,
     userService services.UserService,
     jwtMaker jwt.JWTToken,
@@ -25,9 +31,25 @@ func AddEndpoints(
     // Public Endpoints
     public.POST("/register", register(dB, userService))
     public.POST("/login", login(dB, userService, jwtMaker, tokenDuration))
+    public.POST("/password/forgot", forgotPassword(dB, userService))
+    public.POST("/password/reset", resetPassword(dB, userService))
+    public.POST("/verify-email", verifyEmail(dB, userService))
+
+    // Account Endpoints (also open to unverified users)
+    account.POST("/password/change", changePassword(dB, userService, jwtMaker, tokenDuration))
+    account.POST("/verify-email/resend", resendEmailVerification(dB, userService))
+    account.GET("/me", getMe(dB, userService))
 
     // Protected Endpoints
     
     protected.GET("/:id", getUserByID(dB, userService))
     protected.GET("/username/:username", getUserByUsername(dB, userService))
+
+    // Admin Endpoints
+    admin.GET("/users", listUsers(dB, userService))
+    admin.PATCH("/users/:id", updateUser(dB, userService))
+    admin.POST("/users/:id/activate", setUserActive(dB, userService, true))
+    admin.POST("/users/:id/deactivate", setUserActive(dB, userService, false))
+    admin.POST("/users/:id/unlock", unlockUser(dB, userService))
+    admin.POST("/users/:id/force-password-reset", forcePasswordReset(dB, userService))
 }
\ No newline at end of file
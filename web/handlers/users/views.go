@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/apperr"
+	"github.com/Doris-Mwito5/ginja-ai/internal/ctxfilter"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/dtos"
 	"github.com/Doris-Mwito5/ginja-ai/internal/jwt"
+	"github.com/Doris-Mwito5/ginja-ai/internal/middleware"
 	"github.com/Doris-Mwito5/ginja-ai/internal/services"
 	"github.com/Doris-Mwito5/ginja-ai/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -62,7 +64,7 @@ func login(
 		ctx, cancel := context.WithTimeout(c.Request.Context(), handlerTimeout)
 		defer cancel()
 
-		user, err := userService.ValidateCredentials(ctx, dB, strings.TrimSpace(req.Username), req.Password)
+		user, err := userService.ValidateCredentials(ctx, dB, strings.TrimSpace(req.Username), req.Password, c.ClientIP())
 		if err != nil {
 			if ctx.Err() == context.DeadlineExceeded {
 				utils.HandleError(c, apperr.NewInternal("request timed out; check database is running and reachable"))
@@ -72,7 +74,22 @@ func login(
 			return
 		}
 
-		accessToken, err := jwtMaker.CreateToken(user.Username, tokenDuration)
+		if user.MFAEnabled {
+			mfaToken, err := jwtMaker.CreateScopedToken(user.Username, user.Role.String(), jwt.ScopeMFA, MFATokenDuration)
+			if err != nil {
+				utils.HandleError(c, apperr.NewInternal("failed to create token"))
+				return
+			}
+
+			c.JSON(http.StatusOK, dtos.MFAChallengeResponse{
+				MFARequired: true,
+				MFAToken:    mfaToken,
+				ExpiresAt:   time.Now().Add(MFATokenDuration).Format(time.RFC3339),
+			})
+			return
+		}
+
+		accessToken, err := jwtMaker.CreateScopedToken(user.Username, user.Role.String(), jwt.LoginScope(user.EmailVerified), tokenDuration)
 		if err != nil {
 			utils.HandleError(c, apperr.NewInternal("failed to create token"))
 			return
@@ -129,3 +146,258 @@ func getUserByUsername(
 		c.JSON(http.StatusOK, user)
 	}
 }
+
+func unlockUser(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid user id"))
+			return
+		}
+
+		user, err := userService.UnlockUser(c.Request.Context(), dB, id)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func changePassword(
+	dB db.DB,
+	userService services.UserService,
+	jwtMaker jwt.JWTToken,
+	tokenDuration time.Duration,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var req dtos.ChangePasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		payload := middleware.GetAuthPayload(c)
+
+		user, err := userService.ChangePassword(c.Request.Context(), dB, payload.Username, &req, c.ClientIP())
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		// the change revoked every earlier token, including the one this request was made with
+		accessToken, err := jwtMaker.CreateScopedToken(user.Username, user.Role.String(), jwt.LoginScope(user.EmailVerified), tokenDuration)
+		if err != nil {
+			utils.HandleError(c, apperr.NewInternal("failed to create token"))
+			return
+		}
+
+		expiresAt := time.Now().Add(tokenDuration).Format(time.RFC3339)
+		c.JSON(http.StatusOK, dtos.LoginResponse{
+			AccessToken: accessToken,
+			User:        user,
+			ExpiresAt:   expiresAt,
+		})
+	}
+}
+
+func forgotPassword(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var req dtos.ForgotPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), handlerTimeout)
+		defer cancel()
+
+		err := userService.ForgotPassword(ctx, dB, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"message": "if the email belongs to an account, a reset link has been sent"})
+	}
+}
+
+func resetPassword(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var req dtos.ResetPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		err := userService.ResetPassword(c.Request.Context(), dB, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "password reset"})
+	}
+}
+
+func verifyEmail(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var req dtos.VerifyEmailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		user, err := userService.VerifyEmail(c.Request.Context(), dB, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func resendEmailVerification(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		payload := middleware.GetAuthPayload(c)
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), handlerTimeout)
+		defer cancel()
+
+		err := userService.ResendEmailVerification(ctx, dB, payload.Username)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"message": "verification email sent"})
+	}
+}
+
+func getMe(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		payload := middleware.GetAuthPayload(c)
+
+		user, err := userService.GetUserByUsername(c.Request.Context(), dB, payload.Username)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func listUsers(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		filter, err := ctxfilter.FilterFromContext(c)
+		if err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		userList, err := userService.GetUsers(c.Request.Context(), dB, filter)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, userList)
+	}
+}
+
+func updateUser(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid user id"))
+			return
+		}
+
+		var req dtos.UpdateUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleError(c, apperr.NewErrorWithType(err, apperr.BadRequest))
+			return
+		}
+
+		user, err := userService.UpdateUser(c.Request.Context(), dB, id, &req)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func setUserActive(
+	dB db.DB,
+	userService services.UserService,
+	active bool,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid user id"))
+			return
+		}
+
+		user, err := userService.SetUserActive(c.Request.Context(), dB, id, active)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func forcePasswordReset(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.HandleError(c, apperr.NewBadRequest("invalid user id"))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), handlerTimeout)
+		defer cancel()
+
+		err = userService.ForcePasswordReset(ctx, dB, id)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"message": "password reset email sent"})
+	}
+}
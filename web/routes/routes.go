@@ -3,20 +3,37 @@ package routes
 import (
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Doris-Mwito5/ginja-ai/internal/configs"
+	"github.com/Doris-Mwito5/ginja-ai/internal/custom_types"
 	"github.com/Doris-Mwito5/ginja-ai/internal/db"
 	"github.com/Doris-Mwito5/ginja-ai/internal/domain"
+	"github.com/Doris-Mwito5/ginja-ai/internal/health"
 	"github.com/Doris-Mwito5/ginja-ai/internal/jwt"
+	"github.com/Doris-Mwito5/ginja-ai/internal/mailer"
 	middleware "github.com/Doris-Mwito5/ginja-ai/internal/middleware"
 	"github.com/Doris-Mwito5/ginja-ai/internal/services"
+	"github.com/Doris-Mwito5/ginja-ai/internal/storage"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/attachments"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/auditlog"
 	"github.com/Doris-Mwito5/ginja-ai/web/handlers/claims"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/costruns"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/edi"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/exchangerates"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/fhir"
+	healthhandlers "github.com/Doris-Mwito5/ginja-ai/web/handlers/health"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/imports"
 	"github.com/Doris-Mwito5/ginja-ai/web/handlers/members"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/mfa"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/payments"
 	"github.com/Doris-Mwito5/ginja-ai/web/handlers/procedures"
 	"github.com/Doris-Mwito5/ginja-ai/web/handlers/providers"
+	"github.com/Doris-Mwito5/ginja-ai/web/handlers/tariffs"
 	"github.com/Doris-Mwito5/ginja-ai/web/handlers/users"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type AppRouter struct {
@@ -26,11 +43,27 @@ type AppRouter struct {
 func BuildRouter(
 	dB db.DB,
 	domainStore *domain.Store,
+	healthState *health.State,
 ) *AppRouter {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(
+		otelgin.Middleware("ginja-ai"),
+		middleware.RequestIDMiddleware(),
+		middleware.RequestLogger(),
+		middleware.MetricsMiddleware(),
+		gin.Recovery(),
+	)
+	registerValidators()
+
+	// client IPs drive the login throttle and are recorded in the audit log, so forwarding
+	// headers are only believed from the configured proxies
+	if err := router.SetTrustedProxies(trustedProxies(configs.Config.TrustedProxies)); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	baseAPIGroup := router.Group("/v1")
 	baseAPIGroup.Use(middleware.CORSMiddleware())
+	baseAPIGroup.Use(middleware.AuditMiddleware())
 
 	jwtMaker, err := jwt.NewJWTMaker(configs.Config.JWTSecret)
 	if err != nil {
@@ -38,26 +71,64 @@ func BuildRouter(
 	}
 
 	// --- Service Instantiation ---
-	claimService := services.NewClaimService(domainStore)
-	userService := services.NewUserService(domainStore)
+	claimService := services.NewClaimService(domainStore, configs.Config.ClaimFilingDays)
+	userService := services.NewUserService(domainStore, mailer.NewMailer())
 	memberService := services.NewMemberService(domainStore)
 	procedureService := services.NewProcedureService(domainStore)
 	providerService := services.NewProviderService(domainStore)
+	mfaService := services.NewMFAService(domainStore)
+	tariffService := services.NewTariffService(domainStore)
+	importService := services.NewImportService(domainStore)
+	costRunService := services.NewCostRunService(domainStore)
+	eligibilityService := services.NewEligibilityService(domainStore)
+	fhirService := services.NewFHIRService(domainStore, claimService, eligibilityService)
+	x12Service := services.NewX12Service(domainStore, claimService)
+	attachmentService := services.NewAttachmentService(domainStore, storage.NewStorage(), configs.Config.AttachmentMaxBytes)
+	paymentService := services.NewPaymentService(domainStore)
+	exchangeRateService := services.NewExchangeRateService(domainStore)
+	auditService := services.NewAuditService(domainStore)
+	healthService := services.NewHealthService(domainStore, healthState)
+
+	// Probe group (no auth, outside /v1 so orchestrators can call it without CORS or audit)
+	probeRoutes := router.Group("")
 
 	// Public group (no auth)
 	publicRoutes := baseAPIGroup.Group("")
 
+	// Account group (JWT required, unverified users allowed)
+	accountRoutes := baseAPIGroup.Group("")
+	accountRoutes.Use(middleware.AuthMiddleware(jwtMaker, dB, domainStore.UserDomain, jwt.ScopeAccess, jwt.ScopeLimited))
+
 	// Protected group (JWT required)
 	protectedRoutes := baseAPIGroup.Group("")
-	protectedRoutes.Use(middleware.AuthMiddleware(jwtMaker))
+	protectedRoutes.Use(middleware.AuthMiddleware(jwtMaker, dB, domainStore.UserDomain))
+
+	// Admin group (JWT with admin role required)
+	adminRoutes := protectedRoutes.Group("")
+	adminRoutes.Use(middleware.RequireRole(custom_types.UserRoleAdmin))
 
-	users.AddEndpoints(publicRoutes, protectedRoutes, dB, userService, jwtMaker, 24*time.Hour)
+	// Auditor group (JWT with admin or auditor role required)
+	auditorRoutes := protectedRoutes.Group("")
+	auditorRoutes.Use(middleware.RequireRole(custom_types.UserRoleAdmin, custom_types.UserRoleAuditor))
+
+	users.AddEndpoints(publicRoutes, accountRoutes, protectedRoutes, adminRoutes, dB, userService, jwtMaker, 24*time.Hour)
+	mfa.AddEndpoints(publicRoutes, protectedRoutes, dB, mfaService, jwtMaker, 24*time.Hour)
 
 	claims.AddEndpoints(protectedRoutes, dB, claimService)
+	fhir.AddEndpoints(protectedRoutes, dB, fhirService)
+	edi.AddEndpoints(protectedRoutes, dB, x12Service)
+	attachments.AddEndpoints(protectedRoutes, adminRoutes, dB, attachmentService, configs.Config.AttachmentMaxBytes)
 
-	members.AddEndpoints(protectedRoutes, dB, memberService)
-	procedures.AddEndpoints(protectedRoutes, dB, procedureService)
-	providers.AddEndpoints(protectedRoutes, dB, providerService)
+	members.AddEndpoints(protectedRoutes, adminRoutes, dB, memberService, eligibilityService)
+	procedures.AddEndpoints(protectedRoutes, adminRoutes, dB, procedureService)
+	providers.AddEndpoints(protectedRoutes, adminRoutes, dB, providerService)
+	tariffs.AddEndpoints(protectedRoutes, adminRoutes, dB, tariffService)
+	exchangerates.AddEndpoints(protectedRoutes, adminRoutes, dB, exchangeRateService)
+	imports.AddEndpoints(adminRoutes, dB, importService)
+	costruns.AddEndpoints(adminRoutes, dB, costRunService)
+	payments.AddEndpoints(adminRoutes, dB, paymentService)
+	auditlog.AddEndpoints(auditorRoutes, dB, auditService)
+	healthhandlers.AddEndpoints(probeRoutes, adminRoutes, dB, healthService)
 
 	router.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error_message": "Endpoint not found"})
@@ -65,3 +136,15 @@ func BuildRouter(
 
 	return &AppRouter{router}
 }
+
+// trustedProxies splits the comma separated TRUSTED_PROXIES setting. An empty setting gives nil,
+// which trusts no proxy.
+func trustedProxies(setting string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(setting, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"reflect"
+
+	"github.com/Doris-Mwito5/ginja-ai/internal/money"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// registerValidators lets binding tags such as required and gt=0 apply to money amounts, which
+// are validated as their number of minor units.
+func registerValidators() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if amount, ok := field.Interface().(money.Money); ok {
+			return amount.Amount
+		}
+		return nil
+	}, money.Money{})
+}